@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	domain "jobgen-backend/Domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AIUsageReporter is implemented by the shared LLM client.
+type AIUsageReporter interface {
+	Usage() domain.AIUsageStats
+}
+
+// AIUserUsageReporter reports the AI usage recorded per user.
+type AIUserUsageReporter interface {
+	Report(ctx context.Context, filter domain.AIUsageFilter) (*domain.AIUsageReport, error)
+}
+
+type AIController struct {
+	usage     AIUsageReporter
+	userUsage AIUserUsageReporter
+}
+
+func NewAIController(usage AIUsageReporter, userUsage AIUserUsageReporter) *AIController {
+	return &AIController{usage: usage, userUsage: userUsage}
+}
+
+// Usage returns AI request, failure and token counts
+// @Summary AI usage
+// @Description Requests, failures, retries and tokens per AI operation since the API process started. Admin only.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} domain.AIUsageStats
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/ai/usage [get]
+func (ctrl *AIController) Usage(c *gin.Context) {
+	c.JSON(http.StatusOK, ctrl.usage.Usage())
+}
+
+// UserUsage reports AI usage and estimated cost per user
+// @Summary AI usage per user
+// @Description Requests, tokens and estimated cost per user and operation between two days (UTC, inclusive). Defaults to the current month. Admin only.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Param user_id query string false "Only this user"
+// @Success 200 {object} StandardResponse{data=domain.AIUsageReport}
+// @Failure 400 {object} StandardResponse
+// @Failure 401 {object} StandardResponse
+// @Failure 403 {object} StandardResponse
+// @Router /admin/ai/usage/users [get]
+func (ctrl *AIController) UserUsage(c *gin.Context) {
+	report, err := ctrl.userUsage.Report(c.Request.Context(), domain.AIUsageFilter{
+		UserID:  c.Query("user_id"),
+		FromDay: c.Query("from"),
+		ToDay:   c.Query("to"),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			ErrorResponse(c, http.StatusBadRequest, "INVALID_INPUT", err.Error(), nil)
+			return
+		}
+		InternalErrorResponse(c, "Failed to build AI usage report")
+		return
+	}
+	SuccessResponse(c, http.StatusOK, "AI usage report retrieved successfully", report)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -18,7 +19,7 @@ func NewChatController(chatUsecase domain.IChatUsecase) *ChatController {
 }
 
 // @Summary Send a message to the AI chatbot
-// @Description Send a message to the AI career assistant and receive a response
+// @Description Send a message to the AI career assistant and receive a response. The assistant looks up real job listings and the user's CV; jobs it mentions are cited in the text as [job:<id>] and returned in "jobs". The response carries the saved message ID and the session; fetch earlier messages from the session history endpoint.
 // @Tags AI Chat
 // @Accept json
 // @Produce json
@@ -26,6 +27,8 @@ func NewChatController(chatUsecase domain.IChatUsecase) *ChatController {
 // @Param request body domain.ChatRequest true "Chat message"
 // @Success 200 {object} StandardResponse "Message sent successfully"
 // @Failure 400 {object} StandardResponse "Bad request"
+// @Failure 404 {object} StandardResponse "Session not found"
+// @Failure 429 {object} StandardResponse "AI usage quota exceeded"
 // @Failure 500 {object} StandardResponse "Internal server error"
 // @Router /chat/message [post]
 func (c *ChatController) SendMessage(ctx *gin.Context) {
@@ -45,24 +48,88 @@ func (c *ChatController) SendMessage(ctx *gin.Context) {
 	req.UserID = userID
 	
 	response, err := c.chatUsecase.SendMessage(ctx, &req)
+	if errors.Is(err, domain.ErrAIQuotaExceeded) {
+		QuotaExceededResponse(ctx, err)
+		return
+	}
 	if err != nil {
-		InternalErrorResponse(ctx, "Failed to process message: "+err.Error())
+		respondChatSessionError(ctx, err, "Failed to process message: "+err.Error())
 		return
 	}
 	
 	SuccessResponse(ctx, http.StatusOK, "Message processed successfully", response)
 }
 
+// @Summary Send a message and stream the reply
+// @Description Server-sent event stream of the AI reply. "delta" events carry each piece of text as it is generated ({"text": "..."}), with job citations already resolved, so the pieces add up to the saved reply; a final "done" event carries the saved message ID, suggestions and session. If the model fails part way through, "done" has partial=true; errors after the stream has started are sent as an "error" event. If the client disconnects, the text sent so far is saved as a partial reply.
+// @Tags AI Chat
+// @Accept json
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param request body domain.ChatRequest true "Chat message"
+// @Success 200 {object} domain.ChatResponse "Event stream"
+// @Failure 400 {object} StandardResponse "Bad request"
+// @Failure 404 {object} StandardResponse "Session not found"
+// @Failure 429 {object} StandardResponse "AI usage quota exceeded"
+// @Failure 500 {object} StandardResponse "Internal server error"
+// @Router /chat/message/stream [post]
+func (c *ChatController) SendMessageStream(ctx *gin.Context) {
+	var req domain.ChatRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(ctx, err)
+		return
+	}
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		UnauthorizedResponse(ctx, "User not authenticated")
+		return
+	}
+	req.UserID = userID
+
+	// The stream starts with the first piece of text, so errors before it get a normal response
+	started := false
+	onDelta := func(delta string) error {
+		if !started {
+			ctx.Header("Cache-Control", "no-cache")
+			ctx.Header("Connection", "keep-alive")
+			ctx.Header("X-Accel-Buffering", "no") // keep reverse proxies from buffering the stream
+			started = true
+		}
+		ctx.SSEvent("delta", gin.H{"text": delta})
+		ctx.Writer.Flush()
+		return ctx.Request.Context().Err()
+	}
+
+	response, err := c.chatUsecase.SendMessageStream(ctx.Request.Context(), &req, onDelta)
+	switch {
+	case err == nil:
+		ctx.SSEvent("done", response)
+		ctx.Writer.Flush()
+	case ctx.Request.Context().Err() != nil:
+		// The client disconnected; there is no one to tell
+	case started:
+		ctx.SSEvent("error", ErrorInfo{Code: "INTERNAL_ERROR", Message: "Failed to process message: " + err.Error()})
+		ctx.Writer.Flush()
+	case errors.Is(err, domain.ErrAIQuotaExceeded):
+		QuotaExceededResponse(ctx, err)
+	default:
+		respondChatSessionError(ctx, err, "Failed to process message: "+err.Error())
+	}
+}
+
 // @Summary Get chat session history
-// @Description Retrieve the message history for a specific chat session
+// @Description Retrieve a page of a chat session's messages in chronological order. Offset counts back from the newest message, so offset 0 is the latest page; has_more reports whether older messages remain.
 // @Tags AI Chat
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param session_id path string true "Session ID"
+// @Param limit query int false "Number of messages to return (default 50, max 100)"
+// @Param offset query int false "Number of newest messages to skip (default 0)"
 // @Success 200 {object} StandardResponse "History retrieved successfully"
 // @Failure 400 {object} StandardResponse "Bad request"
 // @Failure 404 {object} StandardResponse "Session not found"
+// @Failure 500 {object} StandardResponse "Internal server error"
 // @Router /chat/session/{session_id} [get]
 func (c *ChatController) GetSessionHistory(ctx *gin.Context) {
 	sessionID := ctx.Param("session_id")
@@ -73,9 +140,12 @@ func (c *ChatController) GetSessionHistory(ctx *gin.Context) {
 		return
 	}
 	
-	history, err := c.chatUsecase.GetSessionHistory(ctx, sessionID, userID)
+	limit := parseIntQueryParam(ctx, "limit", 50)
+	offset := parseIntQueryParam(ctx, "offset", 0)
+	
+	history, err := c.chatUsecase.GetSessionHistory(ctx, sessionID, userID, limit, offset)
 	if err != nil {
-		NotFoundResponse(ctx, "Session not found")
+		respondChatSessionError(ctx, err, "Failed to get session history")
 		return
 	}
 	
@@ -83,11 +153,12 @@ func (c *ChatController) GetSessionHistory(ctx *gin.Context) {
 }
 
 // @Summary Get user's chat sessions
-// @Description Retrieve a list of the user's chat sessions
+// @Description Retrieve a list of the user's chat sessions, pinned sessions first. Archived sessions are only listed with archived=true.
 // @Tags AI Chat
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param archived query bool false "List archived sessions instead (default false)"
 // @Param limit query int false "Number of results to return (default 10)"
 // @Param offset query int false "Number of results to skip (default 0)"
 // @Success 200 {object} StandardResponse "Sessions retrieved successfully"
@@ -104,7 +175,9 @@ func (c *ChatController) GetUserSessions(ctx *gin.Context) {
 	limit := parseIntQueryParam(ctx, "limit", 10)
 	offset := parseIntQueryParam(ctx, "offset", 0)
 	
-	sessions, err := c.chatUsecase.GetUserSessions(ctx, userID, limit, offset)
+	archived := ctx.Query("archived") == "true"
+	
+	sessions, err := c.chatUsecase.GetUserSessions(ctx, userID, archived, limit, offset)
 	if err != nil {
 		InternalErrorResponse(ctx, "Failed to retrieve sessions: "+err.Error())
 		return
@@ -123,6 +196,7 @@ func (c *ChatController) GetUserSessions(ctx *gin.Context) {
 // @Success 200 {object} StandardResponse "Session deleted successfully"
 // @Failure 400 {object} StandardResponse "Bad request"
 // @Failure 404 {object} StandardResponse "Session not found"
+// @Failure 500 {object} StandardResponse "Internal server error"
 // @Router /chat/session/{session_id} [delete]
 func (c *ChatController) DeleteSession(ctx *gin.Context) {
 	sessionID := ctx.Param("session_id")
@@ -135,13 +209,116 @@ func (c *ChatController) DeleteSession(ctx *gin.Context) {
 	
 	err := c.chatUsecase.DeleteSession(ctx, sessionID, userID)
 	if err != nil {
-		NotFoundResponse(ctx, "Session not found")
+		respondChatSessionError(ctx, err, "Failed to delete session")
 		return
 	}
 	
 	SuccessResponse(ctx, http.StatusOK, "Session deleted successfully", nil)
 }
 
+// @Summary Rename, pin or archive a chat session
+// @Description Update a session's title, pinned or archived state. Fields that are left out are unchanged.
+// @Tags AI Chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param session_id path string true "Session ID"
+// @Param request body domain.ChatSessionUpdate true "Fields to change"
+// @Success 200 {object} StandardResponse "Session updated successfully"
+// @Failure 400 {object} StandardResponse "Bad request"
+// @Failure 404 {object} StandardResponse "Session not found"
+// @Router /chat/session/{session_id} [patch]
+func (c *ChatController) UpdateSession(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		UnauthorizedResponse(ctx, "User not authenticated")
+		return
+	}
+
+	var update domain.ChatSessionUpdate
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		ValidationErrorResponse(ctx, err)
+		return
+	}
+
+	session, err := c.chatUsecase.UpdateSessionSettings(ctx, ctx.Param("session_id"), userID, update)
+	if err != nil {
+		respondChatSessionError(ctx, err, "Failed to update session")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Session updated successfully", session)
+}
+
+// @Summary Search chat messages
+// @Description Full-text search across the messages of all the user's chat sessions, best matches first
+// @Tags AI Chat
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text"
+// @Param limit query int false "Number of results to return (default 20, max 50)"
+// @Success 200 {object} StandardResponse "Search results"
+// @Failure 400 {object} StandardResponse "Missing search text"
+// @Router /chat/search [get]
+func (c *ChatController) SearchMessages(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		UnauthorizedResponse(ctx, "User not authenticated")
+		return
+	}
+
+	hits, err := c.chatUsecase.SearchMessages(ctx, userID, ctx.Query("q"), parseIntQueryParam(ctx, "limit", 20))
+	if err != nil {
+		respondChatSessionError(ctx, err, "Failed to search messages")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Search results", hits)
+}
+
+// @Summary Export a chat session
+// @Description Download a whole session as a Markdown, plain text or PDF transcript, or as JSON with the session and its messages
+// @Tags AI Chat
+// @Produce json
+// @Produce plain
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param session_id path string true "Session ID"
+// @Param format query string false "Export format" Enums(md, json, txt, pdf) default(md)
+// @Success 200 {file} file "Exported session"
+// @Failure 400 {object} StandardResponse "Unsupported format"
+// @Failure 404 {object} StandardResponse "Session not found"
+// @Failure 500 {object} StandardResponse "Internal server error"
+// @Router /chat/session/{session_id}/export [get]
+func (c *ChatController) ExportSession(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		UnauthorizedResponse(ctx, "User not authenticated")
+		return
+	}
+
+	format := domain.ExportFormat(ctx.DefaultQuery("format", string(domain.ExportMarkdown)))
+	doc, err := c.chatUsecase.ExportSession(ctx, ctx.Param("session_id"), userID, format)
+	if err != nil {
+		respondChatSessionError(ctx, err, "Failed to export session")
+		return
+	}
+
+	sendExportedDocument(ctx, doc)
+}
+
+// respondChatSessionError maps session management errors to responses
+func respondChatSessionError(ctx *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		ErrorResponse(ctx, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+	case errors.Is(err, domain.ErrNotFound):
+		NotFoundResponse(ctx, "Session not found")
+	default:
+		InternalErrorResponse(ctx, fallback)
+	}
+}
+
 // Helper function to parse integer query parameters
 func parseIntQueryParam(ctx *gin.Context, param string, defaultValue int) int {
 	value := ctx.Query(param)
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	domain "jobgen-backend/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CoverLetterController struct {
+	coverLetterUsecase domain.ICoverLetterUsecase
+}
+
+func NewCoverLetterController(coverLetterUsecase domain.ICoverLetterUsecase) *CoverLetterController {
+	return &CoverLetterController{coverLetterUsecase: coverLetterUsecase}
+}
+
+type GenerateCoverLetterRequest struct {
+	CVID   string `json:"cv_id,omitempty"` // optional when re-generating; defaults to the CV of the latest draft
+	Tone   string `json:"tone,omitempty" binding:"omitempty,oneof=professional friendly enthusiastic formal"`
+	Length string `json:"length,omitempty" binding:"omitempty,oneof=short medium long"`
+}
+
+type EditCoverLetterRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// @Summary Generate a cover letter for a job
+// @Description Generate a new AI cover letter draft grounded in the job description and one of the user's CVs. Calling again re-generates and stores the next version.
+// @Tags Cover Letters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Param request body GenerateCoverLetterRequest true "Generation options"
+// @Success 201 {object} StandardResponse "Draft generated"
+// @Failure 400 {object} StandardResponse "Bad request"
+// @Failure 403 {object} StandardResponse "CV belongs to another user"
+// @Failure 404 {object} StandardResponse "Job or CV not found"
+// @Failure 409 {object} StandardResponse "CV has not finished processing"
+// @Failure 429 {object} StandardResponse "AI usage quota exceeded"
+// @Failure 503 {object} StandardResponse "AI service unavailable"
+// @Router /jobs/{id}/cover-letter [post]
+func (c *CoverLetterController) Generate(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		UnauthorizedResponse(ctx, "User authentication required")
+		return
+	}
+
+	var req GenerateCoverLetterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(ctx, err)
+		return
+	}
+
+	opts := domain.CoverLetterOptions{
+		Tone:   domain.CoverLetterTone(req.Tone),
+		Length: domain.CoverLetterLength(req.Length),
+	}
+	draft, err := c.coverLetterUsecase.Generate(ctx, userID, ctx.Param("id"), req.CVID, opts)
+	if err != nil {
+		handleCVJobError(ctx, err, "Failed to generate cover letter")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Cover letter generated successfully", draft)
+}
+
+// @Summary List cover letter drafts for a job
+// @Description List every stored version of the user's cover letter for a job, newest first
+// @Tags Cover Letters
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} StandardResponse "Drafts"
+// @Failure 401 {object} StandardResponse "Unauthorized"
+// @Failure 500 {object} StandardResponse "Internal server error"
+// @Router /jobs/{id}/cover-letter [get]
+func (c *CoverLetterController) ListDrafts(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		UnauthorizedResponse(ctx, "User authentication required")
+		return
+	}
+
+	drafts, err := c.coverLetterUsecase.ListDrafts(ctx, userID, ctx.Param("id"))
+	if err != nil {
+		InternalErrorResponse(ctx, "Failed to retrieve cover letter drafts")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Cover letter drafts retrieved successfully", gin.H{
+		"drafts": drafts,
+		"count":  len(drafts),
+	})
+}
+
+// @Summary Edit a cover letter
+// @Description Save the user's edited text as a new cover letter version
+// @Tags Cover Letters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Param request body EditCoverLetterRequest true "Edited content"
+// @Success 201 {object} StandardResponse "Draft saved"
+// @Failure 400 {object} StandardResponse "Bad request"
+// @Failure 404 {object} StandardResponse "No cover letter for this job yet"
+// @Router /jobs/{id}/cover-letter [put]
+func (c *CoverLetterController) Edit(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		UnauthorizedResponse(ctx, "User authentication required")
+		return
+	}
+
+	var req EditCoverLetterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(ctx, err)
+		return
+	}
+
+	draft, err := c.coverLetterUsecase.Edit(ctx, userID, ctx.Param("id"), req.Content)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			ErrorResponse(ctx, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		case errors.Is(err, domain.ErrNotFound):
+			NotFoundResponse(ctx, "No cover letter exists for this job yet")
+		default:
+			InternalErrorResponse(ctx, "Failed to save cover letter")
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Cover letter saved successfully", draft)
+}
+
+// @Summary Export a cover letter
+// @Description Download a cover letter draft as plain text, Markdown or PDF
+// @Tags Cover Letters
+// @Produce plain
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Param format query string false "Export format" Enums(txt, md, pdf) default(txt)
+// @Param version query int false "Draft version (defaults to latest)"
+// @Success 200 {file} file "Exported cover letter"
+// @Failure 400 {object} StandardResponse "Unsupported format or invalid version"
+// @Failure 404 {object} StandardResponse "Draft not found"
+// @Router /jobs/{id}/cover-letter/export [get]
+func (c *CoverLetterController) Export(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		UnauthorizedResponse(ctx, "User authentication required")
+		return
+	}
+
+	version, err := strconv.Atoi(ctx.DefaultQuery("version", "0"))
+	if err != nil || version < 0 {
+		ErrorResponse(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "version must be a positive integer", nil)
+		return
+	}
+	format := domain.ExportFormat(ctx.DefaultQuery("format", string(domain.ExportPlainText)))
+
+	doc, err := c.coverLetterUsecase.Export(ctx, userID, ctx.Param("id"), version, format)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			ErrorResponse(ctx, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		case errors.Is(err, domain.ErrNotFound):
+			NotFoundResponse(ctx, "Cover letter draft not found")
+		default:
+			InternalErrorResponse(ctx, "Failed to export cover letter")
+		}
+		return
+	}
+
+	sendExportedDocument(ctx, doc)
+}
+
+// sendExportedDocument writes a rendered document as a file download
+func sendExportedDocument(ctx *gin.Context, doc *domain.ExportedDocument) {
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", doc.FileName))
+	ctx.Data(http.StatusOK, doc.ContentType, doc.Data)
+}
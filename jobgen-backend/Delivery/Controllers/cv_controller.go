@@ -1,12 +1,20 @@
 package controllers
 
 import (
+	"errors"
+	"io"
+	domain "jobgen-backend/Domain"
 	usecases "jobgen-backend/Usecases"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// sseHeartbeatInterval keeps idle event streams from being closed by proxies.
+const sseHeartbeatInterval = 15 * time.Second
+
 type CVController struct {
 	cvUsecase usecases.CVUsecase
 }
@@ -19,16 +27,29 @@ type CVFileRefRequest struct {
 	FileID string `json:"fileId"`
 }
 
+type CVTailorRequest struct {
+	JobID string `json:"jobId" binding:"required"`
+}
+
+type CVWebhookRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+type CVReviewRequest struct {
+	Corrections []domain.CVFieldCorrection `json:"corrections" binding:"required,min=1,dive"`
+}
+
 // @Summary Start CV parsing job (multipart)
-// @Description Upload a CV PDF via multipart and start a parsing job.
+// @Description Upload a CV via multipart and start a parsing job. Supported formats are PDF, DOCX, ODT, RTF and plain text/Markdown, detected from the file content.
 // @Tags CV
 // @Accept mpfd
 // @Produce json
 // @Security BearerAuth
-// @Param file formData file true "CV PDF file"
+// @Param file formData file true "CV file (PDF, DOCX, ODT, RTF or TXT/Markdown)"
 // @Success 202 {object} map[string]interface{} "Job accepted"
 // @Failure 400 {object} controllers.StandardResponse
 // @Failure 401 {object} controllers.StandardResponse
+// @Failure 415 {object} controllers.StandardResponse "Unsupported file format"
 // @Failure 500 {object} controllers.StandardResponse
 // @Router /cv/parse [post]
 func (ctrl *CVController) StartParsingJobHandler(c *gin.Context) {
@@ -47,7 +68,7 @@ func (ctrl *CVController) StartParsingJobHandler(c *gin.Context) {
 
 	jobID, err := ctrl.cvUsecase.CreateParsingJob(userID.(string), file)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create parsing job", "details": err.Error()})
+		respondUploadError(c, err)
 		return
 	}
 
@@ -65,11 +86,12 @@ func (ctrl *CVController) StartParsingJobHandler(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param file formData file false "CV PDF file"
+// @Param file formData file false "CV file (PDF, DOCX, ODT, RTF or TXT/Markdown)"
 // @Param request body controllers.CVFileRefRequest false "Provide when using existing fileId"
 // @Success 202 {object} map[string]interface{} "Job accepted"
 // @Failure 400 {object} controllers.StandardResponse
 // @Failure 401 {object} controllers.StandardResponse
+// @Failure 415 {object} controllers.StandardResponse "Unsupported file format"
 // @Failure 500 {object} controllers.StandardResponse
 // @Router /cv [post]
 func (ctrl *CVController) StartParsingJobFromRef(c *gin.Context) {
@@ -84,7 +106,7 @@ func (ctrl *CVController) StartParsingJobFromRef(c *gin.Context) {
 	if file, err := c.FormFile("file"); err == nil && file != nil {
 		jobID, err := ctrl.cvUsecase.CreateParsingJob(userID.(string), file)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create parsing job", "details": err.Error()})
+			respondUploadError(c, err)
 			return
 		}
 		c.JSON(http.StatusAccepted, gin.H{"message": "CV parsing job accepted.", "jobId": jobID})
@@ -136,3 +158,549 @@ func (ctrl *CVController) GetParsingJobStatusHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, cv)
 }
+
+// TailorForJobHandler rewrites a parsed CV for a specific job and stores it as a new CV
+// @Summary Tailor a CV for a job
+// @Description Rewrite the profile summary and experience descriptions of a parsed CV to target a job. The result is stored as a new CV linked to the source CV and the job, with a field-level diff in "changes".
+// @Tags CV
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Source CV ID"
+// @Param request body controllers.CVTailorRequest true "Target job"
+// @Success 201 {object} domain.CV "Tailored CV"
+// @Failure 400 {object} controllers.StandardResponse
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Failure 409 {object} controllers.StandardResponse "CV has not finished processing"
+// @Failure 429 {object} controllers.StandardResponse "AI usage quota exceeded"
+// @Failure 503 {object} controllers.StandardResponse "AI service unavailable"
+// @Router /cv/{id}/tailor [post]
+func (ctrl *CVController) TailorForJobHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var body CVTailorRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "jobId is required"})
+		return
+	}
+
+	cv, err := ctrl.cvUsecase.TailorForJob(c, userID.(string), c.Param("id"), body.JobID)
+	if err != nil {
+		respondCVError(c, err, "failed to tailor cv")
+		return
+	}
+
+	c.JSON(http.StatusCreated, cv)
+}
+
+// ListCVsHandler lists the user's CVs
+// @Summary List my CVs
+// @Description List the latest version of each of the authenticated user's CVs, newest first.
+// @Tags CV
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.CV
+// @Failure 401 {object} controllers.StandardResponse
+// @Failure 500 {object} controllers.StandardResponse
+// @Router /cv [get]
+func (ctrl *CVController) ListCVsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	cvs, err := ctrl.cvUsecase.ListUserCVs(userID.(string))
+	if err != nil {
+		respondCVError(c, err, "failed to list cvs")
+		return
+	}
+
+	c.JSON(http.StatusOK, cvs)
+}
+
+// SetPrimaryCVHandler marks a CV as the user's primary CV
+// @Summary Set primary CV
+// @Description Mark a CV (and all of its versions) as the user's primary CV. Any previous primary CV is unmarked.
+// @Tags CV
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "CV ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Router /cv/{id}/primary [put]
+func (ctrl *CVController) SetPrimaryCVHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	if err := ctrl.cvUsecase.SetPrimaryCV(userID.(string), c.Param("id")); err != nil {
+		respondCVError(c, err, "failed to set primary cv")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "isPrimary": true})
+}
+
+// UpdateCVSectionsHandler saves a manual edit of a CV as a new version
+// @Summary Edit CV sections
+// @Description Manually edit the parsed sections of a CV. Omitted sections are left unchanged; provided sections replace the existing ones. The edit is stored as a new version.
+// @Tags CV
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "CV ID (any version)"
+// @Param request body domain.CVSectionsUpdate true "Sections to replace"
+// @Success 201 {object} domain.CV "New version"
+// @Failure 400 {object} controllers.StandardResponse
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Failure 409 {object} controllers.StandardResponse "CV has not finished processing"
+// @Router /cv/{id} [put]
+func (ctrl *CVController) UpdateCVSectionsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var body domain.CVSectionsUpdate
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	cv, err := ctrl.cvUsecase.UpdateSections(userID.(string), c.Param("id"), body)
+	if err != nil {
+		respondCVError(c, err, "failed to update cv")
+		return
+	}
+
+	c.JSON(http.StatusCreated, cv)
+}
+
+// GetCVVersionsHandler returns a CV's version history
+// @Summary CV version history
+// @Description List every version of a CV, oldest first, with the field-level changes each version introduced.
+// @Tags CV
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "CV ID (any version)"
+// @Success 200 {array} domain.CVVersionSummary
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Router /cv/{id}/versions [get]
+func (ctrl *CVController) GetCVVersionsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	history, err := ctrl.cvUsecase.GetVersionHistory(userID.(string), c.Param("id"))
+	if err != nil {
+		respondCVError(c, err, "failed to get cv versions")
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// RestoreCVVersionHandler restores an earlier version as the newest one
+// @Summary Restore CV version
+// @Description Copy an earlier version of a CV into a new latest version. History is never rewritten.
+// @Tags CV
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "CV ID (any version)"
+// @Param version path int true "Version number to restore"
+// @Success 201 {object} domain.CV "New version"
+// @Failure 400 {object} controllers.StandardResponse
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Router /cv/{id}/versions/{version}/restore [post]
+func (ctrl *CVController) RestoreCVVersionHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version must be a positive integer"})
+		return
+	}
+
+	cv, err := ctrl.cvUsecase.RestoreVersion(userID.(string), c.Param("id"), version)
+	if err != nil {
+		respondCVError(c, err, "failed to restore cv version")
+		return
+	}
+
+	c.JSON(http.StatusCreated, cv)
+}
+
+// AcceptSuggestionHandler applies an AI suggestion to the CV
+// @Summary Accept CV suggestion
+// @Description Apply a suggestion's replacement to the section it targets (profile summary, an experience description or the skills list). The result is stored as a new version in which the suggestion is marked applied and the score is recalculated.
+// @Tags CV
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "CV ID"
+// @Param suggestionId path string true "Suggestion ID"
+// @Success 201 {object} domain.CV "New version"
+// @Failure 400 {object} controllers.StandardResponse "Suggestion already handled or no longer applicable"
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Failure 409 {object} controllers.StandardResponse "CV has not finished processing"
+// @Router /cv/{id}/suggestions/{suggestionId}/accept [post]
+func (ctrl *CVController) AcceptSuggestionHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	cv, err := ctrl.cvUsecase.AcceptSuggestion(userID.(string), c.Param("id"), c.Param("suggestionId"))
+	if err != nil {
+		respondCVError(c, err, "failed to apply suggestion")
+		return
+	}
+
+	c.JSON(http.StatusCreated, cv)
+}
+
+// RejectSuggestionHandler dismisses an AI suggestion
+// @Summary Reject CV suggestion
+// @Description Mark a suggestion as rejected. The CV content and score are unchanged and no version is created.
+// @Tags CV
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "CV ID"
+// @Param suggestionId path string true "Suggestion ID"
+// @Success 200 {object} domain.CV
+// @Failure 400 {object} controllers.StandardResponse "Suggestion already handled"
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Failure 409 {object} controllers.StandardResponse "CV has not finished processing"
+// @Router /cv/{id}/suggestions/{suggestionId}/reject [post]
+func (ctrl *CVController) RejectSuggestionHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	cv, err := ctrl.cvUsecase.RejectSuggestion(userID.(string), c.Param("id"), c.Param("suggestionId"))
+	if err != nil {
+		respondCVError(c, err, "failed to reject suggestion")
+		return
+	}
+
+	c.JSON(http.StatusOK, cv)
+}
+
+// GetCVReviewHandler lists the low-confidence fields of a parsed CV
+// @Summary Get CV fields to review
+// @Description List the fields the parser was unsure about (or could not find), with their parsed values and confidence, so the user can confirm or correct them.
+// @Tags CV
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "CV ID"
+// @Success 200 {object} domain.CVReview
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Failure 409 {object} controllers.StandardResponse "CV has not finished processing"
+// @Router /cv/{id}/review [get]
+func (ctrl *CVController) GetCVReviewHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	review, err := ctrl.cvUsecase.GetReview(userID.(string), c.Param("id"))
+	if err != nil {
+		respondCVError(c, err, "failed to get cv review")
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// SubmitCVReviewHandler confirms or corrects low-confidence fields
+// @Summary Confirm or correct CV fields
+// @Description Confirm parsed values or replace them with corrections. Contact fields take a string value; sections take a list in the same shape as the CV. The reviewed CV is saved as a new version with origin review; once no field is left to review its status is Completed rather than NeedsReview.
+// @Tags CV
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "CV ID"
+// @Param request body controllers.CVReviewRequest true "Field confirmations and corrections"
+// @Success 200 {object} domain.CV
+// @Failure 400 {object} controllers.StandardResponse
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Failure 409 {object} controllers.StandardResponse "CV has not finished processing"
+// @Router /cv/{id}/review [post]
+func (ctrl *CVController) SubmitCVReviewHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var body CVReviewRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "corrections must list at least one field with an action"})
+		return
+	}
+
+	cv, err := ctrl.cvUsecase.SubmitReview(c, userID.(string), c.Param("id"), body.Corrections)
+	if err != nil {
+		respondCVError(c, err, "failed to save cv review")
+		return
+	}
+
+	c.JSON(http.StatusOK, cv)
+}
+
+// ListDeadLetteredJobsHandler lists CV processing jobs that exhausted their retries
+// @Summary List dead-lettered CV jobs
+// @Description Admin only. Lists CV processing jobs the queue gave up on, newest first, with the attempt count and the last failure.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.DeadLetteredJob
+// @Failure 401 {object} controllers.StandardResponse
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 500 {object} controllers.StandardResponse
+// @Router /admin/cv-jobs/dead-letters [get]
+func (ctrl *CVController) ListDeadLetteredJobsHandler(c *gin.Context) {
+	jobs, err := ctrl.cvUsecase.ListDeadLetteredJobs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list dead-lettered jobs", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// RequeueDeadLetteredJobHandler puts a dead-lettered CV job back on the queue
+// @Summary Requeue a dead-lettered CV job
+// @Description Admin only. Resets the CV to Pending and queues its processing job again with a fresh retry budget.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param jobId path string true "CV job ID"
+// @Success 202 {object} map[string]interface{} "Job requeued"
+// @Failure 401 {object} controllers.StandardResponse
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Failure 500 {object} controllers.StandardResponse
+// @Router /admin/cv-jobs/dead-letters/{jobId}/requeue [post]
+func (ctrl *CVController) RequeueDeadLetteredJobHandler(c *gin.Context) {
+	jobID := c.Param("jobId")
+	if err := ctrl.cvUsecase.RequeueDeadLetteredJob(jobID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "job is not dead-lettered"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to requeue job", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "CV parsing job requeued.",
+		"jobId":   jobID,
+	})
+}
+
+// StreamJobEventsHandler streams a CV parsing job's progress as server-sent events
+// @Summary Stream CV parsing job events
+// @Description Server-sent event stream of a parsing job. The first "status" event carries the job's current status; later "status" events report transitions (Pending, Processing, Completed, NeedsReview, Failed) and "stage" events report intermediate steps (text_extracted, sections_parsed, ai_suggestions_done). The stream ends after a final status.
+// @Tags CV
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param jobId path string true "Parsing Job ID"
+// @Success 200 {object} domain.CVJobEvent "Event stream"
+// @Failure 401 {object} controllers.StandardResponse
+// @Failure 403 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Router /cv/parse/{jobId}/events [get]
+func (ctrl *CVController) StreamJobEventsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	cv, events, err := ctrl.cvUsecase.SubscribeJobEvents(c.Request.Context(), userID.(string), c.Param("jobId"))
+	if err != nil {
+		respondCVError(c, err, "failed to subscribe to job events")
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // keep reverse proxies from buffering the stream
+
+	current := domain.CVJobEvent{JobID: cv.ID, Type: domain.CVEventStatus, Status: cv.Status, Message: cv.ProcessingError, At: cv.UpdatedAt}
+	c.SSEvent(string(current.Type), current)
+	c.Writer.Flush()
+	if current.Terminal() {
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event)
+			return !event.Terminal()
+		case <-heartbeat.C:
+			io.WriteString(w, ": keep-alive\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// RegisterWebhookHandler registers a URL to be called when the user's CV jobs finish
+// @Summary Register a CV job webhook
+// @Description Registers a URL that receives a POST whenever one of the user's CV parsing jobs finishes. Callbacks carry the headers X-JobGen-Event, X-JobGen-Timestamp and X-JobGen-Signature ("sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the webhook secret). The secret is only returned by this call.
+// @Tags CV
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body controllers.CVWebhookRequest true "Callback URL"
+// @Success 201 {object} map[string]interface{} "Webhook with its signing secret"
+// @Failure 400 {object} controllers.StandardResponse
+// @Failure 401 {object} controllers.StandardResponse
+// @Failure 500 {object} controllers.StandardResponse
+// @Router /cv/webhooks [post]
+func (ctrl *CVController) RegisterWebhookHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req CVWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+
+	hook, err := ctrl.cvUsecase.RegisterWebhook(c.Request.Context(), userID.(string), req.URL)
+	if err != nil {
+		respondCVError(c, err, "failed to register webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":        hook.ID,
+		"url":       hook.URL,
+		"secret":    hook.Secret,
+		"createdAt": hook.CreatedAt,
+	})
+}
+
+// ListWebhooksHandler lists the user's CV job webhooks
+// @Summary List CV job webhooks
+// @Description Lists the user's webhooks with the outcome of their latest delivery. Secrets are not included.
+// @Tags CV
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.CVWebhook
+// @Failure 401 {object} controllers.StandardResponse
+// @Failure 500 {object} controllers.StandardResponse
+// @Router /cv/webhooks [get]
+func (ctrl *CVController) ListWebhooksHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	hooks, err := ctrl.cvUsecase.ListWebhooks(c.Request.Context(), userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list webhooks", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, hooks)
+}
+
+// DeleteWebhookHandler removes one of the user's CV job webhooks
+// @Summary Delete a CV job webhook
+// @Tags CV
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 204 "Webhook deleted"
+// @Failure 401 {object} controllers.StandardResponse
+// @Failure 404 {object} controllers.StandardResponse
+// @Failure 500 {object} controllers.StandardResponse
+// @Router /cv/webhooks/{id} [delete]
+func (ctrl *CVController) DeleteWebhookHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	if err := ctrl.cvUsecase.DeleteWebhook(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete webhook", "details": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondUploadError reports a failed upload, telling unsupported file types apart from server errors
+func respondUploadError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrUnsupportedCVFormat) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create parsing job", "details": err.Error()})
+}
+
+// respondCVError maps usecase errors onto the CV endpoints' error shape
+func respondCVError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "you are not authorized to use this CV"})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "cv, job or suggestion not found"})
+	case errors.Is(err, domain.ErrCVNotReady):
+		c.JSON(http.StatusConflict, gin.H{"error": "cv has not finished processing"})
+	case errors.Is(err, domain.ErrAIQuotaExceeded):
+		QuotaExceededResponse(c, err)
+	case errors.Is(err, domain.ErrServiceUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "ai service unavailable, please try again later"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback, "details": err.Error()})
+	}
+}
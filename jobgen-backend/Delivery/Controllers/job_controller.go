@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -364,6 +365,121 @@ func (c *JobController) SearchJobsBySkills(ctx *gin.Context) {
 	})
 }
 
+// @Summary Give feedback on a matched job
+// @Description Rate a matched job (thumbs_up, thumbs_down) or dismiss it (not_interested, with an optional reason). Feedback hides dismissed jobs from future matches and tunes the user's match weights.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Param request body JobFeedbackRequest true "Feedback"
+// @Success 200 {object} StandardResponse "Feedback recorded"
+// @Failure 400 {object} StandardResponse "Bad request"
+// @Failure 401 {object} StandardResponse "Unauthorized"
+// @Failure 404 {object} StandardResponse "Job not found"
+// @Failure 500 {object} StandardResponse "Internal server error"
+// @Router /jobs/{id}/feedback [post]
+func (c *JobController) SubmitJobFeedback(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		UnauthorizedResponse(ctx, "User authentication required")
+		return
+	}
+
+	jobID := ctx.Param("id")
+	if jobID == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Job ID is required", nil)
+		return
+	}
+
+	var req JobFeedbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(ctx, err)
+		return
+	}
+
+	feedback, err := c.jobUsecase.SubmitJobFeedback(ctx, userID, jobID, domain.FeedbackType(req.Type), strings.TrimSpace(req.Reason))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidFeedback), errors.Is(err, domain.ErrInvalidInput):
+			ErrorResponse(ctx, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		case errors.Is(err, domain.ErrNotFound):
+			NotFoundResponse(ctx, "Job not found")
+		default:
+			InternalErrorResponse(ctx, "Failed to record feedback")
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Feedback recorded successfully", gin.H{
+		"feedback": feedback,
+	})
+}
+
+// @Summary Analyze how well a CV fits a job
+// @Description Compare a parsed CV against a job's skills and experience requirement. Returns matching and missing skills, a fit score and, optionally, AI-tailored suggestions.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Param request body GapAnalysisRequest true "CV to compare"
+// @Success 200 {object} StandardResponse "Gap analysis"
+// @Failure 400 {object} StandardResponse "Bad request"
+// @Failure 401 {object} StandardResponse "Unauthorized"
+// @Failure 403 {object} StandardResponse "CV belongs to another user"
+// @Failure 404 {object} StandardResponse "Job or CV not found"
+// @Failure 409 {object} StandardResponse "CV has not finished processing"
+// @Failure 500 {object} StandardResponse "Internal server error"
+// @Router /jobs/{id}/gap-analysis [post]
+func (c *JobController) AnalyzeJobGap(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		UnauthorizedResponse(ctx, "User authentication required")
+		return
+	}
+
+	jobID := ctx.Param("id")
+	if jobID == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "VALIDATION_ERROR", "Job ID is required", nil)
+		return
+	}
+
+	var req GapAnalysisRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ValidationErrorResponse(ctx, err)
+		return
+	}
+
+	analysis, err := c.jobUsecase.AnalyzeJobGap(ctx, userID, jobID, req.CVID, req.IncludeSuggestions)
+	if err != nil {
+		handleCVJobError(ctx, err, "Failed to analyze CV against job")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Gap analysis completed successfully", analysis)
+}
+
+// handleCVJobError maps errors from endpoints that combine a job with one of the user's CVs
+func handleCVJobError(ctx *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		ErrorResponse(ctx, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+	case errors.Is(err, domain.ErrForbidden):
+		ForbiddenResponse(ctx, "You are not authorized to use this CV")
+	case errors.Is(err, domain.ErrCVNotReady):
+		ConflictResponse(ctx, "CV has not finished processing")
+	case errors.Is(err, domain.ErrNotFound):
+		NotFoundResponse(ctx, "Job or CV not found")
+	case errors.Is(err, domain.ErrAIQuotaExceeded):
+		QuotaExceededResponse(ctx, err)
+	case errors.Is(err, domain.ErrServiceUnavailable):
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "AI_UNAVAILABLE", "AI service is currently unavailable, please try again later", nil)
+	default:
+		InternalErrorResponse(ctx, fallback)
+	}
+}
+
 // Admin endpoints
 
 // @Summary Trigger job aggregation
@@ -531,6 +647,27 @@ func (c *JobController) DeleteJob(ctx *gin.Context) {
 	SuccessResponse(ctx, http.StatusOK, "Job deleted successfully", nil)
 }
 
+// @Summary Get match precision report
+// @Description Aggregate user feedback on matched jobs by match score band (Admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} StandardResponse "Match precision report"
+// @Failure 401 {object} StandardResponse "Unauthorized"
+// @Failure 403 {object} StandardResponse "Forbidden"
+// @Failure 500 {object} StandardResponse "Internal server error"
+// @Router /admin/jobs/match-report [get]
+func (c *JobController) GetMatchPrecisionReport(ctx *gin.Context) {
+	report, err := c.jobUsecase.GetMatchPrecisionReport(ctx)
+	if err != nil {
+		InternalErrorResponse(ctx, "Failed to get match precision report")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Match precision report retrieved successfully", report)
+}
+
 // Request structs for Swagger documentation
 type CreateJobRequest struct {
 	Title                  string   `json:"title" binding:"required"`
@@ -553,3 +690,13 @@ type UpdateJobRequest struct {
 	Description     *string   `json:"description,omitempty"`
 	ExtractedSkills *[]string `json:"extracted_skills,omitempty"`
 }
+
+type JobFeedbackRequest struct {
+	Type   string `json:"type" binding:"required,oneof=thumbs_up thumbs_down not_interested"`
+	Reason string `json:"reason,omitempty" binding:"max=500"`
+}
+
+type GapAnalysisRequest struct {
+	CVID               string `json:"cv_id" binding:"required"`
+	IncludeSuggestions bool   `json:"include_suggestions,omitempty"`
+}
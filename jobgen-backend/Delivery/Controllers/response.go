@@ -1,7 +1,12 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	domain "jobgen-backend/Domain"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -79,6 +84,21 @@ func InternalErrorResponse(ctx *gin.Context, message string) {
 	ErrorResponse(ctx, http.StatusInternalServerError, "INTERNAL_ERROR", message, nil)
 }
 
+// QuotaExceededResponse reports that the user has used up an AI quota and when it resets.
+func QuotaExceededResponse(ctx *gin.Context, err error) {
+	var quotaErr *domain.AIQuotaError
+	if !errors.As(err, &quotaErr) {
+		ErrorResponse(ctx, http.StatusTooManyRequests, "AI_QUOTA_EXCEEDED", "AI usage quota exceeded", nil)
+		return
+	}
+	if wait := time.Until(quotaErr.ResetAt); wait > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+	}
+	ErrorResponse(ctx, http.StatusTooManyRequests, "AI_QUOTA_EXCEEDED",
+		fmt.Sprintf("You have used your %s AI %s quota; it resets at %s", quotaErr.Period, quotaErr.Unit, quotaErr.ResetAt.Format(time.RFC3339)),
+		quotaErr)
+}
+
 func PaginatedSuccessResponse(ctx *gin.Context, statusCode int, message string, paginatedData *PaginatedResponse) {
 	response := StandardResponse{
 		Success: true,
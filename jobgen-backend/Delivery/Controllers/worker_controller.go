@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	domain "jobgen-backend/Domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CVWorkerStatsProvider is implemented by the CV worker pool.
+type CVWorkerStatsProvider interface {
+	Stats() domain.CVWorkerStats
+}
+
+type WorkerController struct {
+	worker CVWorkerStatsProvider
+}
+
+func NewWorkerController(worker CVWorkerStatsProvider) *WorkerController {
+	return &WorkerController{worker: worker}
+}
+
+// Health reports whether the CV worker pool is taking jobs
+// @Summary CV worker health
+// @Description Returns 200 while the CV worker pool is running and 503 once it has stopped or is shutting down.
+// @Tags Worker
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /worker/health [get]
+func (ctrl *WorkerController) Health(c *gin.Context) {
+	stats := ctrl.worker.Stats()
+	if !stats.Running {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "stopped"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "inFlight": stats.InFlight})
+}
+
+// Metrics returns the CV worker pool's job counters
+// @Summary CV worker metrics
+// @Description Job counters of the CV worker pool since the process started. On a process that also serves the API, only admins may read them.
+// @Tags Worker
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.CVWorkerStats
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /worker/metrics [get]
+func (ctrl *WorkerController) Metrics(c *gin.Context) {
+	c.JSON(http.StatusOK, ctrl.worker.Stats())
+}
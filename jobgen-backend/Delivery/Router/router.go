@@ -23,6 +23,8 @@ func SetupRouter(
 	cvController *controllers.CVController,
 	contactController *controllers.ContactController,
 	chatController *controllers.ChatController, // Add this parameter
+	coverLetterController *controllers.CoverLetterController,
+	aiController *controllers.AIController,
 ) *gin.Engine {
 	r := gin.New()
 
@@ -105,6 +107,13 @@ func SetupRouter(
 			authenticated.Use(authMiddleware.RequireAuth())
 			{
 				authenticated.GET("/matched", jobController.GetMatchedJobs)
+				authenticated.POST("/:id/feedback", jobController.SubmitJobFeedback)
+				authenticated.POST("/:id/gap-analysis", jobController.AnalyzeJobGap)
+
+				authenticated.POST("/:id/cover-letter", coverLetterController.Generate)
+				authenticated.GET("/:id/cover-letter", coverLetterController.ListDrafts)
+				authenticated.PUT("/:id/cover-letter", coverLetterController.Edit)
+				authenticated.GET("/:id/cover-letter/export", coverLetterController.Export)
 			}
 		}
 
@@ -119,10 +128,20 @@ func SetupRouter(
 			jobAdmin := admin.Group("/jobs")
 			{
 				jobAdmin.POST("/aggregate", jobController.TriggerJobAggregation)
+				jobAdmin.GET("/match-report", jobController.GetMatchPrecisionReport)
 				jobAdmin.POST("/", jobController.CreateJob)
 				jobAdmin.PUT("/:id", jobController.UpdateJob)
 				jobAdmin.DELETE("/:id", jobController.DeleteJob)
 			}
+
+			cvJobAdmin := admin.Group("/cv-jobs")
+			{
+				cvJobAdmin.GET("/dead-letters", cvController.ListDeadLetteredJobsHandler)
+				cvJobAdmin.POST("/dead-letters/:jobId/requeue", cvController.RequeueDeadLetteredJobHandler)
+			}
+
+			admin.GET("/ai/usage", aiController.Usage)
+			admin.GET("/ai/usage/users", aiController.UserUsage)
 		}
 
 		files := api.Group("/files")
@@ -140,10 +159,24 @@ func SetupRouter(
 		cv := api.Group("/cv")
 		cv.Use(authMiddleware.RequireAuth())
 		{
+			cv.GET("/", cvController.ListCVsHandler)
 			cv.POST("/", cvController.StartParsingJobFromRef)
 			cv.POST("/parse", cvController.StartParsingJobHandler)
 			cv.GET("/parse/:jobId/status", cvController.GetParsingJobStatusHandler)
+			cv.GET("/parse/:jobId/events", cvController.StreamJobEventsHandler)
+			cv.POST("/webhooks", cvController.RegisterWebhookHandler)
+			cv.GET("/webhooks", cvController.ListWebhooksHandler)
+			cv.DELETE("/webhooks/:id", cvController.DeleteWebhookHandler)
 			cv.GET("/:id", cvController.GetParsingJobStatusHandler)
+			cv.PUT("/:id", cvController.UpdateCVSectionsHandler)
+			cv.POST("/:id/tailor", cvController.TailorForJobHandler)
+			cv.PUT("/:id/primary", cvController.SetPrimaryCVHandler)
+			cv.GET("/:id/review", cvController.GetCVReviewHandler)
+			cv.POST("/:id/review", cvController.SubmitCVReviewHandler)
+			cv.GET("/:id/versions", cvController.GetCVVersionsHandler)
+			cv.POST("/:id/versions/:version/restore", cvController.RestoreCVVersionHandler)
+			cv.POST("/:id/suggestions/:suggestionId/accept", cvController.AcceptSuggestionHandler)
+			cv.POST("/:id/suggestions/:suggestionId/reject", cvController.RejectSuggestionHandler)
 		}
 
 		// Chat routes - moved inside the api group
@@ -151,9 +184,13 @@ func SetupRouter(
 		chatRoutes.Use(authMiddleware.RequireAuth())
 		{
 			chatRoutes.POST("/message", chatController.SendMessage)
+			chatRoutes.POST("/message/stream", chatController.SendMessageStream)
 			chatRoutes.GET("/sessions", chatController.GetUserSessions)
 			chatRoutes.GET("/session/:session_id", chatController.GetSessionHistory)
+			chatRoutes.PATCH("/session/:session_id", chatController.UpdateSession)
 			chatRoutes.DELETE("/session/:session_id", chatController.DeleteSession)
+			chatRoutes.GET("/session/:session_id/export", chatController.ExportSession)
+			chatRoutes.GET("/search", chatController.SearchMessages)
 		}
 	}
 
@@ -172,3 +209,25 @@ func SetupRouter(
 
 	return r
 }
+
+// RegisterWorkerRoutes exposes the CV worker pool's health and metrics endpoints. The
+// metricsAuth handlers guard the metrics on a router that also serves the public API.
+func RegisterWorkerRoutes(r *gin.Engine, workerController *controllers.WorkerController, metricsAuth ...gin.HandlerFunc) {
+	workerGroup := r.Group("/api/v1/worker")
+	{
+		workerGroup.GET("/health", workerController.Health)
+		workerGroup.GET("/metrics", append(metricsAuth, workerController.Metrics)...)
+	}
+}
+
+// SetupWorkerRouter builds the router of a worker-only process, which serves no API routes.
+// Its port is meant for internal health checks and scraping, so the metrics are not guarded.
+func SetupWorkerRouter(workerController *controllers.WorkerController) *gin.Engine {
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	RegisterWorkerRoutes(r, workerController)
+	r.GET("/health", workerController.Health)
+
+	return r
+}
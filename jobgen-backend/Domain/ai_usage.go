@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AIUsage counts model calls and the tokens they used. Requests answered from the cache or
+// by an identical in-flight request are counted apart and use no tokens.
+type AIUsage struct {
+	Requests         int64 `bson:"requests" json:"requests"` // requests sent to the model
+	Failures         int64 `bson:"failures" json:"failures"` // calls that failed after all retries
+	Retries          int64 `bson:"retries" json:"retries"`
+	CacheHits        int64 `bson:"cacheHits" json:"cacheHits"`
+	Deduplicated     int64 `bson:"deduplicated" json:"deduplicated"`
+	PromptTokens     int64 `bson:"promptTokens" json:"promptTokens"`
+	CompletionTokens int64 `bson:"completionTokens" json:"completionTokens"`
+}
+
+// AIUsageStats is a process's AI accounting since it started, broken down by operation
+// (e.g. "chat", "cv_suggestions").
+type AIUsageStats struct {
+	Provider   string             `json:"provider"`
+	Available  bool               `json:"available"`
+	Since      time.Time          `json:"since"`
+	Total      AIUsage            `json:"total"`
+	Operations map[string]AIUsage `json:"operations"`
+}
+
+// AIQuota limits a user's model calls. A zero limit means unlimited. Requests answered from
+// the cache do not count against it.
+type AIQuota struct {
+	DailyRequests   int64 `json:"dailyRequests"`
+	DailyTokens     int64 `json:"dailyTokens"`
+	MonthlyRequests int64 `json:"monthlyRequests"`
+	MonthlyTokens   int64 `json:"monthlyTokens"`
+}
+
+// AIPricing is the cost of model tokens, used to estimate what each user's usage costs.
+type AIPricing struct {
+	PromptPer1K     float64 `json:"promptPer1K"`
+	CompletionPer1K float64 `json:"completionPer1K"`
+}
+
+// Cost returns the estimated cost of the tokens in u.
+func (p AIPricing) Cost(u AIUsage) float64 {
+	return float64(u.PromptTokens)/1000*p.PromptPer1K + float64(u.CompletionTokens)/1000*p.CompletionPer1K
+}
+
+// AIUsageRecord is one user's usage of one operation on one day (UTC).
+type AIUsageRecord struct {
+	UserID    string  `bson:"userId" json:"userId"`
+	Day       string  `bson:"day" json:"day"` // YYYY-MM-DD
+	Operation string  `bson:"operation" json:"operation"`
+	Usage     AIUsage `bson:",inline" json:"usage"`
+	CostUSD   float64 `bson:"costUsd" json:"costUsd"`
+}
+
+// AIUsageFilter selects usage records. Days are inclusive; empty fields match everything.
+type AIUsageFilter struct {
+	UserID  string
+	FromDay string
+	ToDay   string
+}
+
+// AIUserUsage is a user's usage over a reporting period.
+type AIUserUsage struct {
+	UserID     string             `json:"userId"`
+	Total      AIUsage            `json:"total"`
+	CostUSD    float64            `json:"costUsd"`
+	Operations map[string]AIUsage `json:"operations"`
+}
+
+// AIUsageReport is the per-user usage between two days, most expensive users first.
+type AIUsageReport struct {
+	From    string        `json:"from"`
+	To      string        `json:"to"`
+	Total   AIUsage       `json:"total"`
+	CostUSD float64       `json:"costUsd"`
+	Users   []AIUserUsage `json:"users"`
+}
+
+// AIQuotaError is returned when a user has used up a quota. It matches ErrAIQuotaExceeded.
+type AIQuotaError struct {
+	Period  string    `json:"period"` // "daily" or "monthly"
+	Unit    string    `json:"unit"`   // "requests" or "tokens"
+	Limit   int64     `json:"limit"`
+	Used    int64     `json:"used"`
+	ResetAt time.Time `json:"resetAt"`
+}
+
+func (e *AIQuotaError) Error() string {
+	return fmt.Sprintf("%s ai %s quota exceeded: used %d of %d", e.Period, e.Unit, e.Used, e.Limit)
+}
+
+func (e *AIQuotaError) Is(target error) bool { return target == ErrAIQuotaExceeded }
+
+// Add accumulates other into u.
+func (u *AIUsage) Add(other AIUsage) {
+	u.Requests += other.Requests
+	u.Failures += other.Failures
+	u.Retries += other.Retries
+	u.CacheHits += other.CacheHits
+	u.Deduplicated += other.Deduplicated
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+}
+
+// Tokens is the number of prompt and completion tokens used.
+func (u AIUsage) Tokens() int64 { return u.PromptTokens + u.CompletionTokens }
+
+type aiUserKey struct{}
+
+// WithAIUser attributes the AI calls made with ctx to a user, so they count against the
+// user's quota.
+func WithAIUser(ctx context.Context, userID string) context.Context {
+	if userID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, aiUserKey{}, userID)
+}
+
+// AIUserFromContext returns the user AI calls made with ctx are attributed to, if any.
+func AIUserFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(aiUserKey{}).(string)
+	return userID
+}
+
+// IAIUsageMeter enforces per-user quotas and records what each user's AI calls used.
+type IAIUsageMeter interface {
+	// Allow returns an *AIQuotaError when the user may not make another model call.
+	Allow(ctx context.Context, userID string) error
+	Record(ctx context.Context, userID, operation string, usage AIUsage) error
+}
+
+type IAIUsageRepository interface {
+	// Add increments the usage of the record's user, day and operation.
+	Add(ctx context.Context, record AIUsageRecord) error
+	List(ctx context.Context, filter AIUsageFilter) ([]AIUsageRecord, error)
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 )
 
@@ -15,21 +16,27 @@ type ChatRequest struct {
 
 // ChatResponse represents a response from the AI chatbot
 type ChatResponse struct {
-	SessionID   string        `json:"session_id"`
-	Message     string        `json:"message"`
-	History     []ChatMessage `json:"history,omitempty"`
-	Suggestions []Suggestion  `json:"suggestions,omitempty"`
+	SessionID   string       `json:"session_id"`
+	MessageID   string       `json:"message_id,omitempty"` // the saved reply
+	Message     string       `json:"message"`
+	Suggestions []Suggestion `json:"suggestions,omitempty"`
+	Jobs        []Job        `json:"jobs,omitempty"` // jobs the reply cites as [job:<id>], for rendering as cards
+	Session     *ChatSession `json:"session,omitempty"`
+	Partial     bool         `json:"partial,omitempty"`
 }
 
 // ChatMessage represents a single message in a conversation
 type ChatMessage struct {
-	ID          string       `json:"id" bson:"_id,omitempty"`
-	SessionID   string       `json:"session_id" bson:"session_id"`
-	Role        string       `json:"role" bson:"role"` // "user" or "assistant"
-	Content     string       `json:"content" bson:"content"`
-	CVData      *CV          `json:"cv_data,omitempty" bson:"cv_data,omitempty"`
-	Suggestions []Suggestion `json:"suggestions,omitempty" bson:"suggestions,omitempty"`
-	Timestamp   time.Time    `json:"timestamp" bson:"timestamp"`
+	ID          string         `json:"id" bson:"_id,omitempty"`
+	SessionID   string         `json:"session_id" bson:"session_id"`
+	Role        string         `json:"role" bson:"role"` // "user" or "assistant"; ChatSummaryRole only in AI history
+	Content     string         `json:"content" bson:"content"`
+	CVData      *CV            `json:"cv_data,omitempty" bson:"cv_data,omitempty"`
+	Suggestions []Suggestion   `json:"suggestions,omitempty" bson:"suggestions,omitempty"`
+	JobIDs      []string       `json:"job_ids,omitempty" bson:"job_ids,omitempty"` // jobs the reply cites
+	ToolCalls   []ChatToolCall `json:"tool_calls,omitempty" bson:"tool_calls,omitempty"`
+	Partial     bool           `json:"partial,omitempty" bson:"partial,omitempty"` // the reply was cut off before it was complete
+	Timestamp   time.Time      `json:"timestamp" bson:"timestamp"`
 }
 
 // ChatSession represents a conversation session
@@ -39,7 +46,70 @@ type ChatSession struct {
 	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" bson:"updated_at"`
 	MessageCount int       `json:"message_count" bson:"message_count"`
-	Title        string    `json:"title" bson:"title"` // First message or generated title
+	Title        string    `json:"title" bson:"title"`                 // First message or generated title
+	Pinned       bool      `json:"pinned" bson:"pinned,omitempty"`     // listed before other sessions
+	Archived     bool      `json:"archived" bson:"archived,omitempty"` // hidden from the default session list
+	// Summary condenses the oldest SummarizedCount messages, which are no longer sent to the AI
+	Summary         string `json:"-" bson:"summary,omitempty"`
+	SummarizedCount int    `json:"-" bson:"summarized_count,omitempty"`
+}
+
+// ChatSessionUpdate renames, pins or archives a session; nil fields are left unchanged.
+type ChatSessionUpdate struct {
+	Title    *string `json:"title,omitempty"`
+	Pinned   *bool   `json:"pinned,omitempty"`
+	Archived *bool   `json:"archived,omitempty"`
+}
+
+// ChatExchange is what saving one exchange changes on its session.
+type ChatExchange struct {
+	Messages        int // messages added to the session
+	Summary         string
+	SummarizedCount int
+	Title           string // generated title; empty leaves the title as it is
+}
+
+// ChatSearchHit is a message matching a search, with the session it belongs to.
+type ChatSearchHit struct {
+	SessionID    string      `json:"session_id"`
+	SessionTitle string      `json:"session_title"`
+	Message      ChatMessage `json:"message"`
+}
+
+// ChatSummaryRole marks the history entry that carries the session summary rather than a
+// saved message.
+const ChatSummaryRole = "system"
+
+// ChatHistoryPage is a page of a session's messages in chronological order. Offset counts back
+// from the newest message, so offset 0 is the latest page.
+type ChatHistoryPage struct {
+	Messages []ChatMessage `json:"messages"`
+	Total    int           `json:"total"`
+	Limit    int           `json:"limit"`
+	Offset   int           `json:"offset"`
+	HasMore  bool          `json:"has_more"` // older messages remain
+}
+
+// ChatTool is a server-side function the chat assistant can call while answering. Run
+// receives the model's JSON arguments; its result is sent back to the model as JSON.
+type ChatTool struct {
+	Name        string
+	Description string
+	Parameters  string // JSON object describing the arguments, shown to the model
+	Run         func(ctx context.Context, args json.RawMessage) (interface{}, error)
+}
+
+// ChatToolCall records a tool call made while answering a message.
+type ChatToolCall struct {
+	Name      string `json:"name" bson:"name"`
+	Arguments string `json:"arguments,omitempty" bson:"arguments,omitempty"`
+	Error     string `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// ChatAgentReply is the assistant's answer and the tools it called to reach it.
+type ChatAgentReply struct {
+	Reply     string
+	ToolCalls []ChatToolCall
 }
 
 // IChatRepository defines the interface for chat storage
@@ -47,16 +117,39 @@ type IChatRepository interface {
 	CreateSession(ctx context.Context, session *ChatSession) error
 	GetSession(ctx context.Context, sessionID, userID string) (*ChatSession, error)
 	UpdateSession(ctx context.Context, session *ChatSession) error
-	GetUserSessions(ctx context.Context, userID string, limit, offset int) ([]ChatSession, error)
+	// RecordExchange counts an exchange's messages on the session and stores its summary and
+	// title, leaving every other field as it is, and returns the updated session
+	RecordExchange(ctx context.Context, sessionID, userID string, exchange ChatExchange) (*ChatSession, error)
+	// GetUserSessions lists the user's archived or unarchived sessions, pinned ones first
+	GetUserSessions(ctx context.Context, userID string, archived bool, limit, offset int) ([]ChatSession, error)
+	// UpdateSessionSettings applies a rename, pin or archive to a session the user owns
+	UpdateSessionSettings(ctx context.Context, sessionID, userID string, update ChatSessionUpdate) (*ChatSession, error)
 	SaveMessage(ctx context.Context, message *ChatMessage) error
-	GetSessionMessages(ctx context.Context, sessionID, userID string, limit int) ([]ChatMessage, error)
+	// GetSessionMessages returns up to limit messages in chronological order, skipping the
+	// newest offset messages; limit 0 returns them all.
+	GetSessionMessages(ctx context.Context, sessionID, userID string, limit, offset int) ([]ChatMessage, error)
 	DeleteSession(ctx context.Context, sessionID, userID string) error
+	// SearchMessages returns the user's messages matching a full-text query, best matches first
+	SearchMessages(ctx context.Context, userID, query string, limit int) ([]ChatSearchHit, error)
 }
 
 // IAIService defines the interface for AI interactions
 type IAIService interface {
-	GenerateResponse(ctx context.Context, prompt string, history []ChatMessage) (string, error)
-	AnalyzeCV(ctx context.Context, cvText string) (string, error)
-	FindJobs(ctx context.Context, userProfile, query string) (string, error)
+	// RunChatAgent answers a message, calling tools as the model asks for them. With onDelta
+	// set the final answer is passed to it as it is generated.
+	RunChatAgent(ctx context.Context, prompt string, history []ChatMessage, tools []ChatTool, onDelta func(delta string) error) (*ChatAgentReply, error)
+	GenerateTitle(ctx context.Context, firstMessage string) (string, error)
+	// SummarizeConversation folds messages into the summary of the conversation before them.
+	SummarizeConversation(ctx context.Context, summary string, messages []ChatMessage) (string, error)
+	AnalyzeCV(ctx context.Context, cvText string) (string, []Suggestion, error)
 	ImproveCV(ctx context.Context, cv *CV, userQuery string, history []ChatMessage) (string, []Suggestion, error)
+	SuggestForJob(ctx context.Context, cv *CV, job *Job, missingSkills []string) ([]Suggestion, error)
+	GenerateCoverLetter(ctx context.Context, cv *CV, job *Job, opts CoverLetterOptions) (string, error)
+	TailorCV(ctx context.Context, cv *CV, job *Job) (*TailoredCVContent, error)
+
+	// CV processing
+	SuggestCVImprovements(ctx context.Context, rawText string) ([]Suggestion, error)
+	// ExtractCVFields asks the model for the CV's summary, experiences, educations and skills
+	// as a JSON object. The response is untrusted and must be validated by the caller.
+	ExtractCVFields(ctx context.Context, rawText string) (string, error)
 }
@@ -4,7 +4,11 @@ import "context"
 
 type IChatUsecase interface {
     SendMessage(ctx context.Context, req *ChatRequest) (*ChatResponse, error)
-    GetSessionHistory(ctx context.Context, sessionID, userID string) ([]ChatMessage, error)
-    GetUserSessions(ctx context.Context, userID string, limit, offset int) ([]ChatSession, error)
+    SendMessageStream(ctx context.Context, req *ChatRequest, onDelta func(delta string) error) (*ChatResponse, error)
+    GetSessionHistory(ctx context.Context, sessionID, userID string, limit, offset int) (*ChatHistoryPage, error)
+    GetUserSessions(ctx context.Context, userID string, archived bool, limit, offset int) ([]ChatSession, error)
+    UpdateSessionSettings(ctx context.Context, sessionID, userID string, update ChatSessionUpdate) (*ChatSession, error)
     DeleteSession(ctx context.Context, sessionID, userID string) error
+    SearchMessages(ctx context.Context, userID, query string, limit int) ([]ChatSearchHit, error)
+    ExportSession(ctx context.Context, sessionID, userID string, format ExportFormat) (*ExportedDocument, error)
 }
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type CoverLetterTone string
+
+const (
+	ToneProfessional CoverLetterTone = "professional"
+	ToneFriendly     CoverLetterTone = "friendly"
+	ToneEnthusiastic CoverLetterTone = "enthusiastic"
+	ToneFormal       CoverLetterTone = "formal"
+)
+
+type CoverLetterLength string
+
+const (
+	LengthShort  CoverLetterLength = "short"
+	LengthMedium CoverLetterLength = "medium"
+	LengthLong   CoverLetterLength = "long"
+)
+
+// TargetWords is the approximate word count the AI is asked to produce.
+func (l CoverLetterLength) TargetWords() int {
+	switch l {
+	case LengthShort:
+		return 150
+	case LengthLong:
+		return 400
+	default:
+		return 250
+	}
+}
+
+type DraftSource string
+
+const (
+	DraftGenerated DraftSource = "generated"
+	DraftEdited    DraftSource = "edited"
+)
+
+// CoverLetterDraft is one version of a user's cover letter for a job. Every generation or
+// manual edit creates a new draft with the next version number; older drafts are kept.
+type CoverLetterDraft struct {
+	ID        string            `json:"id" bson:"_id,omitempty"`
+	UserID    string            `json:"user_id" bson:"user_id"`
+	JobID     string            `json:"job_id" bson:"job_id"`
+	CVID      string            `json:"cv_id" bson:"cv_id"`
+	Version   int               `json:"version" bson:"version"`
+	Tone      CoverLetterTone   `json:"tone" bson:"tone"`
+	Length    CoverLetterLength `json:"length" bson:"length"`
+	Content   string            `json:"content" bson:"content"`
+	Source    DraftSource       `json:"source" bson:"source"`
+	CreatedAt time.Time         `json:"created_at" bson:"created_at"`
+}
+
+// CoverLetterOptions controls how a cover letter is generated.
+type CoverLetterOptions struct {
+	Tone   CoverLetterTone
+	Length CoverLetterLength
+}
+
+type ICoverLetterRepository interface {
+	Create(ctx context.Context, draft *CoverLetterDraft) error
+	GetLatest(ctx context.Context, userID, jobID string) (*CoverLetterDraft, error)
+	GetVersion(ctx context.Context, userID, jobID string, version int) (*CoverLetterDraft, error)
+	ListVersions(ctx context.Context, userID, jobID string) ([]CoverLetterDraft, error)
+}
+
+type ICoverLetterUsecase interface {
+	Generate(ctx context.Context, userID, jobID, cvID string, opts CoverLetterOptions) (*CoverLetterDraft, error)
+	Edit(ctx context.Context, userID, jobID, content string) (*CoverLetterDraft, error)
+	ListDrafts(ctx context.Context, userID, jobID string) ([]CoverLetterDraft, error)
+	Export(ctx context.Context, userID, jobID string, version int, format ExportFormat) (*ExportedDocument, error)
+}
@@ -4,30 +4,65 @@ import "time"
 
 type JobStatus string
 
+// CVVersionOrigin records how a CV version came to exist.
+type CVVersionOrigin string
+
+const (
+	OriginUpload     CVVersionOrigin = "upload"
+	OriginManualEdit CVVersionOrigin = "manual_edit"
+	OriginTailored   CVVersionOrigin = "tailored"
+	OriginRestore    CVVersionOrigin = "restore"
+	OriginSuggestion CVVersionOrigin = "suggestion" // an accepted AI suggestion
+	OriginReview     CVVersionOrigin = "review"     // the user's review of low-confidence fields
+)
+
 const (
-	StatusPending    JobStatus = "Pending"
-	StatusProcessing JobStatus = "Processing"
-	StatusCompleted  JobStatus = "Completed"
-	StatusFailed     JobStatus = "Failed"
+	StatusPending     JobStatus = "Pending"
+	StatusProcessing  JobStatus = "Processing"
+	StatusCompleted   JobStatus = "Completed"
+	StatusNeedsReview JobStatus = "NeedsReview" // parsed, but some fields are low-confidence and await the user's review
+	StatusFailed      JobStatus = "Failed"
+)
+
+// CVFieldSource records which extraction path produced a parsed CV field.
+type CVFieldSource string
+
+const (
+	FieldSourceHeuristic CVFieldSource = "heuristic"
+	FieldSourceLLM       CVFieldSource = "llm"
 )
 
 // CV is the core domain model for a curriculum vitae and its processing job.
 type CV struct {
-	ID              string       `json:"id" bson:"_id"`
-	UserID          string       `json:"userId" bson:"userId"`
-	FileStorageID   string       `json:"fileStorageId" bson:"fileStorageId"`
-	FileName        string       `json:"fileName" bson:"fileName"`
-	Status          JobStatus    `json:"status" bson:"status"`
-	ProcessingError string       `json:"processingError,omitempty" bson:"processingError,omitempty"`
-	RawText         string       `json:"rawText,omitempty" bson:"rawText,omitempty"`
-	ProfileSummary  string       `json:"profileSummary,omitempty" bson:"profileSummary,omitempty"`
-	Experiences     []Experience `json:"experiences,omitempty" bson:"experiences,omitempty"`
-	Educations      []Education  `json:"educations,omitempty" bson:"educations,omitempty"`
-	Skills          []string     `json:"skills,omitempty" bson:"skills,omitempty"`
-	Suggestions     []Suggestion `json:"suggestions,omitempty" bson:"suggestions,omitempty"`
-	Score           int          `json:"score" bson:"score"`
-	CreatedAt       time.Time    `json:"createdAt" bson:"createdAt"`
-	UpdatedAt       time.Time    `json:"updatedAt" bson:"updatedAt"`
+	ID              string                   `json:"id" bson:"_id"`
+	UserID          string                   `json:"userId" bson:"userId"`
+	FileStorageID   string                   `json:"fileStorageId" bson:"fileStorageId"`
+	FileName        string                   `json:"fileName" bson:"fileName"`
+	Status          JobStatus                `json:"status" bson:"status"`
+	ProcessingError string                   `json:"processingError,omitempty" bson:"processingError,omitempty"`
+	RawText         string                   `json:"rawText,omitempty" bson:"rawText,omitempty"`
+	ProfileSummary  string                   `json:"profileSummary,omitempty" bson:"profileSummary,omitempty"`
+	Experiences     []Experience             `json:"experiences,omitempty" bson:"experiences,omitempty"`
+	Educations      []Education              `json:"educations,omitempty" bson:"educations,omitempty"`
+	Skills          []string                 `json:"skills,omitempty" bson:"skills,omitempty"`
+	Contact         CVContact                `json:"contact" bson:"contact"`
+	Languages       []Language               `json:"languages,omitempty" bson:"languages,omitempty"`
+	Certifications  []Certification          `json:"certifications,omitempty" bson:"certifications,omitempty"`
+	Projects        []Project                `json:"projects,omitempty" bson:"projects,omitempty"`
+	FieldConfidence map[string]float64       `json:"fieldConfidence,omitempty" bson:"fieldConfidence,omitempty"` // parser confidence (0-1) keyed by field, e.g. "contact.email"
+	ReviewFields    []string                 `json:"reviewFields,omitempty" bson:"reviewFields,omitempty"`       // fields awaiting review while Status is NeedsReview
+	FieldSources    map[string]CVFieldSource `json:"fieldSources,omitempty" bson:"fieldSources,omitempty"`       // extraction path that produced each core field
+	Suggestions     []Suggestion             `json:"suggestions,omitempty" bson:"suggestions,omitempty"`
+	Score           int                      `json:"score" bson:"score"`
+	LineageID       string                   `json:"lineageId,omitempty" bson:"lineageId,omitempty"` // ID of the first version; shared by all versions of a CV
+	Version         int                      `json:"version,omitempty" bson:"version,omitempty"`     // 1-based position within the lineage
+	Origin          CVVersionOrigin          `json:"origin,omitempty" bson:"origin,omitempty"`
+	IsPrimary       bool                     `json:"isPrimary" bson:"isPrimary"`
+	SourceCVID      string                   `json:"sourceCvId,omitempty" bson:"sourceCvId,omitempty"`   // CV this one was derived from
+	TargetJobID     string                   `json:"targetJobId,omitempty" bson:"targetJobId,omitempty"` // job a tailored CV was written for
+	Changes         []CVFieldChange          `json:"changes,omitempty" bson:"changes,omitempty"`         // diff against the previous version
+	CreatedAt       time.Time                `json:"createdAt" bson:"createdAt"`
+	UpdatedAt       time.Time                `json:"updatedAt" bson:"updatedAt"`
 }
 
 type Experience struct {
@@ -48,22 +83,176 @@ type Education struct {
 	GraduationDate time.Time `json:"graduationDate" bson:"graduationDate"`
 }
 
+// CVContact holds the contact details found in a CV, usually in its header.
+type CVContact struct {
+	Name      string `json:"name,omitempty" bson:"name,omitempty"`
+	Email     string `json:"email,omitempty" bson:"email,omitempty"`
+	Phone     string `json:"phone,omitempty" bson:"phone,omitempty"`
+	Location  string `json:"location,omitempty" bson:"location,omitempty"`
+	LinkedIn  string `json:"linkedin,omitempty" bson:"linkedin,omitempty"`
+	GitHub    string `json:"github,omitempty" bson:"github,omitempty"`
+	Portfolio string `json:"portfolio,omitempty" bson:"portfolio,omitempty"`
+}
+
+// Language is a spoken language. Proficiency is normalised to native, fluent, professional,
+// intermediate or basic, or kept as a CEFR level (A1-C2); empty when the CV does not say.
+type Language struct {
+	Name        string `json:"name" bson:"name"`
+	Proficiency string `json:"proficiency,omitempty" bson:"proficiency,omitempty"`
+}
+
+type Certification struct {
+	ID     string     `json:"id" bson:"id"`
+	Name   string     `json:"name" bson:"name"`
+	Issuer string     `json:"issuer,omitempty" bson:"issuer,omitempty"`
+	Date   *time.Time `json:"date,omitempty" bson:"date,omitempty"`
+}
+
+type Project struct {
+	ID           string   `json:"id" bson:"id"`
+	Name         string   `json:"name" bson:"name"`
+	Description  string   `json:"description,omitempty" bson:"description,omitempty"`
+	URL          string   `json:"url,omitempty" bson:"url,omitempty"`
+	Technologies []string `json:"technologies,omitempty" bson:"technologies,omitempty"`
+}
+
+// Suggestion types returned by the AI; each has a severity weight in the CV score.
+const (
+	SuggestionQuantification  = "quantification"
+	SuggestionWeakActionVerbs = "weak_action_verbs"
+	SuggestionMissingKeywords = "missing_keywords"
+)
+
+// SuggestionTypes lists the types the AI may use for CV improvement suggestions.
+var SuggestionTypes = []string{SuggestionQuantification, SuggestionWeakActionVerbs, SuggestionMissingKeywords}
+
+// CV sections a suggestion can target.
+const (
+	SectionProfileSummary = "profileSummary"
+	SectionExperience     = "experience"
+	SectionEducation      = "education"
+	SectionSkills         = "skills"
+)
+
+// SuggestionSections lists the sections a suggestion can target.
+var SuggestionSections = []string{SectionProfileSummary, SectionExperience, SectionEducation, SectionSkills}
+
+// Suggestion is a proposed change to a CV. AI suggestions name the text they replace so they
+// can be reviewed and applied; older and job-tailoring suggestions only carry Content.
 type Suggestion struct {
-	ID      string `json:"id" bson:"id"`
-	Type    string `json:"type" bson:"type"`
-	Content string `json:"content" bson:"content"`
-	Applied bool   `json:"applied" bson:"applied"`
+	ID          string `json:"id" bson:"id"`
+	Type        string `json:"type" bson:"type"`
+	Content     string `json:"content" bson:"content"`                             // one-line summary of the change
+	Section     string `json:"section,omitempty" bson:"section,omitempty"`         // one of SuggestionSections
+	TargetID    string `json:"targetId,omitempty" bson:"targetId,omitempty"`       // experience ID when Section is experience
+	Original    string `json:"original,omitempty" bson:"original,omitempty"`       // text quoted from the CV; empty for pure additions
+	Replacement string `json:"replacement,omitempty" bson:"replacement,omitempty"` // proposed new text
+	Rationale   string `json:"rationale,omitempty" bson:"rationale,omitempty"`
+	Applied     bool   `json:"applied" bson:"applied"`
+	Rejected    bool   `json:"rejected,omitempty" bson:"rejected,omitempty"`
+}
+
+// Lineage returns the version chain this CV belongs to. Records created before versioning
+// existed have no LineageID and form a chain of their own.
+func (cv *CV) Lineage() string {
+	if cv.LineageID != "" {
+		return cv.LineageID
+	}
+	return cv.ID
+}
+
+// IsTailored reports whether the CV is a copy rewritten for one job. Tailored copies are kept
+// in their source's history but never become the CV's latest version.
+func (cv *CV) IsTailored() bool {
+	return cv.Origin == OriginTailored
+}
+
+// IsParsed reports whether parsing has finished, including CVs still awaiting review.
+func (cv *CV) IsParsed() bool {
+	return cv.Status == StatusCompleted || cv.Status == StatusNeedsReview
+}
+
+// VersionNumber returns the CV's version, treating unversioned records as version 1.
+func (cv *CV) VersionNumber() int {
+	if cv.Version > 0 {
+		return cv.Version
+	}
+	return 1
+}
+
+// CVSectionsUpdate is a manual edit of parsed CV sections. Nil fields are left unchanged.
+type CVSectionsUpdate struct {
+	ProfileSummary *string          `json:"profileSummary,omitempty"`
+	Experiences    *[]Experience    `json:"experiences,omitempty"`
+	Educations     *[]Education     `json:"educations,omitempty"`
+	Skills         *[]string        `json:"skills,omitempty"`
+	Contact        *CVContact       `json:"contact,omitempty"`
+	Languages      *[]Language      `json:"languages,omitempty"`
+	Certifications *[]Certification `json:"certifications,omitempty"`
+	Projects       *[]Project       `json:"projects,omitempty"`
+}
+
+// CVVersionSummary is one entry in a CV's version history.
+type CVVersionSummary struct {
+	ID          string          `json:"id"`
+	Version     int             `json:"version"`
+	Origin      CVVersionOrigin `json:"origin,omitempty"`
+	SourceCVID  string          `json:"sourceCvId,omitempty"`
+	TargetJobID string          `json:"targetJobId,omitempty"`
+	Changes     []CVFieldChange `json:"changes"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// CVFieldChange is one field-level difference between two CVs.
+// Field uses a path such as "profileSummary" or "experiences[exp-1a2b].description".
+type CVFieldChange struct {
+	Field  string `json:"field" bson:"field"`
+	Before string `json:"before" bson:"before"`
+	After  string `json:"after" bson:"after"`
+}
+
+// TailoredCVContent is the AI rewrite of a CV for a job. Only free-text fields are rewritten;
+// experience descriptions are keyed by Experience.ID.
+type TailoredCVContent struct {
+	ProfileSummary         string            `json:"profileSummary"`
+	ExperienceDescriptions map[string]string `json:"experienceDescriptions"`
 }
 
 // CVRepository defines the interface for CV data persistence.
 type CVRepository interface {
+	// Create stores a CV; it returns ErrCVVersionConflict if the lineage already has its version
 	Create(cv *CV) error
 	GetByID(id string) (*CV, error)
+	// UpdateStatus sets the status and replaces the processing error; an empty or missing
+	// procError clears it
 	UpdateStatus(id string, status JobStatus, procError ...string) error
 	UpdateWithResults(id string, results *CV) error
+	ListByUser(userID string) ([]CV, error)
+	ListByStatus(statuses ...JobStatus) ([]CV, error)
+	ListVersions(lineageID string) ([]CV, error)
+	// SetPrimary marks every version of the lineage except tailored copies as primary
+	SetPrimary(userID, lineageID string) error
+	// UpdateSuggestions replaces a CV's suggestions without creating a version
+	UpdateSuggestions(id string, suggestions []Suggestion) error
+}
+
+// DeadLetteredJob is a CV processing job the queue gave up on after exhausting its retries.
+// It stays parked until an admin requeues it.
+type DeadLetteredJob struct {
+	JobID    string    `json:"jobId"`
+	Attempts int       `json:"attempts"`
+	Reason   string    `json:"reason"`
+	FailedAt time.Time `json:"failedAt"`
 }
 
-// AIService defines the interface for the AI team's service.
-type AIService interface {
-	AnalyzeCV(rawText string) ([]Suggestion, error)
+// CVWorkerStats is a snapshot of a CV worker pool, served by its health and metrics endpoints.
+type CVWorkerStats struct {
+	Running      bool      `json:"running"`
+	Concurrency  int       `json:"concurrency"`
+	InFlight     int64     `json:"inFlight"`
+	Succeeded    int64     `json:"succeeded"`
+	Failed       int64     `json:"failed"` // permanent failures, e.g. unreadable files
+	Retried      int64     `json:"retried"`
+	DeadLettered int64     `json:"deadLettered"`
+	StartedAt    time.Time `json:"startedAt"`
 }
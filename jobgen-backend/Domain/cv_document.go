@@ -0,0 +1,60 @@
+package domain
+
+import "strings"
+
+// CVBlockKind classifies a block of extracted CV text
+type CVBlockKind string
+
+const (
+	BlockHeading   CVBlockKind = "heading"
+	BlockParagraph CVBlockKind = "paragraph"
+	BlockBullet    CVBlockKind = "bullet"
+)
+
+// CVTextBlock is one unit of extracted text, in reading order. Paragraph lines are joined
+// with "\n"; wrapped bullet lines are joined into a single line.
+type CVTextBlock struct {
+	Kind     CVBlockKind `json:"kind"`
+	Text     string      `json:"text"`
+	Page     int         `json:"page,omitempty"`   // 1-based; 0 when the format has no pages
+	Column   int         `json:"column,omitempty"` // 0-based column index on the page
+	FontSize float64     `json:"fontSize,omitempty"`
+}
+
+// CVDocument is the structured result of extracting text from a CV file.
+// HasLayout is true when blocks were derived from glyph positions (headings, columns),
+// false when they were inferred from plain text.
+type CVDocument struct {
+	Format    string        `json:"format"`
+	HasLayout bool          `json:"hasLayout"`
+	Blocks    []CVTextBlock `json:"blocks"`
+}
+
+// PlainText renders the blocks as text: headings and paragraphs are separated by a blank
+// line, and bullets follow the preceding block directly with a "• " prefix.
+func (d *CVDocument) PlainText() string {
+	var b strings.Builder
+	for i, blk := range d.Blocks {
+		if i > 0 {
+			b.WriteByte('\n')
+			if blk.Kind != BlockBullet {
+				b.WriteByte('\n')
+			}
+		}
+		if blk.Kind == BlockBullet {
+			b.WriteString("• ")
+		}
+		b.WriteString(blk.Text)
+	}
+	return b.String()
+}
+
+// HasHeadings reports whether any block was recognised as a heading
+func (d *CVDocument) HasHeadings() bool {
+	for _, blk := range d.Blocks {
+		if blk.Kind == BlockHeading {
+			return true
+		}
+	}
+	return false
+}
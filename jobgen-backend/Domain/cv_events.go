@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// CVJobEventType tells a status transition apart from a processing stage.
+type CVJobEventType string
+
+const (
+	CVEventStatus CVJobEventType = "status"
+	CVEventStage  CVJobEventType = "stage"
+)
+
+// CVJobStage is an intermediate step of CV processing.
+type CVJobStage string
+
+const (
+	StageTextExtracted     CVJobStage = "text_extracted"
+	StageSectionsParsed    CVJobStage = "sections_parsed"
+	StageAISuggestionsDone CVJobStage = "ai_suggestions_done"
+)
+
+// CVJobEvent is one step in the life of a CV processing job, streamed to clients while the
+// job runs.
+type CVJobEvent struct {
+	JobID   string         `json:"jobId"`
+	Type    CVJobEventType `json:"type"`
+	Status  JobStatus      `json:"status,omitempty"`
+	Stage   CVJobStage     `json:"stage,omitempty"`
+	Message string         `json:"message,omitempty"` // failure reason, or a note such as "ai_unavailable"
+	At      time.Time      `json:"at"`
+}
+
+// Terminal reports whether the event ends the job: it completed, awaits review or failed.
+func (e CVJobEvent) Terminal() bool {
+	return e.Type == CVEventStatus && e.Status.Finished()
+}
+
+// Finished reports whether a job in this status will not be processed further.
+func (s JobStatus) Finished() bool {
+	return s == StatusCompleted || s == StatusNeedsReview || s == StatusFailed
+}
+
+// CVJobNotifier relays a CV job's progress to subscribed clients and, once the job has
+// finished, to the owner's webhooks.
+type CVJobNotifier interface {
+	StatusChanged(jobID string, status JobStatus, message string)
+	StageCompleted(jobID string, stage CVJobStage, message string)
+}
+
+// CVWebhook is a URL that receives a signed callback whenever one of the user's CV jobs
+// finishes.
+type CVWebhook struct {
+	ID                string     `json:"id" bson:"_id"`
+	UserID            string     `json:"userId" bson:"userId"`
+	URL               string     `json:"url" bson:"url"`
+	Secret            string     `json:"-" bson:"secret"` // HMAC key; only shown once, on registration
+	CreatedAt         time.Time  `json:"createdAt" bson:"createdAt"`
+	LastDeliveryAt    *time.Time `json:"lastDeliveryAt,omitempty" bson:"lastDeliveryAt,omitempty"`
+	LastDeliveryError string     `json:"lastDeliveryError,omitempty" bson:"lastDeliveryError,omitempty"`
+}
+
+// CVWebhookPayload is the JSON body of a webhook callback.
+type CVWebhookPayload struct {
+	Event           string    `json:"event"` // always "cv.job.finished"
+	JobID           string    `json:"jobId"`
+	Status          JobStatus `json:"status"`
+	Score           int       `json:"score"`
+	ProcessingError string    `json:"processingError,omitempty"`
+	OccurredAt      time.Time `json:"occurredAt"`
+}
+
+type ICVWebhookRepository interface {
+	Create(ctx context.Context, hook *CVWebhook) error
+	ListByUser(ctx context.Context, userID string) ([]CVWebhook, error)
+	Delete(ctx context.Context, userID, id string) error
+	// RecordDelivery stores the outcome of the latest callback; deliveryErr is empty on success
+	RecordDelivery(ctx context.Context, id string, at time.Time, deliveryErr string) error
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// ReviewConfidenceThreshold is the parser confidence below which a field is sent for review
+const ReviewConfidenceThreshold = 0.6
+
+// LowConfidenceFields lists the fields the user should confirm or correct: fields the parser
+// extracted with a confidence below ReviewConfidenceThreshold. Fields it did not find have no
+// confidence and are not flagged, since there is nothing to confirm.
+func (cv *CV) LowConfidenceFields() []string {
+	var fields []string
+	for f, c := range cv.FieldConfidence {
+		if c < ReviewConfidenceThreshold {
+			fields = append(fields, f)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// CVReviewField is a field awaiting review with its parsed value
+type CVReviewField struct {
+	Field      string      `json:"field"`
+	Confidence float64     `json:"confidence"`
+	Value      interface{} `json:"value"`
+}
+
+type CVReview struct {
+	CVID   string          `json:"cvId"`
+	Status JobStatus       `json:"status"`
+	Fields []CVReviewField `json:"fields"`
+}
+
+type CVReviewAction string
+
+const (
+	ReviewConfirm CVReviewAction = "confirm"
+	ReviewCorrect CVReviewAction = "correct"
+)
+
+// CVFieldCorrection is the user's verdict on one field. Value holds the corrected value in
+// the field's JSON shape (a string for contact fields, a list for sections) and is only
+// used with ReviewCorrect.
+type CVFieldCorrection struct {
+	Field  string          `json:"field" binding:"required"`
+	Action CVReviewAction  `json:"action" binding:"required"`
+	Value  json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+// CVParseLabel is a reviewed field kept as a labelled example for evaluating the parser.
+// ParsedValue and LabelValue are JSON; they are equal when the user confirmed the field.
+type CVParseLabel struct {
+	ID          string    `json:"id" bson:"_id,omitempty"`
+	CVID        string    `json:"cvId" bson:"cvId"`
+	UserID      string    `json:"userId" bson:"userId"`
+	Field       string    `json:"field" bson:"field"`
+	Confidence  float64   `json:"confidence" bson:"confidence"`
+	ParsedValue string    `json:"parsedValue" bson:"parsedValue"`
+	LabelValue  string    `json:"labelValue" bson:"labelValue"`
+	Confirmed   bool      `json:"confirmed" bson:"confirmed"`
+	RawText     string    `json:"rawText,omitempty" bson:"rawText,omitempty"`
+	CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+type ICVParseLabelRepository interface {
+	CreateMany(ctx context.Context, labels []CVParseLabel) error
+	// List returns labels, newest first; an empty field returns all fields
+	List(ctx context.Context, field string, limit int) ([]CVParseLabel, error)
+}
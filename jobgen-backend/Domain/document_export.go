@@ -0,0 +1,23 @@
+package domain
+
+type ExportFormat string
+
+const (
+	ExportPlainText ExportFormat = "txt"
+	ExportMarkdown  ExportFormat = "md"
+	ExportPDF       ExportFormat = "pdf"
+	ExportJSON      ExportFormat = "json" // structured data; not rendered by IDocumentExporter
+)
+
+// ExportedDocument is a rendered file ready to be sent to the client.
+type ExportedDocument struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// IDocumentExporter renders a titled text document in one of the supported formats.
+// Content is treated as Markdown; the plain text and PDF renderers strip the markup.
+type IDocumentExporter interface {
+	Export(baseName, title, content string, format ExportFormat) (*ExportedDocument, error)
+}
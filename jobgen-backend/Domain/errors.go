@@ -28,6 +28,7 @@ var (
 	// System errors
 	ErrInternal          = errors.New("internal server error")
 	ErrServiceUnavailable = errors.New("service temporarily unavailable")
+	ErrAIQuotaExceeded   = errors.New("ai usage quota exceeded")
 	
 	// OTP errors
 	ErrOTPExpired        = errors.New("OTP expired")
@@ -51,6 +52,14 @@ var (
 	ErrInvalidJobData = errors.New("invalid job data")
 	ErrNotFound       = errors.New("resource not found")
 
+	// CV errors
+	ErrCVNotReady          = errors.New("cv has not finished processing")
+	ErrUnsupportedCVFormat = errors.New("unsupported cv file format")
+	ErrCVVersionConflict   = errors.New("cv version already exists")
+
+	// Cover letter errors
+	ErrDraftVersionConflict = errors.New("cover letter version already exists")
+
 	// Scraping errors
 	ErrScrapingFailed     = errors.New("scraping failed")
 	ErrRateLimitExceeded  = errors.New("rate limit exceeded")
@@ -59,4 +68,5 @@ var (
 	// Matching errors
 	ErrNoMatchingJobs     = errors.New("no matching jobs found")
 	ErrInvalidPreferences = errors.New("invalid user preferences")
+	ErrInvalidFeedback    = errors.New("invalid feedback type")
 )
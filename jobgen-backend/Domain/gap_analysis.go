@@ -0,0 +1,17 @@
+package domain
+
+// JobGapAnalysis describes how well a CV covers the requirements of a single job.
+type JobGapAnalysis struct {
+	JobID                   string       `json:"job_id"`
+	CVID                    string       `json:"cv_id"`
+	MatchingSkills          []string     `json:"matching_skills"`
+	MissingSkills           []string     `json:"missing_skills"`
+	RequiredExperienceYears int          `json:"required_experience_years"`
+	CVExperienceYears       float64      `json:"cv_experience_years"`
+	ExperienceGapYears      float64      `json:"experience_gap_years"` // 0 when the CV meets the requirement
+	SkillScore              float64      `json:"skill_score"`
+	ExperienceScore         float64      `json:"experience_score"`
+	FitScore                float64      `json:"fit_score"`
+	Suggestions             []Suggestion `json:"suggestions,omitempty"`
+	SuggestionsError        string       `json:"suggestions_error,omitempty"` // set when AI suggestions were requested but unavailable or over quota
+}
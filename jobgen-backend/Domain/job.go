@@ -102,6 +102,9 @@ type IJobMatchingService interface {
 	CalculateMatchScore(job Job, preferences UserJobPreferences) float64
 	GetMatchedJobs(ctx context.Context, userID string, limit int, offset int) ([]Job, error)
 	UpdateUserPreferences(ctx context.Context, userID string, preferences UserJobPreferences) error
+	RecordFeedback(ctx context.Context, userID string, feedback *JobFeedback) error
+	GetMatchPrecisionReport(ctx context.Context) (*MatchPrecisionReport, error)
+	AnalyzeGap(job Job, cv CV) JobGapAnalysis
 }
 
 // Use case interfaces
@@ -120,6 +123,13 @@ type IJobUsecase interface {
 	GetJobStats(ctx context.Context) (map[string]interface{}, error)
 	GetTrendingJobs(ctx context.Context, limit int) ([]Job, error)
 	SearchJobsBySkills(ctx context.Context, skills []string, limit int) ([]Job, error)
+
+	// Match feedback
+	SubmitJobFeedback(ctx context.Context, userID, jobID string, feedbackType FeedbackType, reason string) (*JobFeedback, error)
+	GetMatchPrecisionReport(ctx context.Context) (*MatchPrecisionReport, error)
+
+	// CV fit
+	AnalyzeJobGap(ctx context.Context, userID, jobID, cvID string, withSuggestions bool) (*JobGapAnalysis, error)
 }
 
 // Skill extraction interface
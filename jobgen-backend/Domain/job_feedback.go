@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type FeedbackType string
+
+const (
+	FeedbackThumbsUp      FeedbackType = "thumbs_up"
+	FeedbackThumbsDown    FeedbackType = "thumbs_down"
+	FeedbackNotInterested FeedbackType = "not_interested"
+)
+
+// JobFeedback is a user's verdict on a single matched job. One document per (user, job) pair;
+// submitting again overwrites the previous verdict.
+type JobFeedback struct {
+	ID         string       `json:"id" bson:"_id,omitempty"`
+	UserID     string       `json:"user_id" bson:"user_id"`
+	JobID      string       `json:"job_id" bson:"job_id"`
+	Type       FeedbackType `json:"type" bson:"type"`
+	Reason     string       `json:"reason,omitempty" bson:"reason,omitempty"`
+	MatchScore float64      `json:"match_score" bson:"match_score"` // score shown to the user when feedback was given
+	// Adjustment is the nudge this verdict applied to the user's weights, undone if it changes
+	Adjustment *MatchWeightAdjustment `json:"-" bson:"adjustment,omitempty"`
+	CreatedAt  time.Time              `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at" bson:"updated_at"`
+}
+
+// MatchWeightAdjustment is the change one verdict made to each component weight, before the
+// weights were renormalized.
+type MatchWeightAdjustment struct {
+	Skills     float64 `bson:"skills"`
+	Experience float64 `bson:"experience"`
+	Location   float64 `bson:"location"`
+}
+
+// IsPositive reports whether the feedback counts as a good match.
+func (f FeedbackType) IsPositive() bool {
+	return f == FeedbackThumbsUp
+}
+
+// MatchWeights holds the per-user component weights used by the matcher. They always sum to 1.
+type MatchWeights struct {
+	UserID     string    `json:"user_id" bson:"_id"`
+	Skills     float64   `json:"skills" bson:"skills"`
+	Experience float64   `json:"experience" bson:"experience"`
+	Location   float64   `json:"location" bson:"location"`
+	Samples    int       `json:"samples" bson:"samples"` // number of feedback events folded in
+	UpdatedAt  time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// DefaultMatchWeights returns the global weighting used before a user has given any feedback.
+func DefaultMatchWeights() MatchWeights {
+	return MatchWeights{Skills: 0.7, Experience: 0.2, Location: 0.1}
+}
+
+// MatchScoreBand summarizes feedback for matches whose score fell into [MinScore, MaxScore).
+type MatchScoreBand struct {
+	MinScore  float64 `json:"min_score" bson:"min_score"`
+	MaxScore  float64 `json:"max_score" bson:"max_score"`
+	Positive  int64   `json:"positive" bson:"positive"`
+	Negative  int64   `json:"negative" bson:"negative"`
+	Dismissed int64   `json:"dismissed" bson:"dismissed"`
+	Total     int64   `json:"total" bson:"total"`
+	Precision float64 `json:"precision"` // positive / total
+}
+
+// MatchPrecisionReport is the admin view of how well match scores agree with user feedback.
+type MatchPrecisionReport struct {
+	Bands         []MatchScoreBand `json:"bands"`
+	TotalFeedback int64            `json:"total_feedback"`
+	Precision     float64          `json:"precision"`
+	GeneratedAt   time.Time        `json:"generated_at"`
+}
+
+// IJobFeedbackRepository persists match feedback and the learned per-user weights.
+type IJobFeedbackRepository interface {
+	Upsert(ctx context.Context, feedback *JobFeedback) error
+	// Get returns the user's verdict on a job, or ErrNotFound
+	Get(ctx context.Context, userID, jobID string) (*JobFeedback, error)
+	GetDismissedJobIDs(ctx context.Context, userID string) ([]string, error)
+	GetWeights(ctx context.Context, userID string) (*MatchWeights, error)
+	SaveWeights(ctx context.Context, weights *MatchWeights) error
+	GetScoreBands(ctx context.Context, boundaries []float64) ([]MatchScoreBand, error)
+}
@@ -0,0 +1,284 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	domain "jobgen-backend/Domain"
+)
+
+// maxAgentToolCalls bounds the tool calls made for one message; after that the model must answer.
+const maxAgentToolCalls = 4
+
+// maxToolResultChars keeps a single tool result from filling the model's context.
+const maxToolResultChars = 6000
+
+const emptyChatReply = "I'm sorry, I didn't get a response. Please try again."
+
+const chatAgentInstructions = `You are JobGen, an AI career assistant helping African professionals find remote tech jobs and improve their CVs.
+
+You can call server-side tools to look up real data. To call a tool, respond with ONLY a JSON object and nothing else:
+{"tool": "<tool name>", "arguments": {<arguments>}}
+The tool's output is then sent to you, and you may call another tool or answer.
+
+When you answer, write plain text for the user, not JSON. Only mention jobs that a tool returned and cite each one as [job:<id>] with its exact id. Never invent job listings, companies or links; if the tools find no jobs, say so.`
+
+// agentToolCall is the JSON the model responds with to call a tool.
+type agentToolCall struct {
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// RunChatAgent answers the message, running the tools the model asks for and sending their
+// results back until it answers in plain text. Tool failures are reported to the model
+// rather than ending the conversation.
+func (s *aiService) RunChatAgent(ctx context.Context, prompt string, history []domain.ChatMessage, tools []domain.ChatTool, onDelta func(delta string) error) (*domain.ChatAgentReply, error) {
+	req := chatRequest(prompt, history)
+	req.System = strings.TrimSpace(chatAgentSystemPrompt(tools) + "\n\n" + req.System)
+	reply := &domain.ChatAgentReply{}
+
+	for calls := 0; ; calls++ {
+		if calls == maxAgentToolCalls {
+			req.Messages = append(req.Messages, LLMMessage{Role: "user", Content: "Answer the user now in plain text, without calling any more tools."})
+		}
+		text, relayed, err := s.agentStep(ctx, req, onDelta)
+		if err != nil {
+			return nil, err
+		}
+
+		call, isCall := parseAgentToolCall(text)
+		if isCall && calls == maxAgentToolCalls {
+			log.Printf("🟠 AI chat agent kept calling tools after %d calls", calls)
+			text, isCall = "I couldn't finish looking that up. Please try asking in a different way.", false
+		}
+		if !isCall {
+			if strings.TrimSpace(text) == "" {
+				text = emptyChatReply
+			}
+			if onDelta != nil && !relayed {
+				if err := onDelta(text); err != nil {
+					return nil, err
+				}
+			}
+			reply.Reply = text
+			return reply, nil
+		}
+
+		result, record := runChatTool(ctx, tools, call)
+		reply.ToolCalls = append(reply.ToolCalls, record)
+		req.Messages = append(req.Messages,
+			LLMMessage{Role: "assistant", Content: text},
+			LLMMessage{Role: "user", Content: fmt.Sprintf("Tool %s returned:\n%s", call.Tool, result)},
+		)
+	}
+}
+
+// agentStep asks the model for its next step. When streaming, text is relayed as it arrives
+// unless the response opens like JSON, which may be a tool call and is held back; relayed
+// reports whether the text has already been sent.
+func (s *aiService) agentStep(ctx context.Context, req LLMRequest, onDelta func(delta string) error) (text string, relayed bool, err error) {
+	if onDelta == nil {
+		text, err = s.client.GenerateText(ctx, "chat", req)
+		return text, false, err
+	}
+
+	var pending strings.Builder
+	decided, holding := false, false
+	resp, err := s.client.GenerateStream(ctx, "chat", req, func(delta string) error {
+		if decided {
+			if holding {
+				return nil
+			}
+			return onDelta(delta)
+		}
+		pending.WriteString(delta)
+		start := strings.TrimSpace(pending.String())
+		if start == "" {
+			return nil
+		}
+		decided, holding = true, start[0] == '{' || start[0] == '`'
+		if holding {
+			return nil
+		}
+		return onDelta(pending.String())
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return resp.Text, decided && !holding, nil
+}
+
+// parseAgentToolCall recognises a response that is a tool call.
+func parseAgentToolCall(text string) (agentToolCall, bool) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "```") {
+		return agentToolCall{}, false
+	}
+	var call agentToolCall
+	if err := json.Unmarshal([]byte(extractJSONObject(trimmed)), &call); err != nil || strings.TrimSpace(call.Tool) == "" {
+		return agentToolCall{}, false
+	}
+	call.Tool = strings.TrimSpace(call.Tool)
+	if len(call.Arguments) == 0 || string(call.Arguments) == "null" {
+		call.Arguments = json.RawMessage("{}")
+	}
+	return call, true
+}
+
+// runChatTool runs one tool call and returns its result for the model, which is an error
+// object when the tool is unknown or fails.
+func runChatTool(ctx context.Context, tools []domain.ChatTool, call agentToolCall) (string, domain.ChatToolCall) {
+	record := domain.ChatToolCall{Name: call.Tool, Arguments: string(call.Arguments)}
+	fail := func(err error) (string, domain.ChatToolCall) {
+		record.Error = err.Error()
+		data, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return string(data), record
+	}
+
+	var tool *domain.ChatTool
+	for i := range tools {
+		if tools[i].Name == call.Tool {
+			tool = &tools[i]
+			break
+		}
+	}
+	if tool == nil {
+		return fail(fmt.Errorf("unknown tool %q", call.Tool))
+	}
+
+	result, err := tool.Run(ctx, call.Arguments)
+	if err != nil {
+		log.Printf("🟠 AI chat tool %s failed: %v", call.Tool, err)
+		return fail(err)
+	}
+	text, err := fitToolResult(result, maxToolResultChars)
+	if err != nil {
+		return fail(err)
+	}
+	return text, record
+}
+
+// fitToolResult encodes a tool result in at most limit bytes. A longer result is shortened
+// value by value, cutting long strings and then the last items of its longest list, so the
+// model always gets valid JSON.
+func fitToolResult(result interface{}, limit int) (string, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	if len(data) <= limit {
+		return string(data), nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", err
+	}
+	for maxRunes := 2000; maxRunes >= 100; maxRunes /= 2 {
+		value = capStrings(value, maxRunes)
+		if data, err = json.Marshal(value); err == nil && len(data) <= limit {
+			return string(data), nil
+		}
+	}
+	for n := longestList(value); n > 0; n = longestList(value) {
+		value, _ = dropLastItem(value, n)
+		if data, err = json.Marshal(value); err == nil && len(data) <= limit {
+			return string(data), nil
+		}
+	}
+	return `{"error":"the result is too large to show"}`, nil
+}
+
+// capStrings cuts every string in a decoded JSON value to at most maxRunes runes.
+func capStrings(v interface{}, maxRunes int) interface{} {
+	switch v := v.(type) {
+	case string:
+		if runes := []rune(v); len(runes) > maxRunes {
+			return string(runes[:maxRunes]) + "…"
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = capStrings(v[i], maxRunes)
+		}
+		return v
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = capStrings(v[k], maxRunes)
+		}
+		return v
+	}
+	return v
+}
+
+// longestList returns the length of the longest list in a decoded JSON value.
+func longestList(v interface{}) int {
+	longest := 0
+	switch v := v.(type) {
+	case []interface{}:
+		longest = len(v)
+		for _, item := range v {
+			longest = max(longest, longestList(item))
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			longest = max(longest, longestList(item))
+		}
+	}
+	return longest
+}
+
+// dropLastItem removes the last item of the first list of length n in a decoded JSON value
+// and reports whether it found one.
+func dropLastItem(v interface{}, n int) (interface{}, bool) {
+	switch v := v.(type) {
+	case []interface{}:
+		if len(v) == n {
+			return v[:n-1], true
+		}
+		for i := range v {
+			var dropped bool
+			if v[i], dropped = dropLastItem(v[i], n); dropped {
+				return v, true
+			}
+		}
+	case map[string]interface{}:
+		for k := range v {
+			var dropped bool
+			if v[k], dropped = dropLastItem(v[k], n); dropped {
+				return v, true
+			}
+		}
+	}
+	return v, false
+}
+
+func chatAgentSystemPrompt(tools []domain.ChatTool) string {
+	if len(tools) == 0 {
+		return "You are JobGen, an AI career assistant helping African professionals find remote tech jobs and improve their CVs. You cannot look up job listings right now, so never invent them."
+	}
+	var sb strings.Builder
+	sb.WriteString(chatAgentInstructions)
+	sb.WriteString("\n\nTools:")
+	for _, tool := range tools {
+		fmt.Fprintf(&sb, "\n- %s: %s Arguments: %s", tool.Name, tool.Description, tool.Parameters)
+	}
+	return sb.String()
+}
+
+// chatRequest continues a conversation with the user's new message. The summary of earlier
+// messages, if any, goes in the system prompt.
+func chatRequest(prompt string, history []domain.ChatMessage) LLMRequest {
+	req := LLMRequest{}
+	for _, msg := range history {
+		if msg.Role == domain.ChatSummaryRole {
+			req.System = "Summary of the earlier conversation:\n" + msg.Content
+			continue
+		}
+		req.Messages = append(req.Messages, LLMMessage{Role: msg.Role, Content: msg.Content})
+	}
+	req.Messages = append(req.Messages, LLMMessage{Role: "user", Content: prompt})
+	return req
+}
@@ -0,0 +1,42 @@
+package infrastructure
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	domain "jobgen-backend/Domain"
+)
+
+// ParseAIQuota reads a quota such as "daily_requests=200,monthly_tokens=5000000". Limits that
+// are not named are unlimited.
+func ParseAIQuota(spec string) (domain.AIQuota, error) {
+	var quota domain.AIQuota
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return domain.AIQuota{}, fmt.Errorf("quota %q must look like limit=number", entry)
+		}
+		limit, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || limit < 0 {
+			return domain.AIQuota{}, fmt.Errorf("quota %s: invalid number %q", name, value)
+		}
+		switch strings.TrimSpace(name) {
+		case "daily_requests":
+			quota.DailyRequests = limit
+		case "daily_tokens":
+			quota.DailyTokens = limit
+		case "monthly_requests":
+			quota.MonthlyRequests = limit
+		case "monthly_tokens":
+			quota.MonthlyTokens = limit
+		default:
+			return domain.AIQuota{}, fmt.Errorf("unknown quota %q; use daily_requests, daily_tokens, monthly_requests or monthly_tokens", name)
+		}
+	}
+	return quota, nil
+}
@@ -3,399 +3,319 @@ package infrastructure
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
 	"strings"
-	"time"
 
 	domain "jobgen-backend/Domain"
-
-	"github.com/google/generative-ai-go/genai"
-	"golang.org/x/time/rate"
-	"google.golang.org/api/option"
 )
 
+// aiService implements every AI feature on top of the shared LLMClient, which owns rate
+// limiting, retries and usage accounting.
 type aiService struct {
-	client      *genai.Client
-	model       *genai.GenerativeModel
-	rateLimiter *rate.Limiter // may be nil when disabled
+	client *LLMClient
 }
 
-func applyDefaultGenConfig(m *genai.GenerativeModel) {
-	// genai.GenerationConfig is a value type with pointer fields
-	t := float32(0.7)
-	tp := float32(0.95)
-	tk := int32(40)
-	max := int32(2048)
-	m.GenerationConfig = genai.GenerationConfig{
-		Temperature:     &t,
-		TopP:            &tp,
-		TopK:            &tk,
-		MaxOutputTokens: &max,
-	}
+func NewAIService(client *LLMClient) domain.IAIService {
+	return &aiService{client: client}
 }
 
-func isModelNotFound(err error) bool {
-	if err == nil {
-		return false
+// GenerateTitle names a conversation after its first message. The message is normalised so
+// openings that differ only in case or spacing share a cached title.
+func (s *aiService) GenerateTitle(ctx context.Context, firstMessage string) (string, error) {
+	message := []rune(strings.Join(strings.Fields(strings.ToLower(firstMessage)), " "))
+	if len(message) > 200 {
+		message = message[:200]
 	}
-	es := strings.ToLower(err.Error())
-	return strings.Contains(es, "404") ||
-		strings.Contains(es, "not found") ||
-		strings.Contains(es, "is not supported for")
-}
+	prompt := fmt.Sprintf("Generate a short title (max 5 words) for a conversation that started with: %s\nReply with the title only.", string(message))
 
-func (s *aiService) tryModels(ctx context.Context, candidates []string, run func(*genai.GenerativeModel) (string, error)) (string, error) {
-	for _, name := range candidates {
-		mn := normalizeModel(name)
-		m := s.client.GenerativeModel(mn)
-		applyDefaultGenConfig(m)
-		res, err := run(m)
-		if err == nil {
-			// adopt working model
-			s.model = m
-			return res, nil
-		}
-		if !isModelNotFound(err) {
-			return "", err
+	title, err := s.client.GenerateCheckedText(ctx, "chat_title", userPrompt(prompt), func(text string) error {
+		if cleanTitle(text) == "" {
+			return errors.New("empty title")
 		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	title = cleanTitle(title)
+	if title == "" {
+		return "", &LLMError{Kind: LLMBadOutput, Operation: "chat_title", Err: fmt.Errorf("empty response")}
 	}
-	return "", fmt.Errorf("no available model among candidates")
+	return title, nil
+}
+
+// cleanTitle strips the quotes and emphasis models like to wrap titles in.
+func cleanTitle(text string) string {
+	return strings.Trim(strings.TrimSpace(text), `"'*`)
 }
 
-func NewAIService() (domain.IAIService, error) {
-	apiKey := Env.GeminiAPIKey
-	modelName := normalizeModel(Env.GeminiModel)
+// maxSummaryChars keeps the session summary short enough to send with every message.
+const maxSummaryChars = 2000
 
-	if apiKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY is required")
+// SummarizeConversation folds older messages into the running summary of a conversation.
+func (s *aiService) SummarizeConversation(ctx context.Context, summary string, messages []domain.ChatMessage) (string, error) {
+	if len(messages) == 0 {
+		return summary, nil
 	}
+	if strings.TrimSpace(summary) == "" {
+		summary = "None yet"
+	}
+	prompt := fmt.Sprintf(`Update the summary of a conversation between a user and JobGen, an AI career assistant, with the messages below.
+Keep what later replies will need: the user's background, goals and preferences, CV details and changes discussed, jobs mentioned with their [job:<id>] citations, and advice already given.
+Write at most 200 words of plain text and reply with the summary only.
+
+Summary so far:
+%s
 
-	// Log selected models and key length for diagnostics
-	log.Printf("Gemini init: sdkModel=%s restModel=%s apiKeyLen=%d", modelName, normalizeModelV1Beta(Env.GeminiModel), len(apiKey))
+New messages:
+%s`, summary, s.buildConversationContext(messages))
 
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	updated, err := s.client.GenerateText(ctx, "chat_summary", userPrompt(prompt))
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-
-	model := client.GenerativeModel(modelName)
-	applyDefaultGenConfig(model)
-
-	// Set up configurable rate limiting
-	var limiter *rate.Limiter
-	rpm := Env.GeminiRPM
-	if rpm > 0 {
-		// average rpm with burst = rpm
-		perReq := time.Minute / time.Duration(rpm)
-		limiter = rate.NewLimiter(rate.Every(perReq), rpm)
+	updated = strings.TrimSpace(updated)
+	if updated == "" {
+		return "", &LLMError{Kind: LLMBadOutput, Operation: "chat_summary", Err: fmt.Errorf("empty response")}
 	}
-
-	return &aiService{
-		client:      client,
-		model:       model,
-		rateLimiter: limiter,
-	}, nil
+	return truncateForPrompt(updated, maxSummaryChars), nil
 }
 
-// Minimal REST schema for v1beta fallback
-type glContent struct {
-	Role  string `json:"role,omitempty"`
-	Parts []struct {
-		Text string `json:"text"`
-	} `json:"parts"`
-}
+// AnalyzeCV reviews CV text pasted into the chat and returns a reply with typed suggestions.
+func (s *aiService) AnalyzeCV(ctx context.Context, cvText string) (string, []domain.Suggestion, error) {
+	if len(cvText) > 8000 {
+		cvText = cvText[:8000]
+	}
+	prompt := fmt.Sprintf(`You are JobGen, an AI career assistant specializing in helping African professionals find remote tech jobs.
+Analyze the following CV text and suggest specific, actionable improvements. Focus on:
+1. Adding quantifiable metrics to achievements
+2. Using strong action verbs
+3. Including relevant technical keywords
 
-type glRequest struct {
-	Contents []glContent `json:"contents"`
-}
+CV Text:
+%s`, cvText)
 
-type glResponse struct {
-	Candidates []struct {
-		Content glContent `json:"content"`
-	} `json:"candidates"`
-	Error *struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
-		Status  string `json:"status"`
-	} `json:"error,omitempty"`
+	reply, suggestions, err := s.generateSuggestions(ctx, "cv_analysis", prompt, suggestionSource{rawText: cvText})
+	if err != nil {
+		return "", nil, err
+	}
+	if reply == "" {
+		reply = suggestionsReply(suggestions)
+	}
+	return reply, suggestions, nil
 }
 
-func restGenerateText(ctx context.Context, apiKey, model, prompt string, history []domain.ChatMessage) (string, error) {
-	if strings.TrimSpace(model) == "" {
-		model = "gemini-1.5-flash"
-	}
-	base := normalizeModel(model)
-	candidates := []string{base}
-	if !strings.HasSuffix(base, "-latest") {
-		candidates = append(candidates, base+"-latest")
-	}
-
-	var lastErr error
-	for _, restModel := range candidates {
-		endpoint := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", restModel, apiKey)
-
-		var contents []glContent
-		for _, msg := range history {
-			role := "user"
-			if msg.Role == "assistant" {
-				role = "model"
-			}
-			contents = append(contents, glContent{Role: role, Parts: []struct {
-				Text string `json:"text"`
-			}{{Text: msg.Content}}})
-		}
-		contents = append(contents, glContent{Role: "user", Parts: []struct {
-			Text string `json:"text"`
-		}{{Text: prompt}}})
+func (s *aiService) ImproveCV(ctx context.Context, cv *domain.CV, userQuery string, history []domain.ChatMessage) (string, []domain.Suggestion, error) {
+	// Check if CV is nil
+	if cv == nil {
+		return "Please provide a CV to analyze and improve.", nil, nil
+	}
 
-		payload := glRequest{Contents: contents}
-		b, _ := json.Marshal(payload)
+	// Build context from conversation history
+	conversationContext := s.buildConversationContext(history)
 
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(string(b)))
-		if err != nil {
-			lastErr = err
-			continue
-		}
-		req.Header.Set("Content-Type", "application/json")
-		client := &http.Client{Timeout: 20 * time.Second}
-		resp, err := client.Do(req)
-		if err != nil {
-			lastErr = err
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			var er glResponse
-			_ = json.Unmarshal(bodyBytes, &er)
-			msg := strings.TrimSpace(string(bodyBytes))
-			if er.Error != nil && er.Error.Message != "" {
-				msg = er.Error.Message
-			}
-			log.Printf("Gemini REST v1beta error %d (%s): %s", resp.StatusCode, restModel, msg)
-			// Continue to next candidate if model not found/unsupported
-			low := strings.ToLower(msg)
-			if resp.StatusCode == 404 || strings.Contains(low, "not found") || strings.Contains(low, "not supported") {
-				lastErr = fmt.Errorf("gemini http %d: %s", resp.StatusCode, msg)
-				continue
-			}
-			return "", fmt.Errorf("gemini http %d: %s", resp.StatusCode, msg)
-		}
+	// Convert CV to text representation
+	cvText := s.formatCVForAI(cv)
 
-		var gr glResponse
-		if err := json.NewDecoder(resp.Body).Decode(&gr); err != nil {
-			lastErr = err
-			continue
-		}
-		if gr.Error != nil {
-			log.Printf("Gemini REST v1beta error (%s): %s", restModel, gr.Error.Message)
-			lastErr = fmt.Errorf("gemini error: %s", gr.Error.Message)
-			continue
-		}
-		if len(gr.Candidates) == 0 || len(gr.Candidates[0].Content.Parts) == 0 {
-			lastErr = fmt.Errorf("no candidates")
-			continue
-		}
-		return gr.Candidates[0].Content.Parts[0].Text, nil
+	// Build specialized prompt based on the user query
+	prompt := s.buildCVImprovementPrompt(cv, cvText, userQuery, conversationContext)
+
+	reply, suggestions, err := s.generateSuggestions(ctx, "cv_improvement", prompt, suggestionSource{cv: cv, rawText: cv.RawText})
+	if err != nil {
+		return "", nil, err
 	}
-	if lastErr == nil {
-		lastErr = fmt.Errorf("no rest model candidate succeeded")
+	if reply == "" {
+		reply = suggestionsReply(suggestions)
 	}
-	return "", lastErr
+	return reply, suggestions, nil
 }
 
-func (s *aiService) GenerateResponse(ctx context.Context, prompt string, history []domain.ChatMessage) (string, error) {
-	// Apply rate limiting
-	if s.rateLimiter != nil {
-		if err := s.rateLimiter.Wait(ctx); err != nil {
-			return "", fmt.Errorf("rate limit exceeded: %v", err)
-		}
-	}
-
-	run := func(m *genai.GenerativeModel) (string, error) {
-		cs := m.StartChat()
-		// Convert history
-		var historyParts []*genai.Content
-		for _, msg := range history {
-			role := "user"
-			if msg.Role == "assistant" {
-				role = "model"
-			}
-			historyParts = append(historyParts, &genai.Content{Parts: []genai.Part{genai.Text(msg.Content)}, Role: role})
-		}
-		cs.History = historyParts
-		resp, err := cs.SendMessage(ctx, genai.Text(prompt))
-		if err != nil {
-			return "", err
-		}
-		if len(resp.Candidates) > 0 && len(resp.Candidates[0].Content.Parts) > 0 {
-			return fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0]), nil
-		}
-		return "I'm sorry, I didn't get a response. Please try again.", nil
+func (s *aiService) SuggestForJob(ctx context.Context, cv *domain.CV, job *domain.Job, missingSkills []string) ([]domain.Suggestion, error) {
+	if cv == nil || job == nil {
+		return nil, fmt.Errorf("cv and job are required")
 	}
 
-	// First try current model
-	ans, err := run(s.model)
-	if err == nil || !isModelNotFound(err) {
-		return ans, err
+	description := job.Description
+	if len(description) > 4000 {
+		description = description[:4000]
 	}
-	// Fallback models via SDK
-	candidates := []string{Env.GeminiModel, "gemini-1.5-flash", "gemini-1.5-flash-8b", "gemini-1.5-pro"}
-	ans, err = s.tryModels(ctx, candidates, run)
-	if err == nil {
-		return ans, nil
+	missing := "None"
+	if len(missingSkills) > 0 {
+		missing = strings.Join(missingSkills, ", ")
 	}
-	// As final fallback, call REST v1beta
-	text, restErr := restGenerateText(ctx, Env.GeminiAPIKey, Env.GeminiModel, prompt, history)
-	if restErr == nil {
-		return text, nil
-	}
-	return "", fmt.Errorf("sdk fallback error: %v; rest error: %v", err, restErr)
+
+	prompt := fmt.Sprintf(`You are JobGen, an AI career assistant specializing in helping African professionals land remote tech jobs.
+
+The user wants to apply to the job below. Suggest concrete changes to their CV that make it a stronger fit for THIS job.
+
+JOB: %s at %s
+JOB DESCRIPTION:
+%s
+
+SKILLS THE JOB ASKS FOR THAT THE CV DOES NOT SHOW: %s
+
+%s
+----------------------------------------
+EXPERIENCE IDS:
+%s
+Rewrite existing experience bullets where possible, and only mention a missing skill if the CV gives some evidence the user has it. Quote "original" text exactly as it appears in the CV section you target, and never invent facts that are not in the CV.`,
+		job.Title, job.CompanyName, description, missing, s.formatCVForAI(cv), experienceIDList(cv))
+
+	_, suggestions, err := s.generateSuggestions(ctx, "job_suggestions", prompt, suggestionSource{cv: cv})
+	return suggestions, err
 }
 
-func (s *aiService) AnalyzeCV(ctx context.Context, cvText string) (string, error) {
-	if s.rateLimiter != nil {
-		if err := s.rateLimiter.Wait(ctx); err != nil {
-			return "", fmt.Errorf("rate limit exceeded: %v", err)
-		}
+func (s *aiService) GenerateCoverLetter(ctx context.Context, cv *domain.CV, job *domain.Job, opts domain.CoverLetterOptions) (string, error) {
+	if cv == nil || job == nil {
+		return "", fmt.Errorf("cv and job are required")
 	}
 
-	prompt := fmt.Sprintf(`You are JobGen, an AI career assistant specializing in helping African professionals find remote tech jobs. 
-	Analyze the following CV text and provide specific, actionable suggestions for improvement. Focus on:
-	1. Adding quantifiable metrics to achievements
-	2. Using strong action verbs
-	3. Including relevant technical keywords
-	4. Improving structure for Applicant Tracking Systems (ATS)
-	5. Tailoring for international remote tech jobs
-	
-	CV Text: %s
-	
-	Provide your response in a helpful, professional tone with clear bullet points.`, cvText)
-
-	run := func(m *genai.GenerativeModel) (string, error) {
-		result, err := m.GenerateContent(ctx, genai.Text(prompt))
-		if err != nil {
-			return "", err
-		}
-		if len(result.Candidates) > 0 && len(result.Candidates[0].Content.Parts) > 0 {
-			return fmt.Sprintf("%v", result.Candidates[0].Content.Parts[0]), nil
-		}
-		return "I couldn't analyze your CV at this time. Please try again later.", nil
+	description := job.Description
+	if len(description) > 4000 {
+		description = description[:4000]
 	}
 
-	ans, err := run(s.model)
-	if err == nil || !isModelNotFound(err) {
-		return ans, err
-	}
-	candidates := []string{Env.GeminiModel, "gemini-1.5-flash", "gemini-1.5-flash-8b", "gemini-1.5-pro"}
-	ans, err = s.tryModels(ctx, candidates, run)
-	if err == nil {
-		return ans, nil
+	prompt := fmt.Sprintf(`You are JobGen, an AI career assistant specializing in helping African professionals land remote tech jobs.
+
+Write a cover letter for the job below using ONLY facts from the candidate's CV. Do not invent employers, titles, dates, metrics or skills.
+
+JOB: %s at %s (%s)
+JOB DESCRIPTION:
+%s
+
+%s
+Requirements:
+- Tone: %s
+- Length: about %d words
+- Connect the candidate's most relevant experiences to the job's requirements
+- Plain paragraphs only, no headings, no placeholders like [Your Name]
+- Output only the letter text`,
+		job.Title, job.CompanyName, job.Location, description, s.formatCVForAI(cv), opts.Tone, opts.Length.TargetWords())
+
+	ans, err := s.client.GenerateText(ctx, "cover_letter", userPrompt(prompt))
+	if err != nil {
+		return "", err
 	}
-	// REST fallback - pass the prompt, not raw cvText
-	text, restErr := restGenerateText(ctx, Env.GeminiAPIKey, Env.GeminiModel, prompt, nil)
-	if restErr == nil {
-		return text, nil
+	if strings.TrimSpace(ans) == "" {
+		return "", fmt.Errorf("empty response")
 	}
-	return "", fmt.Errorf("sdk fallback error: %v; rest error: %v", err, restErr)
+	return ans, nil
 }
 
-func (s *aiService) FindJobs(ctx context.Context, userProfile, query string) (string, error) {
-	if s.rateLimiter != nil {
-		if err := s.rateLimiter.Wait(ctx); err != nil {
-			return "", fmt.Errorf("rate limit exceeded: %v", err)
-		}
+func (s *aiService) TailorCV(ctx context.Context, cv *domain.CV, job *domain.Job) (*domain.TailoredCVContent, error) {
+	if cv == nil || job == nil {
+		return nil, fmt.Errorf("cv and job are required")
 	}
 
-	prompt := fmt.Sprintf(`You are JobGen, an AI career assistant specializing in helping African professionals find remote tech jobs. 
-	Based on the user profile and query below, provide personalized job search advice and suggestions for remote tech jobs that might be a good fit.
-	
-	User Profile: %s
-	User Query: %s
-	
-	Provide your response with specific advice, potential job roles to explore, and tips for applying to remote positions. 
-	Focus on opportunities that might be open to African candidates.`, userProfile, query)
-
-	run := func(m *genai.GenerativeModel) (string, error) {
-		result, err := m.GenerateContent(ctx, genai.Text(prompt))
-		if err != nil {
-			return "", err
-		}
-		if len(result.Candidates) > 0 && len(result.Candidates[0].Content.Parts) > 0 {
-			response := fmt.Sprintf("%v", result.Candidates[0].Content.Parts[0])
-			disclaimer := "\n\n*Note: This is AI-generated advice based on your profile. For actual job listings, we recommend checking dedicated job platforms like LinkedIn, RemoteOK, and Indeed.*"
-			return response + disclaimer, nil
-		}
-		return "I couldn't search for jobs at this time. Please try again later.", nil
+	description := job.Description
+	if len(description) > 4000 {
+		description = description[:4000]
 	}
 
-	ans, err := run(s.model)
-	if err == nil || !isModelNotFound(err) {
-		return ans, err
-	}
-	candidates := []string{Env.GeminiModel, "gemini-1.5-flash", "gemini-1.5-flash-8b", "gemini-1.5-pro"}
-	ans, err = s.tryModels(ctx, candidates, run)
-	if err == nil {
-		return ans, nil
+	var experiences strings.Builder
+	for _, exp := range cv.Experiences {
+		experiences.WriteString(fmt.Sprintf("- id: %s\n  role: %s at %s\n  description: %s\n", exp.ID, exp.Title, exp.Company, exp.Description))
 	}
-	// REST fallback - pass the prompt, not the raw query
-	text, restErr := restGenerateText(ctx, Env.GeminiAPIKey, Env.GeminiModel, prompt, nil)
-	if restErr == nil {
-		return text, nil
-	}
-	return "", fmt.Errorf("sdk fallback error: %v; rest error: %v", err, restErr)
-}
 
-func (s *aiService) ImproveCV(ctx context.Context, cv *domain.CV, userQuery string, history []domain.ChatMessage) (string, []domain.Suggestion, error) {
-	if s.rateLimiter != nil {
-		if err := s.rateLimiter.Wait(ctx); err != nil {
-			return "", nil, fmt.Errorf("rate limit exceeded: %v", err)
-		}
-	}
+	prompt := fmt.Sprintf(`You are JobGen, an AI career assistant. Rewrite parts of a CV so it targets a specific job.
 
-	// Check if CV is nil
-	if cv == nil {
-		return "Please provide a CV to analyze and improve.", nil, nil
+JOB: %s at %s
+JOB DESCRIPTION:
+%s
+
+CURRENT PROFILE SUMMARY:
+%s
+
+CURRENT EXPERIENCES:
+%s
+Rules:
+- Rewrite the profile summary and each experience description to emphasize skills and achievements relevant to the job.
+- Use ONLY facts already present in the CV. Never add employers, titles, dates, numbers, tools or skills that are not in the original text.
+- Keep every experience id exactly as given. Omit an experience if nothing should change.
+- Output ONLY a JSON object: {"profileSummary": string, "experienceDescriptions": {"<id>": string}}. No markdown, no extra text.`,
+		job.Title, job.CompanyName, description, cv.ProfileSummary, experiences.String())
+
+	response, err := s.client.GenerateText(ctx, "cv_tailoring", LLMRequest{Messages: []LLMMessage{{Role: "user", Content: prompt}}, JSON: true})
+	if err != nil {
+		return nil, err
 	}
 
-	// Build context from conversation history
-	conversationContext := s.buildConversationContext(history)
+	var tailored domain.TailoredCVContent
+	if err := json.Unmarshal([]byte(extractJSONObject(response)), &tailored); err != nil {
+		return nil, &LLMError{Kind: LLMBadOutput, Operation: "cv_tailoring", Err: fmt.Errorf("invalid json response: %v", err)}
+	}
+	return &tailored, nil
+}
 
-	// Convert CV to text representation
-	cvText := s.formatCVForAI(cv)
+// SuggestCVImprovements asks for typed suggestions on a CV's raw text.
+func (s *aiService) SuggestCVImprovements(ctx context.Context, rawText string) ([]domain.Suggestion, error) {
+	if len(rawText) > 8000 {
+		rawText = rawText[:8000]
+	}
+	prompt := "You are an assistant that extracts CV improvement suggestions: missing metrics, weak action verbs and missing keywords.\nCV:\n" + rawText
 
-	// Build specialized prompt based on the user query
-	prompt := s.buildCVImprovementPrompt(cvText, userQuery, conversationContext)
+	_, suggestions, err := s.generateSuggestions(ctx, "cv_suggestions", prompt, suggestionSource{rawText: rawText})
+	return suggestions, err
+}
 
-	run := func(m *genai.GenerativeModel) (string, error) {
-		result, err := m.GenerateContent(ctx, genai.Text(prompt))
-		if err != nil {
-			return "", err
-		}
-		if len(result.Candidates) > 0 && len(result.Candidates[0].Content.Parts) > 0 {
-			return fmt.Sprintf("%v", result.Candidates[0].Content.Parts[0]), nil
+// ExtractCVFields returns the CV's structured fields as a JSON object.
+func (s *aiService) ExtractCVFields(ctx context.Context, rawText string) (string, error) {
+	instruction := `You extract structured data from CV text. Use ONLY information present in the text; never guess or invent values.
+Output ONLY a JSON object with exactly these keys, no markdown, no extra text:
+{"profileSummary": string,
+ "experiences": [{"title": string, "company": string, "location": string, "startDate": "YYYY-MM", "endDate": "YYYY-MM" or "" if current, "description": string}],
+ "educations": [{"degree": string, "institution": string, "location": string, "graduationDate": "YYYY" or "YYYY-MM" or ""}],
+ "skills": [string]}
+Use an empty string or empty array when a value is not in the CV.`
+
+	if len(rawText) > 12000 {
+		rawText = rawText[:12000]
+	}
+	req := userPrompt(instruction + "\nCV:\n" + rawText)
+	req.JSON = true
+	text, err := s.client.GenerateCheckedText(ctx, "cv_extraction", req, func(text string) error {
+		if !json.Valid([]byte(extractJSONObject(text))) {
+			return errors.New("response is not a JSON object")
 		}
-		return "I couldn't analyze your CV at this time. Please try again later.", nil
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
+	return extractJSONObject(text), nil
+}
 
-	response, err := run(s.model)
-	if err != nil && isModelNotFound(err) {
-		candidates := []string{Env.GeminiModel, "gemini-1.5-flash", "gemini-1.5-flash-8b", "gemini-1.5-pro"}
-		response, err = s.tryModels(ctx, candidates, run)
-	}
-	if err != nil {
-		return "", nil, err
+// extractJSON tries to isolate the first JSON array in text.
+func extractJSON(s string) string {
+	s = strings.TrimSpace(s)
+
+	s = strings.TrimPrefix(s, "```json")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSuffix(s, "```")
+	s = strings.TrimSpace(s)
+
+	start := strings.Index(s, "[")
+	end := strings.LastIndex(s, "]")
+	if start >= 0 && end > start {
+		return s[start : end+1]
 	}
+	return s
+}
 
-	// Parse response to extract suggestions
-	suggestions := s.extractSuggestionsFromResponse(response)
+// extractJSONObject isolates the outermost JSON object in a model response.
+func extractJSONObject(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "```json")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSuffix(s, "```")
 
-	return response, suggestions, nil
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start >= 0 && end > start {
+		return s[start : end+1]
+	}
+	return s
 }
 
 func (s *aiService) buildConversationContext(history []domain.ChatMessage) string {
@@ -406,8 +326,11 @@ func (s *aiService) buildConversationContext(history []domain.ChatMessage) strin
 	var context strings.Builder
 	for _, msg := range history {
 		role := "User"
-		if msg.Role == "assistant" {
+		switch msg.Role {
+		case "assistant":
 			role = "Assistant"
+		case domain.ChatSummaryRole:
+			role = "Summary of the earlier conversation"
 		}
 		context.WriteString(fmt.Sprintf("%s: %s\n", role, msg.Content))
 	}
@@ -460,12 +383,15 @@ func (s *aiService) formatCVForAI(cv *domain.CV) string {
 	return sb.String()
 }
 
-func (s *aiService) buildCVImprovementPrompt(cvText, userQuery, conversationContext string) string {
+func (s *aiService) buildCVImprovementPrompt(cv *domain.CV, cvText, userQuery, conversationContext string) string {
 	prompt := `You are JobGen, an AI career assistant specializing in helping African professionals improve their CVs for remote tech jobs.
 
 Below is the user's CV information:
 %s
 ----------------------------------------
+EXPERIENCE IDS:
+%s
+----------------------------------------
 CONVERSATION CONTEXT:
 %s
 ----------------------------------------
@@ -479,45 +405,19 @@ Please provide specific, actionable suggestions to improve this CV. Focus on:
 4. Improving structure for Applicant Tracking Systems (ATS)
 5. Tailoring for international remote tech jobs
 
-Format your response with clear sections and bullet points. Provide specific examples whenever possible.`
+Quote "original" text exactly as it appears in the CV section you target, and never invent facts that are not in the CV.`
 
-	return fmt.Sprintf(prompt, cvText, conversationContext, userQuery)
+	return fmt.Sprintf(prompt, cvText, experienceIDList(cv), conversationContext, userQuery)
 }
 
-func (s *aiService) extractSuggestionsFromResponse(response string) []domain.Suggestion {
-	var suggestions []domain.Suggestion
-
-	// Simple parsing logic
-	lines := strings.Split(response, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Extract bullet points as suggestions
-		if (strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "• ") || strings.HasPrefix(line, "* ")) && len(line) > 2 {
-			content := strings.TrimPrefix(line, "- ")
-			content = strings.TrimPrefix(content, "• ")
-			content = strings.TrimPrefix(content, "* ")
-			content = strings.TrimSpace(content)
-
-			if content != "" {
-				suggestions = append(suggestions, domain.Suggestion{
-					Type:    "cv_improvement",
-					Content: content,
-					Applied: false,
-				})
-			}
-		}
+// experienceIDList lists the CV's experiences by id, so suggestions can target them.
+func experienceIDList(cv *domain.CV) string {
+	var ids strings.Builder
+	for _, exp := range cv.Experiences {
+		ids.WriteString(fmt.Sprintf("- %s: %s at %s\n", exp.ID, exp.Title, exp.Company))
 	}
-
-	// If no structured suggestions found, use the whole response as a suggestion
-	if len(suggestions) == 0 {
-		suggestions = append(suggestions, domain.Suggestion{
-			Type:    "cv_improvement",
-			Content: response,
-			Applied: false,
-		})
+	if ids.Len() == 0 {
+		ids.WriteString("None\n")
 	}
-
-	return suggestions
+	return ids.String()
 }
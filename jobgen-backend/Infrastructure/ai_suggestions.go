@@ -0,0 +1,239 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	domain "jobgen-backend/Domain"
+
+	"github.com/google/uuid"
+)
+
+// suggestionSchema is appended to every prompt that asks for CV improvement suggestions.
+var suggestionSchema = fmt.Sprintf(`Respond ONLY with a JSON object, no markdown, no extra text:
+{"reply": string, "suggestions": [{"type": string, "section": string, "targetId": string, "original": string, "replacement": string, "rationale": string, "content": string}]}
+- "reply": a short, friendly message to the user summarising the suggestions
+- "type": one of [%s]
+- "section": one of [%s]
+- "targetId": the experience id when section is "experience", otherwise ""
+- "original": the exact text from the CV to replace, or "" when adding something new
+- "replacement": the proposed new text
+- "rationale": why the change helps
+- "content": a one-line summary of the change
+Give at most 8 suggestions.`, quoteList(domain.SuggestionTypes), quoteList(domain.SuggestionSections))
+
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = `"` + v + `"`
+	}
+	return strings.Join(quoted, ", ")
+}
+
+type suggestionOutput struct {
+	Reply       string              `json:"reply"`
+	Suggestions []domain.Suggestion `json:"suggestions"`
+}
+
+// suggestionSource is the CV the suggestions are for: a structured CV, or only its raw text.
+type suggestionSource struct {
+	cv      *domain.CV
+	rawText string
+}
+
+// generateSuggestions runs a suggestion prompt and validates the output. A malformed or invalid
+// response is sent back to the model once with the problems found; if the second response is
+// still invalid its valid suggestions are kept, and with none the call fails as bad output.
+func (s *aiService) generateSuggestions(ctx context.Context, operation, prompt string, source suggestionSource) (string, []domain.Suggestion, error) {
+	req := LLMRequest{Messages: []LLMMessage{{Role: "user", Content: prompt + "\n\n" + suggestionSchema}}, JSON: true}
+	// Only responses that need no repair are worth caching
+	validate := func(text string) error {
+		if _, problems := parseSuggestionOutput(text, source); len(problems) > 0 {
+			return errors.New(strings.Join(problems, "; "))
+		}
+		return nil
+	}
+	text, err := s.client.GenerateCheckedText(ctx, operation, req, validate)
+	if err != nil {
+		return "", nil, err
+	}
+	out, problems := parseSuggestionOutput(text, source)
+	if len(problems) == 0 {
+		return out.Reply, out.Suggestions, nil
+	}
+
+	log.Printf("🟠 AI %s returned invalid suggestions, asking for a repair: %s", operation, strings.Join(problems, "; "))
+	req.Messages = append(req.Messages,
+		LLMMessage{Role: "assistant", Content: text},
+		LLMMessage{Role: "user", Content: "Your previous response was rejected:\n- " + strings.Join(problems, "\n- ") + "\nReturn the corrected JSON object only, following the same format."},
+	)
+	text, err = s.client.GenerateCheckedText(ctx, operation, req, validate)
+	if err != nil {
+		return "", nil, err
+	}
+	out, problems = parseSuggestionOutput(text, source)
+	if len(problems) > 0 {
+		if len(out.Suggestions) == 0 {
+			return "", nil, &LLMError{Kind: LLMBadOutput, Operation: operation, Err: errors.New(strings.Join(problems, "; "))}
+		}
+		log.Printf("🟠 AI %s dropped invalid suggestions after repair: %s", operation, strings.Join(problems, "; "))
+	}
+	return out.Reply, out.Suggestions, nil
+}
+
+var trailingCommaRE = regexp.MustCompile(`,\s*([}\]])`)
+
+// parseSuggestionOutput decodes a suggestion response and keeps the suggestions that pass
+// validation. It returns a description of every problem found; the output is usable when
+// there are none.
+func parseSuggestionOutput(text string, source suggestionSource) (suggestionOutput, []string) {
+	var out suggestionOutput
+	raw := strings.TrimSpace(text)
+	if strings.HasPrefix(strings.TrimPrefix(strings.TrimPrefix(raw, "```json"), "```"), "[") {
+		// A bare array of suggestions is accepted as well
+		raw = "{\"suggestions\": " + extractJSON(raw) + "}"
+	} else {
+		raw = extractJSONObject(raw)
+	}
+	// Trailing commas are the most common syntax slip and are safe to repair locally
+	raw = trailingCommaRE.ReplaceAllString(raw, "$1")
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		return suggestionOutput{}, []string{fmt.Sprintf("the response is not valid JSON: %v", err)}
+	}
+
+	var problems []string
+	valid := make([]domain.Suggestion, 0, len(out.Suggestions))
+	for i, sg := range out.Suggestions {
+		sg, problem := validateSuggestion(sg, source)
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("suggestion %d: %s", i+1, problem))
+			continue
+		}
+		valid = append(valid, sg)
+	}
+	// An empty array is a valid answer for a CV that needs no changes; only a missing one is not
+	if out.Suggestions == nil {
+		problems = append(problems, `the response has no "suggestions" array`)
+	}
+	out.Suggestions = valid
+	out.Reply = strings.TrimSpace(out.Reply)
+	return out, problems
+}
+
+// validateSuggestion normalises one suggestion and reports why it cannot be used, if it cannot.
+func validateSuggestion(sg domain.Suggestion, source suggestionSource) (domain.Suggestion, string) {
+	sg.Type = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(sg.Type)), " ", "_")
+	if !containsString(domain.SuggestionTypes, sg.Type) {
+		return sg, fmt.Sprintf("type %q is not one of %s", sg.Type, quoteList(domain.SuggestionTypes))
+	}
+	sg.Section = strings.TrimSpace(sg.Section)
+	if !containsString(domain.SuggestionSections, sg.Section) {
+		return sg, fmt.Sprintf("section %q is not one of %s", sg.Section, quoteList(domain.SuggestionSections))
+	}
+
+	sg.TargetID = strings.TrimSpace(sg.TargetID)
+	if sg.Section != domain.SectionExperience {
+		sg.TargetID = ""
+	} else if source.cv != nil && len(source.cv.Experiences) > 0 {
+		if findExperience(source.cv, sg.TargetID) == nil {
+			return sg, fmt.Sprintf("targetId %q is not the id of an experience in the CV", sg.TargetID)
+		}
+	} else {
+		sg.TargetID = "" // raw text has no experience ids
+	}
+
+	sg.Original = strings.TrimSpace(sg.Original)
+	sg.Replacement = strings.TrimSpace(sg.Replacement)
+	sg.Rationale = strings.TrimSpace(sg.Rationale)
+	sg.Content = strings.TrimSpace(sg.Content)
+	if sg.Replacement == "" {
+		return sg, "replacement is empty"
+	}
+	if sg.Original != "" && !containsNormalized(source.sectionText(sg.Section, sg.TargetID), sg.Original) {
+		return sg, fmt.Sprintf("original text %q does not appear in the %s section of the CV", truncateForPrompt(sg.Original, 80), sg.Section)
+	}
+	if sg.Original == sg.Replacement {
+		return sg, "replacement is the same as the original"
+	}
+	if sg.Content == "" {
+		sg.Content = sg.Rationale
+	}
+	if sg.Content == "" {
+		return sg, "content and rationale are empty"
+	}
+
+	sg.ID = "sug-" + uuid.NewString()[:8]
+	sg.Applied = false
+	return sg, ""
+}
+
+// sectionText returns the CV text a suggestion's original must be quoted from.
+func (src suggestionSource) sectionText(section, targetID string) string {
+	cv := src.cv
+	if cv == nil {
+		return src.rawText
+	}
+	switch section {
+	case domain.SectionProfileSummary:
+		return cv.ProfileSummary
+	case domain.SectionExperience:
+		if exp := findExperience(cv, targetID); exp != nil {
+			return exp.Title + "\n" + exp.Description
+		}
+	case domain.SectionSkills:
+		return strings.Join(cv.Skills, "\n")
+	case domain.SectionEducation:
+		var sb strings.Builder
+		for _, edu := range cv.Educations {
+			sb.WriteString(edu.Degree + " " + edu.Institution + " " + edu.Location + "\n")
+		}
+		return sb.String()
+	}
+	return cv.RawText
+}
+
+func findExperience(cv *domain.CV, id string) *domain.Experience {
+	for i := range cv.Experiences {
+		if cv.Experiences[i].ID == id {
+			return &cv.Experiences[i]
+		}
+	}
+	return nil
+}
+
+// containsNormalized reports whether needle occurs in haystack, ignoring case and spacing.
+func containsNormalized(haystack, needle string) bool {
+	normalize := func(s string) string { return strings.Join(strings.Fields(strings.ToLower(s)), " ") }
+	return strings.Contains(normalize(haystack), normalize(needle))
+}
+
+func containsString(values []string, v string) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateForPrompt(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// suggestionsReply is the chat message used when the model gave no reply of its own.
+func suggestionsReply(suggestions []domain.Suggestion) string {
+	var sb strings.Builder
+	sb.WriteString("Here are some ways to improve your CV:\n")
+	for _, sg := range suggestions {
+		sb.WriteString("- " + sg.Content + "\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
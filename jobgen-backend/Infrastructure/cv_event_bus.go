@@ -0,0 +1,79 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	domain "jobgen-backend/Domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// CVEventBus fans CV job events out from the worker pool to the API processes streaming
+// them. Delivery is best effort: subscribers only see events published while subscribed.
+type CVEventBus interface {
+	Publish(event domain.CVJobEvent) error
+	// Subscribe returns the events of one job. The channel is closed once ctx is done.
+	Subscribe(ctx context.Context, jobID string) (<-chan domain.CVJobEvent, error)
+}
+
+// redisCVEventBus publishes each job's events on its own pub/sub channel so workers and API
+// servers in different processes can talk to each other.
+type redisCVEventBus struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewCVEventBus(client *redis.Client, channelPrefix string) CVEventBus {
+	return &redisCVEventBus{client: client, prefix: channelPrefix}
+}
+
+func (b *redisCVEventBus) channel(jobID string) string {
+	return b.prefix + ":" + jobID
+}
+
+func (b *redisCVEventBus) Publish(event domain.CVJobEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(context.Background(), b.channel(event.JobID), payload).Err()
+}
+
+func (b *redisCVEventBus) Subscribe(ctx context.Context, jobID string) (<-chan domain.CVJobEvent, error) {
+	sub := b.client.Subscribe(ctx, b.channel(jobID))
+	// Wait for the subscription to be confirmed so no event published afterwards is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	events := make(chan domain.CVJobEvent, subscriberBuffer)
+	go func() {
+		defer close(events)
+		defer sub.Close()
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event domain.CVJobEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					log.Printf("🟠 Dropping unreadable CV job event on %s: %v", msg.Channel, err)
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
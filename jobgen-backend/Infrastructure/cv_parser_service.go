@@ -1,26 +1,194 @@
 package infrastructure
 
 import (
+	"archive/zip"
 	"bytes"
+	"fmt"
 	"io"
 	"os"
 	"strings"
+	"unicode/utf8"
+
+	domain "jobgen-backend/Domain"
 
 	pdf "github.com/ledongthuc/pdf"
 )
 
 type CVParserService interface {
 	ExtractText(file io.Reader) (string, error)
+	// ExtractDocument returns the text as ordered blocks; for PDFs the blocks follow the
+	// visual layout (columns, headings, bullets).
+	ExtractDocument(file io.Reader) (*domain.CVDocument, error)
+}
+
+// CVFormat is a CV file type as detected from the file's content
+type CVFormat string
+
+const (
+	CVFormatUnknown CVFormat = ""
+	CVFormatPDF     CVFormat = "pdf"
+	CVFormatDOCX    CVFormat = "docx"
+	CVFormatODT     CVFormat = "odt"
+	CVFormatRTF     CVFormat = "rtf"
+	CVFormatText    CVFormat = "text" // plain text and Markdown
+)
+
+const odtMimeType = "application/vnd.oasis.opendocument.text"
+
+// ContentType returns the MIME type files of this format are stored and served with.
+func (f CVFormat) ContentType() string {
+	switch f {
+	case CVFormatPDF:
+		return "application/pdf"
+	case CVFormatDOCX:
+		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	case CVFormatODT:
+		return odtMimeType
+	case CVFormatRTF:
+		return "application/rtf"
+	case CVFormatText:
+		return "text/plain; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
+
+// Extension returns the file extension for the format, including the dot.
+func (f CVFormat) Extension() string {
+	switch f {
+	case CVFormatPDF, CVFormatDOCX, CVFormatODT, CVFormatRTF:
+		return "." + string(f)
+	case CVFormatText:
+		return ".txt"
+	}
+	return ""
+}
+
+var (
+	pdfMagic = []byte("%PDF-")
+	zipMagic = []byte("PK\x03\x04")
+	rtfMagic = []byte(`{\rtf`)
+	oleMagic = []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1") // legacy .doc
+)
+
+// DetectCVFormat identifies a CV file by its magic bytes; the file name is never trusted.
+func DetectCVFormat(data []byte) CVFormat {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+
+	switch {
+	case bytes.Contains(head, pdfMagic): // the PDF header may follow up to 1KB of junk
+		return CVFormatPDF
+	case bytes.HasPrefix(data, zipMagic):
+		return detectZipFormat(data)
+	case bytes.HasPrefix(data, oleMagic):
+		return CVFormatUnknown
+	case bytes.HasPrefix(bytes.TrimLeft(bytes.TrimPrefix(head, utf8BOM), " \t\r\n"), rtfMagic):
+		return CVFormatRTF
+	case looksLikeText(data):
+		return CVFormatText
+	}
+	return CVFormatUnknown
+}
+
+// detectZipFormat tells DOCX and ODT packages apart by their entries
+func detectZipFormat(data []byte) CVFormat {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return CVFormatUnknown
+	}
+	if f := findZipFile(zr, "mimetype"); f != nil {
+		if mt, err := readZipFile(f, 256); err == nil && strings.TrimSpace(string(mt)) == odtMimeType {
+			return CVFormatODT
+		}
+	}
+	if findZipFile(zr, "word/document.xml") != nil {
+		return CVFormatDOCX
+	}
+	return CVFormatUnknown
 }
 
-type pdfParserService struct{}
+type cvParserService struct{}
 
+// NewCVParserService returns a parser that detects the file format and dispatches to the
+// matching text extractor.
 func NewCVParserService() CVParserService {
-	return &pdfParserService{}
+	return &cvParserService{}
+}
+
+func (p *cvParserService) ExtractText(reader io.Reader) (string, error) {
+	doc, err := p.ExtractDocument(reader)
+	if err != nil {
+		return "", err
+	}
+	return doc.PlainText(), nil
 }
 
-func (p *pdfParserService) ExtractText(reader io.Reader) (string, error) {
-	// Write the incoming reader to a temporary file so we can use the parser APIs
+func (p *cvParserService) ExtractDocument(reader io.Reader) (*domain.CVDocument, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	format := DetectCVFormat(data)
+	var text string
+	switch format {
+	case CVFormatPDF:
+		doc, err := extractPDFDocument(data)
+		if err == nil && len(doc.Blocks) > 0 {
+			return doc, nil
+		}
+		// Fall back to the flat text stream, e.g. for fonts without width tables
+		text, err = extractPDFText(data)
+	case CVFormatDOCX:
+		text, err = extractDOCXText(data)
+	case CVFormatODT:
+		text, err = extractODTText(data)
+	case CVFormatRTF:
+		text, err = extractRTFText(data)
+	case CVFormatText:
+		text, err = extractPlainText(data)
+	default:
+		if bytes.HasPrefix(data, oleMagic) {
+			return nil, fmt.Errorf("%w: legacy .doc files are not supported, please save as DOCX or PDF", domain.ErrUnsupportedCVFormat)
+		}
+		return nil, fmt.Errorf("%w: expected PDF, DOCX, ODT, RTF or plain text", domain.ErrUnsupportedCVFormat)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return documentFromText(format, text), nil
+}
+
+// documentFromText splits extracted text into blocks: blank lines separate paragraphs and
+// lines starting with a bullet become bullet blocks. No headings are inferred.
+func documentFromText(format CVFormat, text string) *domain.CVDocument {
+	doc := &domain.CVDocument{Format: string(format)}
+	startNew := true
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			startNew = true
+			continue
+		}
+		if item, ok := splitBullet(line); ok {
+			doc.Blocks = append(doc.Blocks, domain.CVTextBlock{Kind: domain.BlockBullet, Text: item})
+			startNew = true
+			continue
+		}
+		if n := len(doc.Blocks); !startNew && n > 0 {
+			doc.Blocks[n-1].Text += "\n" + line
+		} else {
+			doc.Blocks = append(doc.Blocks, domain.CVTextBlock{Kind: domain.BlockParagraph, Text: line})
+		}
+		startNew = false
+	}
+	return doc
+}
+
+func extractPDFText(data []byte) (string, error) {
+	// Write the data to a temporary file so we can use the parser APIs
 	tmp, err := os.CreateTemp("", "cv-*.pdf")
 	if err != nil {
 		return "", err
@@ -31,7 +199,7 @@ func (p *pdfParserService) ExtractText(reader io.Reader) (string, error) {
 		os.Remove(tmpPath)
 	}()
 
-	if _, err := io.Copy(tmp, reader); err != nil {
+	if _, err := tmp.Write(data); err != nil {
 		return "", err
 	}
 
@@ -55,3 +223,26 @@ func (p *pdfParserService) ExtractText(reader io.Reader) (string, error) {
 	text := strings.ReplaceAll(b.String(), "\r\n", "\n")
 	return text, nil
 }
+
+// looksLikeText accepts UTF-8 or BOM-marked UTF-16 content without binary control bytes.
+func looksLikeText(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	if bytes.HasPrefix(data, utf16LEBOM) || bytes.HasPrefix(data, utf16BEBOM) {
+		return true
+	}
+	if !utf8.Valid(data) {
+		return false
+	}
+	control := 0
+	for _, c := range data {
+		if c == 0 {
+			return false
+		}
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' && c != '\f' {
+			control++
+		}
+	}
+	return control*100 < len(data)
+}
@@ -0,0 +1,413 @@
+package infrastructure
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Extractors for the non-PDF CV formats. Each one emits one paragraph per line (table cells
+// included) with list items prefixed by "• ", so the section parser sees the same shape of
+// text regardless of the source format.
+
+var (
+	utf8BOM    = []byte("\xEF\xBB\xBF")
+	utf16LEBOM = []byte("\xFF\xFE")
+	utf16BEBOM = []byte("\xFE\xFF")
+)
+
+const maxZipEntrySize = 20 << 20 // guards against zip bombs
+
+func findZipFile(zr *zip.Reader, name string) *zip.File {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func readZipFile(f *zip.File, limit int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is too large", f.Name)
+	}
+	return data, nil
+}
+
+func openZipXML(data []byte, name string) (*xml.Decoder, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	f := findZipFile(zr, name)
+	if f == nil {
+		return nil, fmt.Errorf("%s not found in document", name)
+	}
+	content, err := readZipFile(f, maxZipEntrySize)
+	if err != nil {
+		return nil, err
+	}
+	return xml.NewDecoder(bytes.NewReader(content)), nil
+}
+
+// ---------------- DOCX ----------------
+
+var wordNamespaces = map[string]bool{
+	"http://schemas.openxmlformats.org/wordprocessingml/2006/main": true,
+	"http://purl.oclc.org/ooxml/wordprocessingml/main":             true, // strict OOXML
+}
+
+func extractDOCXText(data []byte) (string, error) {
+	dec, err := openZipXML(data, "word/document.xml")
+	if err != nil {
+		return "", fmt.Errorf("invalid docx: %w", err)
+	}
+
+	var b strings.Builder
+	inRun, inText := false, false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid docx: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "Fallback" { // mc:AlternateContent repeats text boxes in the fallback
+				if err := dec.Skip(); err != nil {
+					return "", fmt.Errorf("invalid docx: %w", err)
+				}
+				continue
+			}
+			if !wordNamespaces[t.Name.Space] {
+				continue
+			}
+			switch t.Name.Local {
+			case "r":
+				inRun = true
+			case "t":
+				inText = inRun
+			case "tab":
+				if inRun { // w:tab outside a run is a tab stop definition
+					b.WriteByte('\t')
+				}
+			case "br", "cr":
+				if inRun {
+					b.WriteByte('\n')
+				}
+			case "numPr":
+				b.WriteString("• ")
+			}
+		case xml.EndElement:
+			if !wordNamespaces[t.Name.Space] {
+				continue
+			}
+			switch t.Name.Local {
+			case "r":
+				inRun = false
+			case "t":
+				inText = false
+			case "p":
+				b.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+	return normalizeExtractedText(b.String()), nil
+}
+
+// ---------------- ODT ----------------
+
+const odtTextNamespace = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+
+func extractODTText(data []byte) (string, error) {
+	dec, err := openZipXML(data, "content.xml")
+	if err != nil {
+		return "", fmt.Errorf("invalid odt: %w", err)
+	}
+
+	var b strings.Builder
+	paraDepth := 0
+	pendingBullet := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid odt: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "annotation" || (t.Name.Space == odtTextNamespace && (t.Name.Local == "note" || t.Name.Local == "tracked-changes")) {
+				if err := dec.Skip(); err != nil {
+					return "", fmt.Errorf("invalid odt: %w", err)
+				}
+				continue
+			}
+			if t.Name.Space != odtTextNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "list-item":
+				pendingBullet = true
+			case "p", "h":
+				paraDepth++
+				if pendingBullet {
+					b.WriteString("• ")
+					pendingBullet = false
+				}
+			case "s":
+				n := 1
+				for _, a := range t.Attr {
+					if a.Name.Local == "c" {
+						if v, err := strconv.Atoi(a.Value); err == nil && v > 0 {
+							n = v
+						}
+					}
+				}
+				b.WriteString(strings.Repeat(" ", n))
+			case "tab":
+				b.WriteByte('\t')
+			case "line-break":
+				b.WriteByte('\n')
+			}
+		case xml.EndElement:
+			if t.Name.Space == odtTextNamespace && (t.Name.Local == "p" || t.Name.Local == "h") {
+				paraDepth--
+				b.WriteByte('\n')
+			}
+		case xml.CharData:
+			if paraDepth > 0 {
+				b.Write(t)
+			}
+		}
+	}
+	return normalizeExtractedText(b.String()), nil
+}
+
+// ---------------- RTF ----------------
+
+// rtfSkipDestinations are groups that carry formatting or metadata rather than body text
+var rtfSkipDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true,
+	"object": true, "header": true, "headerl": true, "headerr": true, "headerf": true,
+	"footer": true, "footerl": true, "footerr": true, "footerf": true, "listtable": true,
+	"listoverridetable": true, "rsidtbl": true, "generator": true, "xmlnstbl": true,
+	"themedata": true, "colorschememapping": true, "datastore": true, "latentstyles": true,
+	"fldinst": true, "filetbl": true, "revtbl": true, "footnote": true,
+}
+
+var rtfControlText = map[string]string{
+	"par": "\n", "line": "\n", "sect": "\n", "page": "\n", "row": "\n",
+	"cell": "\t", "tab": "\t",
+	"emdash": "—", "endash": "–", "bullet": "•",
+	"lquote": "'", "rquote": "'", "ldblquote": "\"", "rdblquote": "\"",
+	"emspace": " ", "enspace": " ", "qmspace": " ",
+}
+
+func extractRTFText(data []byte) (string, error) {
+	type rtfGroup struct {
+		skip bool
+		uc   int // characters to skip after a \u escape
+	}
+
+	var b strings.Builder
+	stack := []rtfGroup{{uc: 1}}
+	skipChars := 0 // fallback characters still to drop after a \u escape
+	emit := func(s string) {
+		if skipChars > 0 {
+			skipChars--
+			return
+		}
+		if !stack[len(stack)-1].skip {
+			b.WriteString(s)
+		}
+	}
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '{':
+			stack = append(stack, stack[len(stack)-1])
+			skipChars = 0
+		case '}':
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+			skipChars = 0
+		case '\r', '\n':
+		case '\\':
+			if i+1 >= len(data) {
+				break
+			}
+			next := data[i+1]
+			switch {
+			case isASCIILetter(next):
+				j := i + 1
+				for j < len(data) && isASCIILetter(data[j]) {
+					j++
+				}
+				word := string(data[i+1 : j])
+				k := j
+				if k < len(data) && data[k] == '-' {
+					k++
+				}
+				for k < len(data) && data[k] >= '0' && data[k] <= '9' {
+					k++
+				}
+				param, hasParam := 0, k > j
+				if hasParam {
+					param, _ = strconv.Atoi(string(data[j:k]))
+				}
+				if k < len(data) && data[k] == ' ' {
+					k++ // the delimiting space belongs to the control word
+				}
+				i = k - 1
+
+				cur := &stack[len(stack)-1]
+				switch {
+				case rtfSkipDestinations[word]:
+					cur.skip = true
+				case word == "uc" && hasParam:
+					cur.uc = param
+				case word == "u" && hasParam:
+					if param < 0 {
+						param += 65536
+					}
+					emit(string(rune(param)))
+					skipChars = cur.uc
+				case word == "bin" && hasParam:
+					i += param
+				default:
+					if s, ok := rtfControlText[word]; ok {
+						emit(s)
+					}
+				}
+			case next == '*':
+				stack[len(stack)-1].skip = true // ignorable destination
+				i++
+			case next == '\'':
+				if i+3 < len(data) {
+					if v, err := strconv.ParseUint(string(data[i+2:i+4]), 16, 8); err == nil {
+						emit(string(decodeCP1252(byte(v))))
+					}
+				}
+				i += 3
+			case next == '\\' || next == '{' || next == '}':
+				emit(string(next))
+				i++
+			case next == '~':
+				emit(" ")
+				i++
+			case next == '_':
+				emit("-")
+				i++
+			case next == '\r' || next == '\n':
+				emit("\n")
+				i++
+			default:
+				i++ // other control symbols (e.g. \- optional hyphen) carry no text
+			}
+		default:
+			emit(string(decodeCP1252(c)))
+		}
+	}
+	return normalizeExtractedText(b.String()), nil
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// cp1252High maps the Windows-1252 bytes 0x80-0x9F that differ from Latin-1
+var cp1252High = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+func decodeCP1252(c byte) rune {
+	if c >= 0x80 && c <= 0x9F {
+		return cp1252High[c-0x80]
+	}
+	return rune(c)
+}
+
+// ---------------- Plain text / Markdown ----------------
+
+var (
+	mdHeadingLineRE = regexp.MustCompile(`(?m)^#{1,6}\s+\S`)
+	mdLinkRE        = regexp.MustCompile(`\[([^\]\n]+)\]\(([^)\s]+)\)`)
+)
+
+func extractPlainText(data []byte) (string, error) {
+	text := decodeText(data)
+	if mdHeadingLineRE.MatchString(text) {
+		text = mdLinkRE.ReplaceAllString(text, "$1 ($2)")
+		text = stripMarkdown(text)
+	}
+	return normalizeExtractedText(text), nil
+}
+
+// decodeText converts UTF-8 or BOM-marked UTF-16 bytes to a string
+func decodeText(data []byte) string {
+	var bigEndian bool
+	switch {
+	case bytes.HasPrefix(data, utf16LEBOM):
+		bigEndian = false
+	case bytes.HasPrefix(data, utf16BEBOM):
+		bigEndian = true
+	default:
+		return string(bytes.TrimPrefix(data, utf8BOM))
+	}
+
+	data = data[2:]
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		} else {
+			units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+var (
+	trailingSpaceRE = regexp.MustCompile(`(?m)[ \t]+$`)
+	blankRunRE      = regexp.MustCompile(`\n{3,}`)
+)
+
+// normalizeExtractedText unifies line endings and collapses runs of blank lines while
+// keeping single blank lines as paragraph separators.
+func normalizeExtractedText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	s = strings.ReplaceAll(s, "\f", "\n")
+	s = strings.ReplaceAll(s, " ", " ")
+	s = trailingSpaceRE.ReplaceAllString(s, "")
+	s = blankRunRE.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
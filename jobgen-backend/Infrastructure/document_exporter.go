@@ -0,0 +1,322 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	domain "jobgen-backend/Domain"
+)
+
+type documentExporter struct{}
+
+func NewDocumentExporter() domain.IDocumentExporter {
+	return &documentExporter{}
+}
+
+func (e *documentExporter) Export(baseName, title, content string, format domain.ExportFormat) (*domain.ExportedDocument, error) {
+	switch format {
+	case domain.ExportMarkdown:
+		md := content
+		if title != "" {
+			md = "# " + title + "\n\n" + content
+		}
+		return &domain.ExportedDocument{
+			FileName:    baseName + ".md",
+			ContentType: "text/markdown; charset=utf-8",
+			Data:        []byte(md),
+		}, nil
+	case domain.ExportPlainText:
+		txt := stripMarkdown(content)
+		if title != "" {
+			txt = title + "\n\n" + txt
+		}
+		return &domain.ExportedDocument{
+			FileName:    baseName + ".txt",
+			ContentType: "text/plain; charset=utf-8",
+			Data:        []byte(txt),
+		}, nil
+	case domain.ExportPDF:
+		return &domain.ExportedDocument{
+			FileName:    baseName + ".pdf",
+			ContentType: "application/pdf",
+			Data:        renderTextPDF(title, stripMarkdown(content)),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+var (
+	mdHeadingRE = regexp.MustCompile(`(?m)^#{1,6}\s+`)
+	mdBulletRE  = regexp.MustCompile(`(?m)^\s*[-*+]\s+`)
+)
+
+// mdEmphasisDelimiters are stripped in this order, so bold is handled before italics.
+var mdEmphasisDelimiters = []string{"**", "__", "*", "_", "`"}
+
+// stripMarkdown removes the light markup the AI tends to produce so the text reads cleanly.
+func stripMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = mdHeadingRE.ReplaceAllString(s, "")
+	s = mdBulletRE.ReplaceAllString(s, "- ")
+	for _, delim := range mdEmphasisDelimiters {
+		s = stripEmphasis(s, delim)
+	}
+	return strings.TrimSpace(s)
+}
+
+// stripEmphasis removes pairs of one delimiter that open and close on the same line at word
+// boundaries, so snake_case names, emails and URLs keep their underscores and asterisks.
+func stripEmphasis(s, delim string) string {
+	var out strings.Builder
+	pos := 0
+	for pos < len(s) {
+		open := strings.Index(s[pos:], delim)
+		if open < 0 {
+			break
+		}
+		open += pos
+		if !canOpenEmphasis(s, open, delim) {
+			out.WriteString(s[pos : open+len(delim)])
+			pos = open + len(delim)
+			continue
+		}
+		close := findEmphasisClose(s, open+len(delim), delim)
+		if close < 0 {
+			out.WriteString(s[pos : open+len(delim)])
+			pos = open + len(delim)
+			continue
+		}
+		out.WriteString(s[pos:open])
+		out.WriteString(s[open+len(delim) : close])
+		pos = close + len(delim)
+	}
+	out.WriteString(s[pos:])
+	return out.String()
+}
+
+// findEmphasisClose returns the index of the delimiter closing a span that starts at from, or
+// -1 if the line ends first.
+func findEmphasisClose(s string, from int, delim string) int {
+	end := strings.IndexByte(s[from:], '\n')
+	if end < 0 {
+		end = len(s)
+	} else {
+		end += from
+	}
+	for i := from; i < end; {
+		next := strings.Index(s[i:end], delim)
+		if next < 0 {
+			return -1
+		}
+		next += i
+		if next > from && canCloseEmphasis(s, next, delim) {
+			return next
+		}
+		i = next + 1
+	}
+	return -1
+}
+
+// canOpenEmphasis reports whether the delimiter at i starts a word and is followed by text.
+// Delimiters inside URLs and email addresses never open emphasis.
+func canOpenEmphasis(s string, i int, delim string) bool {
+	if inLinkToken(s, i) {
+		return false
+	}
+	if i > 0 {
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		if isWordRune(before) || before == rune(delim[0]) {
+			return false
+		}
+	}
+	after, size := utf8.DecodeRuneInString(s[i+len(delim):])
+	return size > 0 && !unicode.IsSpace(after) && after != rune(delim[0])
+}
+
+// canCloseEmphasis reports whether the delimiter at i follows text and ends a word.
+func canCloseEmphasis(s string, i int, delim string) bool {
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	if unicode.IsSpace(before) || before == rune(delim[0]) {
+		return false
+	}
+	after, size := utf8.DecodeRuneInString(s[i+len(delim):])
+	return size == 0 || (!isWordRune(after) && after != rune(delim[0]))
+}
+
+// inLinkToken reports whether the whitespace-separated token around i is a URL or an email.
+func inLinkToken(s string, i int) bool {
+	start := strings.LastIndexFunc(s[:i], unicode.IsSpace) + 1
+	end := strings.IndexFunc(s[i:], unicode.IsSpace)
+	if end < 0 {
+		end = len(s)
+	} else {
+		end += i
+	}
+	token := s[start:end]
+	return strings.Contains(token, "://") || strings.HasPrefix(token, "www.") || strings.Contains(token, "@")
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// ---------------- Minimal PDF writer ----------------
+// Renders plain text onto US Letter pages using the built-in Helvetica fonts, so no font
+// embedding or external library is needed. Characters outside WinAnsi are replaced.
+
+const (
+	pdfPageWidth   = 612
+	pdfPageHeight  = 792
+	pdfMargin      = 72
+	pdfBodySize    = 11
+	pdfTitleSize   = 14
+	pdfLeading     = 15
+	pdfCharsPerRow = 85 // Helvetica averages ~0.5em per glyph: (612-2*72)/(11*0.5)
+)
+
+func renderTextPDF(title, body string) []byte {
+	type pdfLine struct {
+		text string
+		bold bool
+	}
+
+	var lines []pdfLine
+	if title != "" {
+		for _, l := range wrapText(title, pdfCharsPerRow*pdfBodySize/pdfTitleSize) {
+			lines = append(lines, pdfLine{text: l, bold: true})
+		}
+		lines = append(lines, pdfLine{})
+	}
+	for _, para := range strings.Split(body, "\n") {
+		if strings.TrimSpace(para) == "" {
+			lines = append(lines, pdfLine{})
+			continue
+		}
+		for _, l := range wrapText(para, pdfCharsPerRow) {
+			lines = append(lines, pdfLine{text: l})
+		}
+	}
+
+	rowsPerPage := (pdfPageHeight - 2*pdfMargin) / pdfLeading
+	var pages [][]pdfLine
+	for len(lines) > 0 {
+		n := rowsPerPage
+		if n > len(lines) {
+			n = len(lines)
+		}
+		pages = append(pages, lines[:n])
+		lines = lines[n:]
+	}
+	if len(pages) == 0 {
+		pages = append(pages, nil)
+	}
+
+	var objects []string
+	// 1: catalog, 2: pages, 3: regular font, 4: bold font, then page/content pairs
+	objects = append(objects, "<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	objects = append(objects, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	objects = append(objects, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		var stream bytes.Buffer
+		stream.WriteString("BT\n")
+		fmt.Fprintf(&stream, "%d TL\n%d %d Td\n", pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, l := range page {
+			if l.bold {
+				fmt.Fprintf(&stream, "/F2 %d Tf\n", pdfTitleSize)
+			} else {
+				fmt.Fprintf(&stream, "/F1 %d Tf\n", pdfBodySize)
+			}
+			fmt.Fprintf(&stream, "(%s) Tj T*\n", pdfEscape(l.text))
+		}
+		stream.WriteString("ET")
+
+		objects = append(objects, fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", stream.Len(), stream.String()))
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// wrapText breaks a paragraph into rows of at most width characters on word boundaries.
+func wrapText(s string, width int) []string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return []string{""}
+	}
+	var rows []string
+	var cur strings.Builder
+	for _, w := range words {
+		for len([]rune(w)) > width { // hard-break very long tokens such as URLs
+			if cur.Len() > 0 {
+				rows = append(rows, cur.String())
+				cur.Reset()
+			}
+			r := []rune(w)
+			rows = append(rows, string(r[:width]))
+			w = string(r[width:])
+		}
+		if cur.Len() > 0 && len([]rune(cur.String()))+1+len([]rune(w)) > width {
+			rows = append(rows, cur.String())
+			cur.Reset()
+		}
+		if cur.Len() > 0 {
+			cur.WriteByte(' ')
+		}
+		cur.WriteString(w)
+	}
+	if cur.Len() > 0 {
+		rows = append(rows, cur.String())
+	}
+	return rows
+}
+
+var pdfRuneReplacer = strings.NewReplacer(
+	"‘", "'", "’", "'", "“", "\"", "”", "\"",
+	"–", "-", "—", "-", "…", "...", "•", "-",
+)
+
+// pdfEscape converts text to WinAnsi bytes and escapes PDF string delimiters.
+func pdfEscape(s string) string {
+	s = pdfRuneReplacer.Replace(s)
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
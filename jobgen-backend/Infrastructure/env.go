@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -43,7 +44,44 @@ type EnvConfig struct {
 	// AI (Gemini API)
 	GeminiAPIKey string
 	GeminiModel  string
-	GeminiRPM    int // requests per minute limit for SDK client
+	GeminiRPM    int // default for LLMRPM
+
+	// LLM backend: "gemini", "openai" (any OpenAI-compatible server), "scripted" or "none";
+	// empty picks one from the keys that are set
+	LLMProvider   string
+	LLMTimeout    time.Duration
+	LLMScriptFile string // JSON rules for the scripted provider
+	LLMRPM        int    // requests per minute shared by every AI feature; 0 disables the limit
+	LLMMaxRetries int    // retries of rate-limited, timed-out or failed AI calls
+	LLMCache      string // "memory", "redis" or "none"; empty uses Redis when it is connected
+	LLMCacheSize  int    // entries kept by the in-memory cache
+	LLMCacheTTLs  string // per-operation overrides, e.g. "job_suggestions=30m,cv_analysis=0"
+
+	// Per-user AI quotas by role, e.g. "daily_requests=200,monthly_tokens=5000000"; empty is unlimited
+	AIQuotaUser  string
+	AIQuotaAdmin string
+	// Prices per 1K tokens, used to estimate the cost of each user's AI usage
+	AIPricePrompt     float64
+	AIPriceCompletion float64
+
+	// Estimated tokens of recent messages sent with each chat message; older ones are summarized
+	ChatHistoryTokens int
+
+	OpenAIBaseURL string // e.g. http://localhost:11434/v1 for Ollama
+	OpenAIAPIKey  string
+	OpenAIModel   string
+
+	// CV parsing: "heuristic" (default) or "llm" to reconcile the parse with AI extraction
+	CVExtractionMode string
+
+	// Process role: "api", "worker" or "all"
+	ProcessRole string
+	WorkerPort  string // health and metrics port of a worker-only process
+
+	// CV worker pool
+	CVWorkerConcurrency int
+	CVJobTimeout        time.Duration // per attempt
+	ShutdownTimeout     time.Duration // time each shutdown stage (requests, CV jobs, webhooks) gets on SIGTERM
 }
 
 var Env EnvConfig
@@ -69,6 +107,47 @@ func LoadEnv() {
 	if err != nil || geminiRPM < 0 {
 		geminiRPM = 30
 	}
+	llmTimeout, err := time.ParseDuration(getEnv("LLM_TIMEOUT", "60s"))
+	if err != nil || llmTimeout <= 0 {
+		llmTimeout = 60 * time.Second
+	}
+	llmRPM, err := strconv.Atoi(getEnv("LLM_RPM", strconv.Itoa(geminiRPM)))
+	if err != nil || llmRPM < 0 {
+		llmRPM = geminiRPM
+	}
+	llmMaxRetries, err := strconv.Atoi(getEnv("LLM_MAX_RETRIES", "2"))
+	if err != nil || llmMaxRetries < 0 {
+		llmMaxRetries = 2
+	}
+	llmCacheSize, err := strconv.Atoi(getEnv("LLM_CACHE_SIZE", "1000"))
+	if err != nil || llmCacheSize < 1 {
+		llmCacheSize = 1000
+	}
+	aiPricePrompt, err := strconv.ParseFloat(getEnv("AI_PRICE_PROMPT_PER_1K", "0"), 64)
+	if err != nil || aiPricePrompt < 0 {
+		aiPricePrompt = 0
+	}
+	aiPriceCompletion, err := strconv.ParseFloat(getEnv("AI_PRICE_COMPLETION_PER_1K", "0"), 64)
+	if err != nil || aiPriceCompletion < 0 {
+		aiPriceCompletion = 0
+	}
+	chatHistoryTokens, err := strconv.Atoi(getEnv("CHAT_HISTORY_TOKENS", "3000"))
+	if err != nil || chatHistoryTokens < 1 {
+		chatHistoryTokens = 3000
+	}
+	// CV worker pool
+	workerConcurrency, err := strconv.Atoi(getEnv("CV_WORKER_CONCURRENCY", "4"))
+	if err != nil || workerConcurrency < 1 {
+		workerConcurrency = 4
+	}
+	jobTimeout, err := time.ParseDuration(getEnv("CV_JOB_TIMEOUT", "3m"))
+	if err != nil || jobTimeout <= 0 {
+		jobTimeout = 3 * time.Minute
+	}
+	shutdownTimeout, err := time.ParseDuration(getEnv("SHUTDOWN_TIMEOUT", "30s"))
+	if err != nil || shutdownTimeout <= 0 {
+		shutdownTimeout = 30 * time.Second
+	}
 	Env = EnvConfig{
 		MongoDBURI:           getEnv("MONGODB_URI", "mongodb://localhost:27017"),
 		DBName:               getEnv("DB_NAME", "jobgen"),
@@ -92,6 +171,28 @@ func LoadEnv() {
 		GeminiAPIKey:         getEnv("GEMINI_API_KEY", ""),
 		GeminiModel:          getEnv("GEMINI_MODEL", "gemini-1.5-pro"),
 		GeminiRPM:            geminiRPM,
+		LLMProvider:          getEnv("LLM_PROVIDER", ""),
+		LLMTimeout:           llmTimeout,
+		LLMScriptFile:        getEnv("LLM_SCRIPT_FILE", ""),
+		LLMRPM:               llmRPM,
+		LLMMaxRetries:        llmMaxRetries,
+		LLMCache:             getEnv("LLM_CACHE", ""),
+		LLMCacheSize:         llmCacheSize,
+		LLMCacheTTLs:         getEnv("LLM_CACHE_TTLS", ""),
+		AIQuotaUser:          getEnv("AI_QUOTA_USER", "daily_requests=200,daily_tokens=500000,monthly_tokens=5000000"),
+		AIQuotaAdmin:         getEnv("AI_QUOTA_ADMIN", ""),
+		AIPricePrompt:        aiPricePrompt,
+		AIPriceCompletion:    aiPriceCompletion,
+		ChatHistoryTokens:    chatHistoryTokens,
+		OpenAIBaseURL:        getEnv("OPENAI_BASE_URL", ""),
+		OpenAIAPIKey:         getEnv("OPENAI_API_KEY", ""),
+		OpenAIModel:          getEnv("OPENAI_MODEL", "gpt-4o-mini"),
+		CVExtractionMode:     getEnv("CV_EXTRACTION_MODE", "heuristic"),
+		ProcessRole:          getEnv("PROCESS_ROLE", "all"),
+		WorkerPort:           getEnv("WORKER_PORT", "8090"),
+		CVWorkerConcurrency:  workerConcurrency,
+		CVJobTimeout:         jobTimeout,
+		ShutdownTimeout:      shutdownTimeout,
 	}
 
 	// Validate required environment variables
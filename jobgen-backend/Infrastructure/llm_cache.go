@@ -0,0 +1,107 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// LLMCache stores model responses by content-addressed key. Get reports a miss with ok=false.
+type LLMCache interface {
+	Get(ctx context.Context, key string) (resp *LLMResponse, ok bool, err error)
+	Set(ctx context.Context, key string, resp *LLMResponse, ttl time.Duration) error
+}
+
+// DefaultLLMCacheTTLs is how long responses are reused per operation. Operations that are
+// expected to vary between calls (conversation, cover letters, tailoring) are not cached.
+var DefaultLLMCacheTTLs = map[string]time.Duration{
+	"chat_title":      24 * time.Hour,
+	"cv_analysis":     24 * time.Hour,
+	"cv_suggestions":  24 * time.Hour,
+	"cv_extraction":   24 * time.Hour,
+	"cv_improvement":  time.Hour,
+	"job_suggestions": 6 * time.Hour,
+}
+
+// ParseLLMCacheTTLs reads overrides such as "job_suggestions=30m,chat_title=0" on top of the defaults.
+func ParseLLMCacheTTLs(spec string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration, len(DefaultLLMCacheTTLs))
+	for op, ttl := range DefaultLLMCacheTTLs {
+		ttls[op] = ttl
+	}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		op, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("cache ttl %q must look like operation=duration", entry)
+		}
+		ttl, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("cache ttl for %s: invalid duration %q", op, value)
+		}
+		ttls[strings.TrimSpace(op)] = ttl
+	}
+	return ttls, nil
+}
+
+// llmCacheKey hashes everything that determines a response: the provider and model, the
+// system prompt, the output mode and every message of the conversation.
+func llmCacheKey(provider string, req LLMRequest) string {
+	h := sha256.New()
+	write := func(s string) {
+		// Length-prefixed so field boundaries cannot be forged by the content
+		h.Write([]byte(strconv.Itoa(len(s)) + ":" + s))
+	}
+	write(provider)
+	write(req.System)
+	write(strconv.FormatBool(req.JSON))
+	for _, msg := range req.Messages {
+		write(msg.Role)
+		write(msg.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// redisLLMCache shares cached responses between every API and worker process.
+type redisLLMCache struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewLLMCache(client *redis.Client, keyPrefix string) LLMCache {
+	return &redisLLMCache{client: client, prefix: keyPrefix}
+}
+
+func (c *redisLLMCache) Get(ctx context.Context, key string) (*LLMResponse, bool, error) {
+	data, err := c.client.Get(ctx, c.prefix+":"+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var resp LLMResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, false, err
+	}
+	return &resp, true, nil
+}
+
+func (c *redisLLMCache) Set(ctx context.Context, key string, resp *LLMResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.prefix+":"+key, data, ttl).Err()
+}
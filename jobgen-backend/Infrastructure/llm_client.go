@@ -0,0 +1,457 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	domain "jobgen-backend/Domain"
+
+	"golang.org/x/time/rate"
+)
+
+// LLMErrorKind classifies a failed model call.
+type LLMErrorKind string
+
+const (
+	LLMRateLimited   LLMErrorKind = "rate_limited"   // HTTP 429 or quota exhausted
+	LLMTimeout       LLMErrorKind = "timeout"        // the attempt ran out of time
+	LLMUnavailable   LLMErrorKind = "unavailable"    // 5xx or network failure
+	LLMNotConfigured LLMErrorKind = "not_configured" // no provider set up
+	LLMRejected      LLMErrorKind = "rejected"       // any other 4xx: bad key, bad request, blocked prompt
+	LLMBadOutput     LLMErrorKind = "bad_output"     // the response could not be used
+)
+
+// LLMError is the error every AI call fails with. Transient kinds match
+// domain.ErrServiceUnavailable, as does a missing provider.
+type LLMError struct {
+	Kind      LLMErrorKind
+	Operation string
+	Err       error
+}
+
+func (e *LLMError) Error() string {
+	return fmt.Sprintf("ai %s %s: %v", e.Operation, e.Kind, e.Err)
+}
+
+func (e *LLMError) Unwrap() error { return e.Err }
+
+func (e *LLMError) Is(target error) bool {
+	return target == domain.ErrServiceUnavailable && (e.Transient() || e.Kind == LLMNotConfigured)
+}
+
+// Transient reports whether trying again later may succeed.
+func (e *LLMError) Transient() bool {
+	return e.Kind == LLMRateLimited || e.Kind == LLMTimeout || e.Kind == LLMUnavailable
+}
+
+// IsTransientLLMError reports whether err is an AI failure worth retrying.
+func IsTransientLLMError(err error) bool {
+	var llmErr *LLMError
+	return errors.As(err, &llmErr) && llmErr.Transient()
+}
+
+var httpStatusPattern = regexp.MustCompile(`http (\d{3})`)
+
+// classifyLLMError sorts a provider error into an LLMErrorKind, using the HTTP status where
+// the provider exposes one.
+func classifyLLMError(operation string, err error) *LLMError {
+	var llmErr *LLMError
+	if errors.As(err, &llmErr) {
+		return llmErr
+	}
+	wrap := func(kind LLMErrorKind) *LLMError { return &LLMError{Kind: kind, Operation: operation, Err: err} }
+
+	if errors.Is(err, ErrLLMNotConfigured) {
+		return wrap(LLMNotConfigured)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return wrap(LLMTimeout)
+	}
+
+	status := 0
+	var coded interface{ HTTPCode() int } // SDK API errors
+	if errors.As(err, &coded) {
+		status = coded.HTTPCode()
+	} else if m := httpStatusPattern.FindStringSubmatch(strings.ToLower(err.Error())); m != nil {
+		status, _ = strconv.Atoi(m[1])
+	}
+	switch {
+	case status == 429:
+		return wrap(LLMRateLimited)
+	case status == 408:
+		return wrap(LLMTimeout)
+	case status >= 500:
+		return wrap(LLMUnavailable)
+	case status >= 400:
+		return wrap(LLMRejected)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return wrap(LLMTimeout)
+		}
+		return wrap(LLMUnavailable)
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return wrap(LLMUnavailable)
+	}
+	msg := strings.ToLower(err.Error())
+	for _, h := range []struct {
+		hint string
+		kind LLMErrorKind
+	}{
+		{"resourceexhausted", LLMRateLimited},
+		{"quota", LLMRateLimited},
+		{"deadlineexceeded", LLMTimeout},
+		{"timeout", LLMTimeout},
+		{"code = unavailable", LLMUnavailable},
+		{"connection", LLMUnavailable},
+	} {
+		if strings.Contains(msg, h.hint) {
+			return wrap(h.kind)
+		}
+	}
+	// Empty or unusable responses
+	return wrap(LLMBadOutput)
+}
+
+// LLMClientOptions tunes the shared AI client.
+type LLMClientOptions struct {
+	RequestsPerMinute int           // 0 disables rate limiting
+	Timeout           time.Duration // per attempt
+	MaxRetries        int           // retries of transient failures
+	RetryBackoff      time.Duration // delay before the first retry; doubles per retry
+
+	Cache     LLMCache                 // nil disables caching
+	CacheTTLs map[string]time.Duration // per operation; operations without a TTL are not cached
+
+	Meter domain.IAIUsageMeter // per-user quotas and accounting; nil disables them
+}
+
+// LLMClient is the single entry point to the model for every AI feature. It applies the
+// rate limit, per-attempt timeout and retries, classifies failures and counts tokens, per
+// operation and per user for calls made with domain.WithAIUser.
+type LLMClient struct {
+	provider LLMProvider
+	limiter  *rate.Limiter // may be nil when disabled
+	opts     LLMClientOptions
+	since    time.Time
+
+	mu       sync.Mutex
+	usage    map[string]*domain.AIUsage // by operation
+	inflight map[string]*inflightLLMCall
+}
+
+// inflightLLMCall is a cacheable request being sent to the model; the request that started
+// it and identical ones that arrive meanwhile wait for its result.
+type inflightLLMCall struct {
+	done  chan struct{}
+	resp  *LLMResponse
+	err   error
+	store sync.Once // the first waiter that accepts the response caches it
+}
+
+func NewLLMClient(provider LLMProvider, opts LLMClientOptions) *LLMClient {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 60 * time.Second
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = time.Second
+	}
+	var limiter *rate.Limiter
+	if rpm := opts.RequestsPerMinute; rpm > 0 {
+		// average rpm with burst = rpm
+		limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(rpm)), rpm)
+	}
+	return &LLMClient{
+		provider: provider,
+		limiter:  limiter,
+		opts:     opts,
+		since:    time.Now().UTC(),
+		usage:    make(map[string]*domain.AIUsage),
+		inflight: make(map[string]*inflightLLMCall),
+	}
+}
+
+func (c *LLMClient) Name() string { return c.provider.Name() }
+
+// Available reports whether a provider is configured.
+func (c *LLMClient) Available() bool { return LLMAvailable(c.provider) }
+
+// Generate runs one request for the named operation. Failures are returned as *LLMError,
+// except that a user over quota gets the meter's *domain.AIQuotaError. For cached operations
+// a stored response is returned when there is one, and identical concurrent requests share a
+// single model call.
+func (c *LLMClient) Generate(ctx context.Context, operation string, req LLMRequest) (*LLMResponse, error) {
+	return c.GenerateChecked(ctx, operation, req, nil)
+}
+
+// GenerateChecked is Generate for output the caller still has to check: only responses that
+// validate accepts are cached, and a cached response it rejects counts as a miss. Rejected
+// responses are still returned so the caller can handle them.
+func (c *LLMClient) GenerateChecked(ctx context.Context, operation string, req LLMRequest, validate func(text string) error) (*LLMResponse, error) {
+	ttl := c.opts.CacheTTLs[operation]
+	cacheable := c.opts.Cache != nil && ttl > 0
+	accepted := func(resp *LLMResponse) bool { return validate == nil || validate(resp.Text) == nil }
+
+	var key string
+	if cacheable {
+		key = llmCacheKey(c.provider.Name(), req)
+		if resp, ok, err := c.opts.Cache.Get(ctx, key); err != nil {
+			log.Printf("🟠 AI cache read failed: %v", err)
+		} else if ok && accepted(resp) {
+			c.account(ctx, operation, domain.AIUsage{CacheHits: 1})
+			return resp, nil
+		}
+	}
+
+	if err := c.allow(ctx); err != nil {
+		return nil, err
+	}
+	if !cacheable {
+		return c.call(ctx, operation, req)
+	}
+
+	c.mu.Lock()
+	pending, shared := c.inflight[key]
+	if !shared {
+		pending = &inflightLLMCall{done: make(chan struct{})}
+		c.inflight[key] = pending
+		// The call outlives the caller that started it, since others may be waiting on it
+		go c.callShared(context.WithoutCancel(ctx), operation, req, key, pending)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-pending.done:
+	case <-ctx.Done():
+		return nil, &LLMError{Kind: LLMTimeout, Operation: operation, Err: ctx.Err()}
+	}
+	if shared {
+		c.account(ctx, operation, domain.AIUsage{Deduplicated: 1})
+	}
+	if pending.err == nil && accepted(pending.resp) {
+		pending.store.Do(func() {
+			if err := c.opts.Cache.Set(context.WithoutCancel(ctx), key, pending.resp, ttl); err != nil {
+				log.Printf("🟠 AI cache write failed: %v", err)
+			}
+		})
+	}
+	return pending.resp, pending.err
+}
+
+// callShared makes a call that identical requests wait on. It does not depend on any one
+// caller's context, so a caller giving up does not fail the others; instead it runs under
+// its own deadline, long enough for every attempt and backoff.
+func (c *LLMClient) callShared(ctx context.Context, operation string, req LLMRequest, key string, pending *inflightLLMCall) {
+	limit := c.opts.Timeout
+	for i, backoff := 0, c.opts.RetryBackoff; i < c.opts.MaxRetries; i, backoff = i+1, backoff*2 {
+		limit += backoff + c.opts.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+
+	pending.resp, pending.err = c.call(ctx, operation, req)
+	c.mu.Lock()
+	delete(c.inflight, key)
+	c.mu.Unlock()
+	close(pending.done)
+}
+
+// allow checks the quota of the user the call is made for. Calls without a user, such as
+// background jobs, are not limited; if the quota cannot be checked the call goes ahead.
+func (c *LLMClient) allow(ctx context.Context) error {
+	userID := domain.AIUserFromContext(ctx)
+	if c.opts.Meter == nil || userID == "" {
+		return nil
+	}
+	err := c.opts.Meter.Allow(ctx, userID)
+	if errors.Is(err, domain.ErrAIQuotaExceeded) {
+		return err
+	}
+	if err != nil {
+		log.Printf("🟠 AI quota check for user %s failed: %v", userID, err)
+	}
+	return nil
+}
+
+// call sends the request to the provider, retrying transient failures.
+func (c *LLMClient) call(ctx context.Context, operation string, req LLMRequest) (*LLMResponse, error) {
+	backoff := c.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				c.record(ctx, operation, nil, attempt, true)
+				return nil, &LLMError{Kind: LLMRateLimited, Operation: operation, Err: fmt.Errorf("rate limit exceeded: %w", err)}
+			}
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+		resp, err := c.provider.Generate(attemptCtx, req)
+		cancel()
+		if err == nil {
+			c.record(ctx, operation, resp, attempt, false)
+			return resp, nil
+		}
+
+		llmErr := classifyLLMError(operation, err)
+		if !llmErr.Transient() || attempt >= c.opts.MaxRetries || ctx.Err() != nil {
+			c.record(ctx, operation, nil, attempt, true)
+			return nil, llmErr
+		}
+		log.Printf("🟠 AI %s failed (%s), retrying in %s: %v", operation, llmErr.Kind, backoff, err)
+		select {
+		case <-ctx.Done():
+			c.record(ctx, operation, nil, attempt, true)
+			return nil, llmErr
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// GenerateStream runs one request, passing the text to onDelta as the model generates it.
+// Providers that cannot stream answer in one piece. Streams are never cached, and a failed
+// attempt is only retried while nothing has been sent. An error returned by onDelta ends
+// the stream and is returned unwrapped.
+func (c *LLMClient) GenerateStream(ctx context.Context, operation string, req LLMRequest, onDelta func(delta string) error) (*LLMResponse, error) {
+	if err := c.allow(ctx); err != nil {
+		return nil, err
+	}
+	streamer, ok := c.provider.(LLMStreamingProvider)
+	if !ok {
+		resp, err := c.call(ctx, operation, req)
+		if err != nil {
+			return nil, err
+		}
+		if err := onDelta(resp.Text); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	var deltaErr error
+	sent := false
+	relay := func(delta string) error {
+		sent = true
+		if err := onDelta(delta); err != nil {
+			deltaErr = err
+			return err
+		}
+		return nil
+	}
+
+	backoff := c.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				c.record(ctx, operation, nil, attempt, true)
+				return nil, &LLMError{Kind: LLMRateLimited, Operation: operation, Err: fmt.Errorf("rate limit exceeded: %w", err)}
+			}
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+		resp, err := streamer.GenerateStream(attemptCtx, req, relay)
+		cancel()
+		if err == nil {
+			c.record(ctx, operation, resp, attempt, false)
+			return resp, nil
+		}
+		if deltaErr != nil {
+			c.record(ctx, operation, nil, attempt, true)
+			return nil, deltaErr
+		}
+
+		llmErr := classifyLLMError(operation, err)
+		if sent || !llmErr.Transient() || attempt >= c.opts.MaxRetries || ctx.Err() != nil {
+			c.record(ctx, operation, nil, attempt, true)
+			return nil, llmErr
+		}
+		log.Printf("🟠 AI %s stream failed (%s), retrying in %s: %v", operation, llmErr.Kind, backoff, err)
+		select {
+		case <-ctx.Done():
+			c.record(ctx, operation, nil, attempt, true)
+			return nil, llmErr
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// GenerateText is Generate for callers that only need the text.
+func (c *LLMClient) GenerateText(ctx context.Context, operation string, req LLMRequest) (string, error) {
+	return c.GenerateCheckedText(ctx, operation, req, nil)
+}
+
+// GenerateCheckedText is GenerateChecked for callers that only need the text.
+func (c *LLMClient) GenerateCheckedText(ctx context.Context, operation string, req LLMRequest, validate func(text string) error) (string, error) {
+	resp, err := c.GenerateChecked(ctx, operation, req, validate)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+func (c *LLMClient) record(ctx context.Context, operation string, resp *LLMResponse, retries int, failed bool) {
+	usage := domain.AIUsage{Requests: 1, Retries: int64(retries)}
+	if failed {
+		usage.Failures = 1
+	}
+	if resp != nil {
+		usage.PromptTokens = int64(resp.Usage.PromptTokens)
+		usage.CompletionTokens = int64(resp.Usage.CompletionTokens)
+	}
+	c.account(ctx, operation, usage)
+}
+
+// account adds usage to the operation's totals and to those of the user the call was made
+// for. Requests answered from the cache or by an identical in-flight request are counted
+// too, with no tokens.
+func (c *LLMClient) account(ctx context.Context, operation string, usage domain.AIUsage) {
+	c.mu.Lock()
+	u := c.usage[operation]
+	if u == nil {
+		u = &domain.AIUsage{}
+		c.usage[operation] = u
+	}
+	u.Add(usage)
+	c.mu.Unlock()
+
+	userID := domain.AIUserFromContext(ctx)
+	if c.opts.Meter == nil || userID == "" {
+		return
+	}
+	// Record even when the caller has given up; the tokens were spent
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := c.opts.Meter.Record(recordCtx, userID, operation, usage); err != nil {
+		log.Printf("🟠 AI usage for user %s not recorded: %v", userID, err)
+	}
+}
+
+// Usage returns the client's accounting since it was created.
+func (c *LLMClient) Usage() domain.AIUsageStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := domain.AIUsageStats{
+		Provider:   c.provider.Name(),
+		Available:  c.Available(),
+		Since:      c.since,
+		Operations: make(map[string]domain.AIUsage, len(c.usage)),
+	}
+	for op, u := range c.usage {
+		stats.Operations[op] = *u
+		stats.Total.Add(*u)
+	}
+	return stats
+}
@@ -0,0 +1,374 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// geminiProvider calls Gemini through the SDK. When the configured model is not available
+// it tries the other common models, adopting the first that works, and finally the REST
+// v1beta endpoint, which exposes some models the SDK cannot reach.
+type geminiProvider struct {
+	apiKey     string
+	client     *genai.Client
+	httpClient *http.Client
+	configured string // model from the configuration, tried first on the REST fallback
+
+	mu    sync.Mutex
+	model string // model currently in use
+}
+
+func NewGeminiProvider(apiKey, model string, timeout time.Duration) (LLMProvider, error) {
+	modelName := normalizeModel(model)
+	// Log selected models and key length for diagnostics
+	log.Printf("Gemini init: sdkModel=%s restModel=%s apiKeyLen=%d", modelName, normalizeModelV1Beta(model), len(apiKey))
+
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, err
+	}
+	return &geminiProvider{
+		apiKey:     apiKey,
+		client:     client,
+		httpClient: &http.Client{Timeout: timeout},
+		configured: model,
+		model:      modelName,
+	}, nil
+}
+
+func (p *geminiProvider) Name() string {
+	return "gemini/" + p.currentModel()
+}
+
+func (p *geminiProvider) currentModel() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.model
+}
+
+func (p *geminiProvider) Generate(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	if len(req.Messages) == 0 {
+		return nil, errors.New("llm request has no messages")
+	}
+
+	current := p.currentModel()
+	resp, err := p.generateSDK(ctx, current, req)
+	if err == nil || !isModelNotFound(err) {
+		return resp, err
+	}
+
+	// Fallback models via SDK
+	for _, name := range []string{p.configured, "gemini-1.5-flash", "gemini-1.5-flash-8b", "gemini-1.5-pro"} {
+		name = normalizeModel(name)
+		if name == current {
+			continue
+		}
+		resp, err = p.generateSDK(ctx, name, req)
+		if err == nil {
+			// adopt working model
+			p.mu.Lock()
+			p.model = name
+			p.mu.Unlock()
+			return resp, nil
+		}
+		if !isModelNotFound(err) {
+			return nil, err
+		}
+	}
+
+	// As final fallback, call REST v1beta
+	resp, restErr := p.generateREST(ctx, req)
+	if restErr == nil {
+		return resp, nil
+	}
+	return nil, fmt.Errorf("sdk fallback error: %v; rest error: %v", err, restErr)
+}
+
+func applyDefaultGenConfig(m *genai.GenerativeModel) {
+	// genai.GenerationConfig is a value type with pointer fields
+	t := float32(0.7)
+	tp := float32(0.95)
+	tk := int32(40)
+	max := int32(2048)
+	m.GenerationConfig = genai.GenerationConfig{
+		Temperature:     &t,
+		TopP:            &tp,
+		TopK:            &tk,
+		MaxOutputTokens: &max,
+	}
+}
+
+func isModelNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	es := strings.ToLower(err.Error())
+	return strings.Contains(es, "404") ||
+		strings.Contains(es, "not found") ||
+		strings.Contains(es, "is not supported for")
+}
+
+// geminiRole maps our chat roles onto Gemini's, which calls the assistant "model".
+func geminiRole(role string) string {
+	if role == "assistant" {
+		return "model"
+	}
+	return "user"
+}
+
+// startChat prepares a chat session holding every message but the last, which is returned
+// to be sent.
+func (p *geminiProvider) startChat(modelName string, req LLMRequest) (*genai.ChatSession, genai.Part) {
+	m := p.client.GenerativeModel(modelName)
+	applyDefaultGenConfig(m)
+	if req.System != "" {
+		m.SystemInstruction = &genai.Content{Parts: []genai.Part{genai.Text(req.System)}}
+	}
+	if req.JSON {
+		m.GenerationConfig.ResponseMIMEType = "application/json"
+	}
+
+	cs := m.StartChat()
+	last := len(req.Messages) - 1
+	for _, msg := range req.Messages[:last] {
+		cs.History = append(cs.History, &genai.Content{Parts: []genai.Part{genai.Text(msg.Content)}, Role: geminiRole(msg.Role)})
+	}
+	return cs, genai.Text(req.Messages[last].Content)
+}
+
+func (p *geminiProvider) generateSDK(ctx context.Context, modelName string, req LLMRequest) (*LLMResponse, error) {
+	cs, last := p.startChat(modelName, req)
+	result, err := cs.SendMessage(ctx, last)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Candidates) == 0 || result.Candidates[0].Content == nil || len(result.Candidates[0].Content.Parts) == 0 {
+		return nil, errors.New("gemini returned no candidates")
+	}
+
+	var text strings.Builder
+	for _, part := range result.Candidates[0].Content.Parts {
+		if t, ok := part.(genai.Text); ok {
+			text.WriteString(string(t))
+		}
+	}
+	resp := &LLMResponse{Text: text.String()}
+	if result.UsageMetadata != nil {
+		resp.Usage = LLMUsage{
+			PromptTokens:     int(result.UsageMetadata.PromptTokenCount),
+			CompletionTokens: int(result.UsageMetadata.CandidatesTokenCount),
+		}
+	}
+	return resp, nil
+}
+
+// GenerateStream streams from the model in use. When that model is not available the
+// completion is made with Generate, which tries the fallback models, and sent in one piece.
+func (p *geminiProvider) GenerateStream(ctx context.Context, req LLMRequest, onDelta func(delta string) error) (*LLMResponse, error) {
+	if len(req.Messages) == 0 {
+		return nil, errors.New("llm request has no messages")
+	}
+
+	sent := false
+	resp, err := p.generateSDKStream(ctx, p.currentModel(), req, func(delta string) error {
+		sent = true
+		return onDelta(delta)
+	})
+	if err == nil || sent || !isModelNotFound(err) {
+		return resp, err
+	}
+
+	resp, err = p.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := onDelta(resp.Text); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (p *geminiProvider) generateSDKStream(ctx context.Context, modelName string, req LLMRequest, onDelta func(delta string) error) (*LLMResponse, error) {
+	cs, last := p.startChat(modelName, req)
+	iter := cs.SendMessageStream(ctx, last)
+
+	var text strings.Builder
+	resp := &LLMResponse{}
+	for {
+		chunk, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(chunk.Candidates) > 0 && chunk.Candidates[0].Content != nil {
+			for _, part := range chunk.Candidates[0].Content.Parts {
+				t, ok := part.(genai.Text)
+				if !ok || t == "" {
+					continue
+				}
+				text.WriteString(string(t))
+				if err := onDelta(string(t)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		// Every chunk carries the running totals
+		if chunk.UsageMetadata != nil {
+			resp.Usage = LLMUsage{
+				PromptTokens:     int(chunk.UsageMetadata.PromptTokenCount),
+				CompletionTokens: int(chunk.UsageMetadata.CandidatesTokenCount),
+			}
+		}
+	}
+	if text.Len() == 0 {
+		return nil, errors.New("gemini returned no candidates")
+	}
+	resp.Text = text.String()
+	return resp, nil
+}
+
+// Minimal REST schema for v1beta fallback
+type glPart struct {
+	Text string `json:"text"`
+}
+
+type glContent struct {
+	Role  string   `json:"role,omitempty"`
+	Parts []glPart `json:"parts"`
+}
+
+type glRequest struct {
+	Contents          []glContent `json:"contents"`
+	SystemInstruction *glContent  `json:"systemInstruction,omitempty"`
+	GenerationConfig  *struct {
+		ResponseMimeType string `json:"responseMimeType,omitempty"`
+	} `json:"generationConfig,omitempty"`
+}
+
+type glResponse struct {
+	Candidates []struct {
+		Content glContent `json:"content"`
+	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata,omitempty"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error,omitempty"`
+}
+
+func (p *geminiProvider) generateREST(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	model := p.configured
+	if strings.TrimSpace(model) == "" {
+		model = "gemini-1.5-flash"
+	}
+	base := normalizeModel(model)
+	candidates := []string{base}
+	if !strings.HasSuffix(base, "-latest") {
+		candidates = append(candidates, base+"-latest")
+	}
+
+	payload := glRequest{}
+	for _, msg := range req.Messages {
+		payload.Contents = append(payload.Contents, glContent{Role: geminiRole(msg.Role), Parts: []glPart{{Text: msg.Content}}})
+	}
+	if req.System != "" {
+		payload.SystemInstruction = &glContent{Parts: []glPart{{Text: req.System}}}
+	}
+	if req.JSON {
+		payload.GenerationConfig = &struct {
+			ResponseMimeType string `json:"responseMimeType,omitempty"`
+		}{ResponseMimeType: "application/json"}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, restModel := range candidates {
+		resp, err := p.postREST(ctx, restModel, body)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		// Continue to next candidate if model not found/unsupported
+		if !isModelNotFound(err) {
+			return nil, err
+		}
+	}
+	return nil, lastErr
+}
+
+func (p *geminiProvider) postREST(ctx context.Context, model string, body []byte) (*LLMResponse, error) {
+	endpoint := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", model, p.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		var er glResponse
+		_ = json.Unmarshal(bodyBytes, &er)
+		msg := strings.TrimSpace(string(bodyBytes))
+		if er.Error != nil && er.Error.Message != "" {
+			msg = er.Error.Message
+		}
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		if len(msg) > 300 {
+			msg = msg[:300]
+		}
+		log.Printf("Gemini REST v1beta error %d (%s): %s", resp.StatusCode, model, msg)
+		return nil, fmt.Errorf("gemini http %d: %s", resp.StatusCode, msg)
+	}
+
+	var gr glResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gr); err != nil {
+		return nil, err
+	}
+	if gr.Error != nil {
+		log.Printf("Gemini REST v1beta error (%s): %s", model, gr.Error.Message)
+		return nil, fmt.Errorf("gemini error: %s", gr.Error.Message)
+	}
+	if len(gr.Candidates) == 0 || len(gr.Candidates[0].Content.Parts) == 0 {
+		return nil, errors.New("gemini returned no candidates")
+	}
+
+	var text strings.Builder
+	for _, part := range gr.Candidates[0].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	out := &LLMResponse{Text: text.String()}
+	if gr.UsageMetadata != nil {
+		out.Usage = LLMUsage{PromptTokens: gr.UsageMetadata.PromptTokenCount, CompletionTokens: gr.UsageMetadata.CandidatesTokenCount}
+	}
+	return out, nil
+}
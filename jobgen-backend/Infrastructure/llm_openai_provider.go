@@ -0,0 +1,229 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// openAICompatibleProvider talks to any server implementing the OpenAI chat completions API:
+// OpenAI itself, or a local llama.cpp, Ollama or vLLM server.
+type openAICompatibleProvider struct {
+	baseURL    string // e.g. https://api.openai.com/v1 or http://localhost:11434/v1
+	apiKey     string // optional for local servers
+	model      string
+	httpClient *http.Client
+}
+
+func NewOpenAICompatibleProvider(baseURL, apiKey, model string, timeout time.Duration) LLMProvider {
+	if model == "" {
+		model = "gpt-4o-mini"
+	}
+	return &openAICompatibleProvider{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *openAICompatibleProvider) Name() string {
+	return "openai/" + p.model
+}
+
+type oaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type oaRequest struct {
+	Model          string      `json:"model"`
+	Messages       []oaMessage `json:"messages"`
+	Temperature    float64     `json:"temperature"`
+	MaxTokens      int         `json:"max_tokens,omitempty"`
+	ResponseFormat *struct {
+		Type string `json:"type"`
+	} `json:"response_format,omitempty"`
+	Stream        bool `json:"stream,omitempty"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options,omitempty"`
+}
+
+type oaResponse struct {
+	Choices []struct {
+		Message oaMessage `json:"message"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error,omitempty"`
+}
+
+func (p *openAICompatibleProvider) Generate(ctx context.Context, req LLMRequest) (*LLMResponse, error) {
+	resp, err := p.post(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var or oaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&or); err != nil {
+		return nil, err
+	}
+	if or.Error != nil {
+		return nil, fmt.Errorf("openai error: %s", or.Error.Message)
+	}
+	if len(or.Choices) == 0 {
+		return nil, errors.New("openai returned no choices")
+	}
+	out := &LLMResponse{Text: or.Choices[0].Message.Content}
+	if or.Usage != nil {
+		out.Usage = LLMUsage{PromptTokens: or.Usage.PromptTokens, CompletionTokens: or.Usage.CompletionTokens}
+	}
+	return out, nil
+}
+
+// oaStreamChunk is one server-sent event of a streamed completion.
+type oaStreamChunk struct {
+	Choices []struct {
+		Delta oaMessage `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// GenerateStream requests a streamed completion and relays each content delta. Servers that
+// do not report usage for streams leave the token counts at zero.
+func (p *openAICompatibleProvider) GenerateStream(ctx context.Context, req LLMRequest, onDelta func(delta string) error) (*LLMResponse, error) {
+	resp, err := p.post(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	out := &LLMResponse{}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "data:")
+		if !ok {
+			continue // blank separators, comments and other SSE fields
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk oaStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("openai stream: %w", err)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("openai error: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			out.Usage = LLMUsage{PromptTokens: chunk.Usage.PromptTokens, CompletionTokens: chunk.Usage.CompletionTokens}
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		delta := chunk.Choices[0].Delta.Content
+		text.WriteString(delta)
+		if err := onDelta(delta); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if text.Len() == 0 {
+		return nil, errors.New("openai returned no choices")
+	}
+	out.Text = text.String()
+	return out, nil
+}
+
+// post sends a chat completion request and returns the response once its status is known
+// to be successful. The caller closes the body.
+func (p *openAICompatibleProvider) post(ctx context.Context, req LLMRequest, stream bool) (*http.Response, error) {
+	if len(req.Messages) == 0 {
+		return nil, errors.New("llm request has no messages")
+	}
+
+	payload := oaRequest{Model: p.model, Temperature: 0.7, MaxTokens: 2048}
+	if req.System != "" {
+		payload.Messages = append(payload.Messages, oaMessage{Role: "system", Content: req.System})
+	}
+	for _, msg := range req.Messages {
+		role := "user"
+		if msg.Role == "assistant" {
+			role = "assistant"
+		}
+		payload.Messages = append(payload.Messages, oaMessage{Role: role, Content: msg.Content})
+	}
+	if req.JSON {
+		payload.ResponseFormat = &struct {
+			Type string `json:"type"`
+		}{Type: "json_object"}
+	}
+	if stream {
+		payload.Stream = true
+		payload.StreamOptions = &struct {
+			IncludeUsage bool `json:"include_usage"`
+		}{IncludeUsage: true}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		var er oaResponse
+		_ = json.Unmarshal(b, &er)
+		msg := strings.TrimSpace(string(b))
+		if er.Error != nil && er.Error.Message != "" {
+			msg = er.Error.Message
+		}
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		if len(msg) > 300 {
+			msg = msg[:300]
+		}
+		return nil, fmt.Errorf("openai http %d: %s", resp.StatusCode, msg)
+	}
+	return resp, nil
+}
@@ -0,0 +1,113 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	domain "jobgen-backend/Domain"
+)
+
+// LLMMessage is one turn of a conversation sent to a model.
+type LLMMessage struct {
+	Role    string // "user" or "assistant"
+	Content string
+}
+
+// LLMRequest is a provider-neutral completion request.
+type LLMRequest struct {
+	System   string // instructions sent ahead of the conversation, may be empty
+	Messages []LLMMessage
+	// JSON asks the model for a JSON response where the backend supports it; callers must
+	// still validate the output.
+	JSON bool
+}
+
+// LLMUsage is the token count a provider reported for one completion, zero when unknown.
+type LLMUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+type LLMResponse struct {
+	Text  string
+	Usage LLMUsage
+}
+
+// LLMProvider is a model backend the AI services generate text with.
+type LLMProvider interface {
+	// Name identifies the backend and model in logs and health checks, e.g. "gemini/gemini-1.5-pro".
+	Name() string
+	Generate(ctx context.Context, req LLMRequest) (*LLMResponse, error)
+}
+
+// LLMStreamingProvider is implemented by backends that can return a completion as it is
+// generated. onDelta receives each new piece of text in order; an error from it stops the
+// stream and is returned as is. The returned response holds the full text.
+type LLMStreamingProvider interface {
+	LLMProvider
+	GenerateStream(ctx context.Context, req LLMRequest, onDelta func(delta string) error) (*LLMResponse, error)
+}
+
+// ErrLLMNotConfigured is returned by every call while no provider is configured.
+var ErrLLMNotConfigured = fmt.Errorf("%w: no llm provider configured", domain.ErrServiceUnavailable)
+
+// userPrompt builds a single-turn request.
+func userPrompt(prompt string) LLMRequest {
+	return LLMRequest{Messages: []LLMMessage{{Role: "user", Content: prompt}}}
+}
+
+// NewLLMProvider builds the provider chosen by LLM_PROVIDER. Left empty, Gemini is used when
+// GEMINI_API_KEY is set and an OpenAI-compatible endpoint when OPENAI_BASE_URL or
+// OPENAI_API_KEY is. With nothing configured the returned provider fails every call with
+// ErrLLMNotConfigured so the server can still boot with AI features degraded.
+func NewLLMProvider() (LLMProvider, error) {
+	provider := strings.ToLower(strings.TrimSpace(Env.LLMProvider))
+	if provider == "" {
+		switch {
+		case Env.GeminiAPIKey != "":
+			provider = "gemini"
+		case Env.OpenAIBaseURL != "" || Env.OpenAIAPIKey != "":
+			provider = "openai"
+		default:
+			provider = "none"
+		}
+	}
+
+	switch provider {
+	case "gemini":
+		if Env.GeminiAPIKey == "" {
+			return nil, errors.New("LLM_PROVIDER=gemini requires GEMINI_API_KEY")
+		}
+		return NewGeminiProvider(Env.GeminiAPIKey, Env.GeminiModel, Env.LLMTimeout)
+	case "openai":
+		baseURL := Env.OpenAIBaseURL
+		if baseURL == "" {
+			baseURL = "https://api.openai.com/v1"
+		}
+		return NewOpenAICompatibleProvider(baseURL, Env.OpenAIAPIKey, Env.OpenAIModel, Env.LLMTimeout), nil
+	case "scripted":
+		return NewScriptedProviderFromFile(Env.LLMScriptFile)
+	case "none":
+		log.Printf("⚠️ No LLM provider configured; AI features are disabled")
+		return unavailableProvider{}, nil
+	}
+	return nil, fmt.Errorf("unknown LLM_PROVIDER %q, expected gemini, openai, scripted or none", provider)
+}
+
+// LLMAvailable reports whether the provider can serve requests at all.
+func LLMAvailable(p LLMProvider) bool {
+	_, unavailable := p.(unavailableProvider)
+	return p != nil && !unavailable
+}
+
+// unavailableProvider stands in when no backend is configured.
+type unavailableProvider struct{}
+
+func (unavailableProvider) Name() string { return "none" }
+
+func (unavailableProvider) Generate(context.Context, LLMRequest) (*LLMResponse, error) {
+	return nil, ErrLLMNotConfigured
+}
@@ -0,0 +1,103 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ScriptedRule answers every request whose prompt contains Match (case-insensitive; an empty
+// Match answers anything). A rule with Error set fails the call instead.
+type ScriptedRule struct {
+	Match string `json:"match"`
+	Reply string `json:"reply"`
+	Error string `json:"error,omitempty"`
+}
+
+// ScriptedProvider is a deterministic LLMProvider for tests and offline development. The first
+// matching rule answers; requests are recorded for assertions.
+type ScriptedProvider struct {
+	rules []ScriptedRule
+
+	mu    sync.Mutex
+	calls []LLMRequest
+}
+
+func NewScriptedProvider(rules ...ScriptedRule) *ScriptedProvider {
+	return &ScriptedProvider{rules: rules}
+}
+
+// NewScriptedProviderFromFile loads the rules from a JSON array of ScriptedRule.
+func NewScriptedProviderFromFile(path string) (LLMProvider, error) {
+	if path == "" {
+		return nil, errors.New("LLM_PROVIDER=scripted requires LLM_SCRIPT_FILE")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading llm script: %w", err)
+	}
+	var rules []ScriptedRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parsing llm script %s: %w", path, err)
+	}
+	return NewScriptedProvider(rules...), nil
+}
+
+func (p *ScriptedProvider) Name() string { return "scripted" }
+
+// GenerateStream answers like Generate, sending the reply one word at a time.
+func (p *ScriptedProvider) GenerateStream(ctx context.Context, req LLMRequest, onDelta func(delta string) error) (*LLMResponse, error) {
+	resp, err := p.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range strings.SplitAfter(resp.Text, " ") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := onDelta(word); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func (p *ScriptedProvider) Generate(_ context.Context, req LLMRequest) (*LLMResponse, error) {
+	p.mu.Lock()
+	p.calls = append(p.calls, req)
+	p.mu.Unlock()
+
+	var prompt strings.Builder
+	prompt.WriteString(req.System)
+	for _, msg := range req.Messages {
+		prompt.WriteString("\n")
+		prompt.WriteString(msg.Content)
+	}
+	text := strings.ToLower(prompt.String())
+
+	for _, rule := range p.rules {
+		if !strings.Contains(text, strings.ToLower(rule.Match)) {
+			continue
+		}
+		if rule.Error != "" {
+			return nil, errors.New(rule.Error)
+		}
+		return &LLMResponse{
+			Text: rule.Reply,
+			// Rough whitespace token counts keep usage accounting testable
+			Usage: LLMUsage{PromptTokens: len(strings.Fields(text)), CompletionTokens: len(strings.Fields(rule.Reply))},
+		}, nil
+	}
+	return nil, errors.New("no scripted reply matches the prompt")
+}
+
+// Calls returns the requests received so far.
+func (p *ScriptedProvider) Calls() []LLMRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]LLMRequest(nil), p.calls...)
+}
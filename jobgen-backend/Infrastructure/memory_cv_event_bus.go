@@ -0,0 +1,57 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+
+	domain "jobgen-backend/Domain"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind before new ones
+// are dropped for it.
+const subscriberBuffer = 16
+
+// inMemoryCVEventBus delivers events within a single process, matching the in-memory queue.
+type inMemoryCVEventBus struct {
+	mu   sync.Mutex
+	subs map[string]map[chan domain.CVJobEvent]struct{} // job ID -> subscriber channels
+}
+
+func NewInMemoryCVEventBus() CVEventBus {
+	return &inMemoryCVEventBus{subs: make(map[string]map[chan domain.CVJobEvent]struct{})}
+}
+
+func (b *inMemoryCVEventBus) Publish(event domain.CVJobEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[event.JobID] {
+		select {
+		case ch <- event:
+		default:
+			// Never block the worker on a client that stopped reading
+		}
+	}
+	return nil
+}
+
+func (b *inMemoryCVEventBus) Subscribe(ctx context.Context, jobID string) (<-chan domain.CVJobEvent, error) {
+	ch := make(chan domain.CVJobEvent, subscriberBuffer)
+	b.mu.Lock()
+	if b.subs[jobID] == nil {
+		b.subs[jobID] = make(map[chan domain.CVJobEvent]struct{})
+	}
+	b.subs[jobID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subs[jobID], ch)
+		if len(b.subs[jobID]) == 0 {
+			delete(b.subs, jobID)
+		}
+		b.mu.Unlock()
+		close(ch)
+	}()
+	return ch, nil
+}
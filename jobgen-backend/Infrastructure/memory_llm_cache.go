@@ -0,0 +1,66 @@
+package infrastructure
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// inMemoryLLMCache is a process-local LRU cache used when Redis is not available.
+type inMemoryLLMCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List               // front is most recently used
+	entries map[string]*list.Element // key -> element holding a *memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	key       string
+	resp      LLMResponse
+	expiresAt time.Time
+}
+
+func NewInMemoryLLMCache(maxEntries int) LLMCache {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &inMemoryLLMCache{maxEntries: maxEntries, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *inMemoryLLMCache) Get(_ context.Context, key string) (*LLMResponse, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := el.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(el)
+	resp := entry.resp
+	return &resp, true, nil
+}
+
+func (c *inMemoryLLMCache) Set(_ context.Context, key string, resp *LLMResponse, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &memoryCacheEntry{key: key, resp: *resp, expiresAt: time.Now().Add(ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+	return nil
+}
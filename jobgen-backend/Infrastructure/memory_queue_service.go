@@ -1,21 +1,47 @@
 package infrastructure
 
 import (
-	"errors"
+	"context"
+	"sync"
+	"time"
+
+	domain "jobgen-backend/Domain"
+
+	"github.com/google/uuid"
 )
 
 // inMemoryQueueService is a simple channel-backed queue used as a fallback
-// when Redis is not available. It satisfies the QueueService interface.
+// when Redis is not available. It satisfies the QueueService interface with the same
+// lease, retry and dead-letter semantics, but nothing survives a restart; callers should
+// re-enqueue unfinished jobs on startup.
 type inMemoryQueueService struct {
-	ch chan string
+	ch   chan string
+	opts QueueOptions
+
+	mu       sync.Mutex
+	attempts map[string]int
+	leases   map[string]memoryLease // job ID -> current lease
+	dead     map[string]domain.DeadLetteredJob
+}
+
+// memoryLease is a job's current lease and the timer that redelivers it on expiry.
+type memoryLease struct {
+	token string
+	timer *time.Timer
 }
 
 // NewInMemoryQueueService creates a new in-memory queue with a reasonable buffer.
-func NewInMemoryQueueService(buffer int) QueueService {
+func NewInMemoryQueueService(buffer int, opts QueueOptions) QueueService {
 	if buffer <= 0 {
 		buffer = 100
 	}
-	return &inMemoryQueueService{ch: make(chan string, buffer)}
+	return &inMemoryQueueService{
+		ch:       make(chan string, buffer),
+		opts:     opts.withDefaults(),
+		attempts: make(map[string]int),
+		leases:   make(map[string]memoryLease),
+		dead:     make(map[string]domain.DeadLetteredJob),
+	}
 }
 
 func (q *inMemoryQueueService) Enqueue(jobID string) error {
@@ -23,16 +49,116 @@ func (q *inMemoryQueueService) Enqueue(jobID string) error {
 	case q.ch <- jobID:
 		return nil
 	default:
-		// If buffer is full, try a blocking send to ensure delivery.
-		q.ch <- jobID
+		// If buffer is full, push in the background so callers are never blocked.
+		go func() { q.ch <- jobID }()
 		return nil
 	}
 }
 
-func (q *inMemoryQueueService) Dequeue() (string, error) {
-	jobID, ok := <-q.ch
+func (q *inMemoryQueueService) Dequeue(ctx context.Context) (*QueueDelivery, error) {
+	var jobID string
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case jobID = <-q.ch:
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.attempts[jobID]++
+	d := &QueueDelivery{JobID: jobID, Attempt: q.attempts[jobID], MaxAttempts: q.opts.MaxAttempts, lease: uuid.NewString()}
+	q.leases[jobID] = memoryLease{
+		token: d.lease,
+		timer: time.AfterFunc(q.opts.VisibilityTimeout, func() { q.expireLease(jobID, d.lease) }),
+	}
+	return d, nil
+}
+
+// expireLease redelivers a job whose worker neither acked nor released it in time.
+func (q *inMemoryQueueService) expireLease(jobID, token string) {
+	q.mu.Lock()
+	lease, leased := q.leases[jobID]
+	leased = leased && lease.token == token
+	if leased {
+		delete(q.leases, jobID)
+	}
+	q.mu.Unlock()
+	if leased {
+		q.Enqueue(jobID)
+	}
+}
+
+// releaseLocked ends a delivery's lease; it returns ErrLeaseLost when the lease had already
+// expired.
+func (q *inMemoryQueueService) releaseLocked(d *QueueDelivery) error {
+	lease, ok := q.leases[d.JobID]
+	if !ok || lease.token != d.lease {
+		return ErrLeaseLost
+	}
+	lease.timer.Stop()
+	delete(q.leases, d.JobID)
+	return nil
+}
+
+func (q *inMemoryQueueService) Ack(d *QueueDelivery) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.releaseLocked(d); err != nil {
+		return err
+	}
+	delete(q.attempts, d.JobID)
+	return nil
+}
+
+func (q *inMemoryQueueService) Retry(d *QueueDelivery) error {
+	q.mu.Lock()
+	err := q.releaseLocked(d)
+	q.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	time.AfterFunc(q.opts.backoff(d.Attempt), func() { q.Enqueue(d.JobID) })
+	return nil
+}
+
+func (q *inMemoryQueueService) DeadLetter(d *QueueDelivery, reason string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.releaseLocked(d); err != nil {
+		return err
+	}
+	q.deadLetterLocked(d, reason)
+	return nil
+}
+
+func (q *inMemoryQueueService) deadLetterLocked(d *QueueDelivery, reason string) {
+	delete(q.attempts, d.JobID)
+	q.dead[d.JobID] = domain.DeadLetteredJob{
+		JobID:    d.JobID,
+		Attempts: d.Attempt,
+		Reason:   reason,
+		FailedAt: time.Now().UTC(),
+	}
+}
+
+func (q *inMemoryQueueService) ListDeadLetters() ([]domain.DeadLetteredJob, error) {
+	q.mu.Lock()
+	jobs := make([]domain.DeadLetteredJob, 0, len(q.dead))
+	for _, job := range q.dead {
+		jobs = append(jobs, job)
+	}
+	q.mu.Unlock()
+	sortDeadLetters(jobs)
+	return jobs, nil
+}
+
+func (q *inMemoryQueueService) RequeueDeadLetter(jobID string) error {
+	q.mu.Lock()
+	_, ok := q.dead[jobID]
+	delete(q.dead, jobID)
+	q.mu.Unlock()
 	if !ok {
-		return "", errors.New("queue closed")
+		return domain.ErrNotFound
 	}
-	return jobID, nil
+	return q.Enqueue(jobID)
 }
@@ -51,8 +51,9 @@ func (s *minioCVFileStorage) UploadFile(userID, category, fileName string, file
 	if err != nil {
 		return "", err
 	}
-	key := buildObjectKey(userID, category)
-	contentType := "application/pdf"
+	format := DetectCVFormat(buf)
+	key := buildObjectKey(userID, category, format.Extension())
+	contentType := format.ContentType()
 
 	// Upload to MinIO
 	_, err = s.client.PutObject(context.Background(), s.bucket, key, bytes.NewReader(buf), int64(len(buf)), minio.PutObjectOptions{
@@ -100,14 +101,14 @@ func (s *minioCVFileStorage) decodeID(id string) (string, string, error) {
 	return parts[0], parts[1], nil
 }
 
-func buildObjectKey(userID, category string) string {
+func buildObjectKey(userID, category, ext string) string {
 	id := uuid.NewString()
 	prefix := "cv"
 	if category != "" {
 		prefix = strings.ToLower(category)
 	}
 	if userID != "" {
-		return fmt.Sprintf("%s/%s/%s.pdf", prefix, userID, id)
+		return fmt.Sprintf("%s/%s/%s%s", prefix, userID, id, ext)
 	}
-	return fmt.Sprintf("%s/%s.pdf", prefix, id)
+	return fmt.Sprintf("%s/%s%s", prefix, id, ext)
 }
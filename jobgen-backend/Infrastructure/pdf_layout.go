@@ -0,0 +1,476 @@
+package infrastructure
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	domain "jobgen-backend/Domain"
+
+	pdf "github.com/ledongthuc/pdf"
+)
+
+// Layout-aware PDF extraction. Glyph positions are grouped into baselines, baselines are
+// split into runs at large horizontal gaps, and vertical gutters that no run crosses are
+// taken as column boundaries. Text is then read column by column within horizontal bands,
+// so a sidebar is no longer interleaved with the main column. Headings are recognised by
+// font size or weight and bullets by their leading glyph.
+
+var errNoLayout = errors.New("pdf has no usable glyph positions")
+
+type pdfGlyph struct {
+	x, y, w, size float64
+	bold          bool
+	s             string
+}
+
+// pdfRun is a horizontally contiguous piece of text on one baseline
+type pdfRun struct {
+	x0, x1, size float64
+	bold         bool
+	text         string
+}
+
+type pdfBaseline struct {
+	y    float64
+	runs []pdfRun
+}
+
+// pdfTextLine is a line of text within one column, in reading order
+type pdfTextLine struct {
+	page, column int
+	x0, y, size  float64
+	bold         bool
+	text         string
+}
+
+type pdfGutter struct{ start, end float64 }
+
+const (
+	pdfRunGapEm       = 1.5  // horizontal gap, in ems, that ends a run
+	pdfWordGapEm      = 0.15 // horizontal gap, in ems, that implies a space
+	pdfMinGutter      = 12.0 // minimum gutter width in points
+	pdfMinColumnWidth = 60.0 // minimum column width in points
+	pdfLineGapEm      = 1.6  // baseline distance, in ems, still considered the same block
+	pdfHeadingScale   = 1.15 // font size ratio to body text that makes a heading
+	pdfMaxHeadingLen  = 60
+)
+
+func extractPDFDocument(data []byte) (*domain.CVDocument, error) {
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var pages [][]pdfGlyph
+	for i := 1; i <= r.NumPage(); i++ {
+		p := r.Page(i)
+		if p.V.IsNull() {
+			continue
+		}
+		glyphs, err := pageGlyphs(p)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, glyphs)
+	}
+	if !usableGlyphs(pages) {
+		return nil, errNoLayout
+	}
+
+	var lines []pdfTextLine
+	for i, glyphs := range pages {
+		lines = append(lines, layoutPage(i+1, glyphs)...)
+	}
+	return &domain.CVDocument{
+		Format:    string(CVFormatPDF),
+		HasLayout: true,
+		Blocks:    buildPDFBlocks(lines, bodyFontSize(pages)),
+	}, nil
+}
+
+// pageGlyphs reads the positioned glyphs of a page. The pdf package panics on content it
+// cannot interpret, so that is turned into an error.
+func pageGlyphs(p pdf.Page) (glyphs []pdfGlyph, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("reading page content: %v", rec)
+		}
+	}()
+	for _, t := range p.Content().Text {
+		if t.FontSize <= 0 || t.S == "" || t.S == "\n" {
+			continue
+		}
+		glyphs = append(glyphs, pdfGlyph{
+			x: t.X, y: t.Y, w: t.W, size: t.FontSize,
+			bold: isBoldFont(t.Font),
+			s:    t.S,
+		})
+	}
+	return glyphs, nil
+}
+
+// usableGlyphs rejects documents whose fonts carry no width information; without widths
+// every glyph of a string reports the same position and layout analysis is meaningless.
+func usableGlyphs(pages [][]pdfGlyph) bool {
+	total, zeroWidth := 0, 0
+	for _, glyphs := range pages {
+		for _, g := range glyphs {
+			if strings.TrimSpace(g.s) == "" {
+				continue
+			}
+			total++
+			if g.w <= 0 {
+				zeroWidth++
+			}
+		}
+	}
+	return total > 0 && zeroWidth*2 < total
+}
+
+func isBoldFont(name string) bool {
+	n := strings.ToLower(name)
+	for _, hint := range []string{"bold", "black", "heavy", "semibold", "demi"} {
+		if strings.Contains(n, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyFontSize is the most common font size across the document, weighted by glyph count
+func bodyFontSize(pages [][]pdfGlyph) float64 {
+	counts := map[float64]int{}
+	for _, glyphs := range pages {
+		for _, g := range glyphs {
+			if strings.TrimSpace(g.s) != "" {
+				counts[math.Round(g.size*2)/2]++
+			}
+		}
+	}
+	best, bestN := 0.0, -1
+	for size, n := range counts {
+		if n > bestN || (n == bestN && size < best) {
+			best, bestN = size, n
+		}
+	}
+	return best
+}
+
+func layoutPage(page int, glyphs []pdfGlyph) []pdfTextLine {
+	baselines := groupBaselines(glyphs)
+	gutters := findGutters(baselines)
+	return readingOrder(page, baselines, gutters)
+}
+
+// groupBaselines clusters glyphs that share a baseline and splits each baseline into runs
+func groupBaselines(glyphs []pdfGlyph) []pdfBaseline {
+	sorted := append([]pdfGlyph(nil), glyphs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].y > sorted[j].y })
+
+	var clusters [][]pdfGlyph
+	for _, g := range sorted {
+		if n := len(clusters); n > 0 {
+			head := clusters[n-1][0]
+			if head.y-g.y <= 0.4*math.Min(head.size, g.size) {
+				clusters[n-1] = append(clusters[n-1], g)
+				continue
+			}
+		}
+		clusters = append(clusters, []pdfGlyph{g})
+	}
+
+	baselines := make([]pdfBaseline, 0, len(clusters))
+	for _, cl := range clusters {
+		sort.SliceStable(cl, func(i, j int) bool { return cl[i].x < cl[j].x })
+		bl := pdfBaseline{y: cl[0].y}
+		var cur []pdfGlyph
+		flush := func() {
+			if run, ok := makeRun(cur); ok {
+				bl.runs = append(bl.runs, run)
+			}
+			cur = nil
+		}
+		for _, g := range cl {
+			if n := len(cur); n > 0 {
+				prev := cur[n-1]
+				if g.x-(prev.x+prev.w) > pdfRunGapEm*math.Max(prev.size, g.size) {
+					flush()
+				}
+			}
+			cur = append(cur, g)
+		}
+		flush()
+		if len(bl.runs) > 0 {
+			baselines = append(baselines, bl)
+		}
+	}
+	return baselines
+}
+
+func makeRun(glyphs []pdfGlyph) (pdfRun, bool) {
+	var b strings.Builder
+	run := pdfRun{x0: math.Inf(1)}
+	boldGlyphs, visible := 0, 0
+	var prev *pdfGlyph
+	for i := range glyphs {
+		g := &glyphs[i]
+		if strings.TrimSpace(g.s) == "" {
+			b.WriteByte(' ')
+			prev = g
+			continue
+		}
+		if prev != nil && g.x-(prev.x+prev.w) > pdfWordGapEm*g.size {
+			b.WriteByte(' ')
+		}
+		b.WriteString(g.s)
+		run.x0 = math.Min(run.x0, g.x)
+		run.x1 = math.Max(run.x1, g.x+g.w)
+		run.size = math.Max(run.size, g.size)
+		visible++
+		if g.bold {
+			boldGlyphs++
+		}
+		prev = g
+	}
+	run.text = strings.Join(strings.Fields(b.String()), " ")
+	run.bold = visible > 0 && boldGlyphs*2 > visible
+	return run, visible > 0
+}
+
+// findGutters returns vertical bands that (almost) no run crosses and that separate two
+// dense columns of text. Sparse left-hand runs, such as dates in a tabular layout, do not
+// form a column and are kept on the same line as their neighbours.
+func findGutters(baselines []pdfBaseline) []pdfGutter {
+	var runs []pdfRun
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for _, bl := range baselines {
+		for _, r := range bl.runs {
+			runs = append(runs, r)
+			minX = math.Min(minX, r.x0)
+			maxX = math.Max(maxX, r.x1)
+		}
+	}
+	if len(runs) < 6 || maxX-minX < 4*pdfMinGutter {
+		return nil
+	}
+
+	const bin = 2.0
+	coverage := make([]int, int((maxX-minX)/bin)+1)
+	for _, r := range runs {
+		for i := int((r.x0 - minX) / bin); i <= int((r.x1-minX)/bin) && i < len(coverage); i++ {
+			coverage[i]++
+		}
+	}
+	threshold := int(math.Max(1, float64(len(runs))*0.06))
+
+	var candidates []pdfGutter
+	for i := 0; i < len(coverage); {
+		if coverage[i] > threshold {
+			i++
+			continue
+		}
+		j := i
+		for j < len(coverage) && coverage[j] <= threshold {
+			j++
+		}
+		g := pdfGutter{start: minX + float64(i)*bin, end: minX + float64(j)*bin}
+		// each side must be wide enough to hold text, not just a column of bullet glyphs
+		if g.end-g.start >= pdfMinGutter && g.start-minX >= pdfMinColumnWidth && maxX-g.end >= pdfMinColumnWidth {
+			candidates = append(candidates, g)
+		}
+		i = j
+	}
+
+	var gutters []pdfGutter
+	for _, g := range candidates {
+		var left, right []pdfBaseline
+		for _, bl := range baselines {
+			for _, r := range bl.runs {
+				switch {
+				case r.x1 <= g.start+bin:
+					left = append(left, pdfBaseline{y: bl.y, runs: []pdfRun{r}})
+				case r.x0 >= g.end-bin:
+					right = append(right, pdfBaseline{y: bl.y, runs: []pdfRun{r}})
+				}
+			}
+		}
+		if isDenseColumn(left) && isDenseColumn(right) {
+			gutters = append(gutters, g)
+		}
+	}
+	return gutters
+}
+
+// isDenseColumn reports whether most consecutive runs on one side of a gutter sit on
+// adjacent lines, as they do in a real column of text.
+func isDenseColumn(side []pdfBaseline) bool {
+	if len(side) < 3 {
+		return false
+	}
+	tight := 0
+	for i := 1; i < len(side); i++ {
+		if side[i-1].y-side[i].y <= 2*side[i].runs[0].size {
+			tight++
+		}
+	}
+	return tight*2 >= len(side)-1
+}
+
+// readingOrder emits lines column by column inside each horizontal band. A baseline with a
+// run that crosses a gutter, such as a full-width name or section title, closes the band.
+func readingOrder(page int, baselines []pdfBaseline, gutters []pdfGutter) []pdfTextLine {
+	columnOf := func(r pdfRun) int {
+		col := 0
+		for _, g := range gutters {
+			if r.x0 < g.start-pdfMinGutter/2 && r.x1 > g.end+pdfMinGutter/2 {
+				return -1 // spans the gutter
+			}
+			if r.x0 >= g.end-pdfMinGutter/2 {
+				col++
+			}
+		}
+		return col
+	}
+
+	var out []pdfTextLine
+	band := make([][]pdfTextLine, len(gutters)+1)
+	flush := func() {
+		for c := range band {
+			out = append(out, band[c]...)
+			band[c] = nil
+		}
+	}
+
+	for _, bl := range baselines {
+		cols := make([][]pdfRun, len(gutters)+1)
+		spanning := false
+		for _, r := range bl.runs {
+			c := columnOf(r)
+			if c < 0 {
+				spanning = true
+				break
+			}
+			cols[c] = append(cols[c], r)
+		}
+		if spanning {
+			flush()
+			out = append(out, mergeRuns(page, 0, bl.y, bl.runs))
+			continue
+		}
+		for c, runs := range cols {
+			if len(runs) > 0 {
+				band[c] = append(band[c], mergeRuns(page, c, bl.y, runs))
+			}
+		}
+	}
+	flush()
+	return out
+}
+
+func mergeRuns(page, column int, y float64, runs []pdfRun) pdfTextLine {
+	line := pdfTextLine{page: page, column: column, x0: runs[0].x0, y: y}
+	texts := make([]string, 0, len(runs))
+	boldRunes, allRunes := 0, 0
+	for _, r := range runs {
+		texts = append(texts, r.text)
+		line.size = math.Max(line.size, r.size)
+		n := len([]rune(r.text))
+		allRunes += n
+		if r.bold {
+			boldRunes += n
+		}
+	}
+	line.text = strings.Join(texts, " ")
+	line.bold = allRunes > 0 && boldRunes*2 > allRunes
+	return line
+}
+
+var bulletPrefixes = []string{"•", "·", "▪", "◦", "‣", "●", "■", "➢", "►", "✓", "\uf0b7", "- ", "* ", "– "}
+
+// splitBullet strips a leading bullet glyph and reports whether one was present
+func splitBullet(text string) (string, bool) {
+	for _, p := range bulletPrefixes {
+		if strings.HasPrefix(text, p) {
+			return strings.TrimSpace(strings.TrimPrefix(text, p)), true
+		}
+	}
+	return text, false
+}
+
+func isHeadingLine(line pdfTextLine, bodySize float64) bool {
+	text := line.text
+	if len(text) > pdfMaxHeadingLen || len(strings.Fields(text)) > 8 {
+		return false
+	}
+	if strings.HasSuffix(text, ".") || strings.HasSuffix(text, ",") {
+		return false
+	}
+	if bodySize > 0 && line.size >= bodySize*pdfHeadingScale {
+		return true
+	}
+	if !line.bold || len(strings.Fields(text)) > 5 {
+		return false
+	}
+	return !strings.ContainsFunc(text, unicode.IsDigit)
+}
+
+// buildPDFBlocks groups lines into heading, paragraph and bullet blocks. A line continues
+// the previous block when it is in the same column and close below it; a bullet's wrapped
+// lines must also be indented past the bullet glyph.
+func buildPDFBlocks(lines []pdfTextLine, bodySize float64) []domain.CVTextBlock {
+	var blocks []domain.CVTextBlock
+	var last *pdfTextLine
+	var lastBlockX0 float64
+
+	for i := range lines {
+		ln := &lines[i]
+		if strings.TrimSpace(ln.text) == "" {
+			continue
+		}
+		text, isBullet := splitBullet(ln.text)
+		tight := last != nil && last.page == ln.page && last.column == ln.column &&
+			last.y > ln.y && last.y-ln.y <= pdfLineGapEm*math.Max(last.size, ln.size)
+
+		var cur *domain.CVTextBlock
+		if n := len(blocks); n > 0 {
+			cur = &blocks[n-1]
+		}
+
+		switch {
+		case isBullet:
+			blocks = append(blocks, newPDFBlock(domain.BlockBullet, text, ln))
+			lastBlockX0 = ln.x0
+		case isHeadingLine(*ln, bodySize):
+			if cur != nil && cur.Kind == domain.BlockHeading && tight && ln.size == cur.FontSize {
+				cur.Text += " " + text // heading wrapped onto a second line
+			} else {
+				blocks = append(blocks, newPDFBlock(domain.BlockHeading, text, ln))
+			}
+			lastBlockX0 = ln.x0
+		case tight && cur != nil && cur.Kind == domain.BlockBullet && ln.x0 > lastBlockX0+0.5*ln.size:
+			cur.Text += " " + text
+		case tight && cur != nil && cur.Kind == domain.BlockParagraph:
+			cur.Text += "\n" + text
+		default:
+			blocks = append(blocks, newPDFBlock(domain.BlockParagraph, text, ln))
+			lastBlockX0 = ln.x0
+		}
+		last = ln
+	}
+	return blocks
+}
+
+func newPDFBlock(kind domain.CVBlockKind, text string, ln *pdfTextLine) domain.CVTextBlock {
+	return domain.CVTextBlock{
+		Kind:     kind,
+		Text:     text,
+		Page:     ln.page,
+		Column:   ln.column,
+		FontSize: ln.size,
+	}
+}
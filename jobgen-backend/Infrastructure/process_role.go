@@ -0,0 +1,43 @@
+package infrastructure
+
+import "fmt"
+
+// ProcessRole tells which components a process runs.
+type ProcessRole struct {
+	Name   string
+	API    bool
+	Worker bool
+}
+
+// ParseProcessRole reads "api", "worker" or "all"; an empty role means "all".
+func ParseProcessRole(role string) (ProcessRole, error) {
+	switch role {
+	case "api":
+		return ProcessRole{Name: role, API: true}, nil
+	case "worker":
+		return ProcessRole{Name: role, Worker: true}, nil
+	case "all", "":
+		return ProcessRole{Name: "all", API: true, Worker: true}, nil
+	}
+	return ProcessRole{}, fmt.Errorf("unknown process role %q, expected api, worker or all", role)
+}
+
+// Split reports whether the API and the CV worker run in separate processes.
+func (r ProcessRole) Split() bool {
+	return !(r.API && r.Worker)
+}
+
+// CheckSharedServices fails for a split role unless CV jobs go through Redis and CV files
+// through MinIO, since the API and the workers cannot see each other's memory or disk.
+func (r ProcessRole) CheckSharedServices(durableQueue, sharedStorage bool) error {
+	if !r.Split() {
+		return nil
+	}
+	if !durableQueue {
+		return fmt.Errorf("role %q needs the Redis queue to share CV jobs with other processes; set REDIS_URL or REDIS_ADDR", r.Name)
+	}
+	if !sharedStorage {
+		return fmt.Errorf("role %q needs MinIO to share CV files with other processes; set FILE_STORAGE_URL, STORAGE_ACCESS_KEY and STORAGE_SECRET_KEY", r.Name)
+	}
+	return nil
+}
@@ -2,37 +2,313 @@ package infrastructure
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sort"
 	"time"
 
+	domain "jobgen-backend/Domain"
+
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 )
 
+// ErrLeaseLost is returned when settling a delivery whose lease expired and was given to
+// another delivery; the job is left to whoever holds it now.
+var ErrLeaseLost = errors.New("queue lease expired or taken by another delivery")
+
+// QueueService delivers CV jobs at least once. A dequeued job is leased to the caller for the
+// visibility timeout and must be settled with Ack, Retry or DeadLetter; a lease that expires
+// (e.g. the worker crashed) puts the job back on the queue. Each delivery holds its own lease,
+// so settling a delivery whose lease has expired returns ErrLeaseLost and changes nothing.
 type QueueService interface {
 	Enqueue(jobID string) error
-	Dequeue() (string, error)
+	// Dequeue blocks until a job is available or ctx is done. A job redelivered after its
+	// lease expired on the final attempt is still returned, Exhausted and leased, so the
+	// caller can record the failure before dead-lettering it.
+	Dequeue(ctx context.Context) (*QueueDelivery, error)
+	// Ack removes a successfully processed job for good.
+	Ack(d *QueueDelivery) error
+	// Retry makes the job available again after an exponential backoff.
+	Retry(d *QueueDelivery) error
+	// DeadLetter parks the job until an admin requeues it.
+	DeadLetter(d *QueueDelivery, reason string) error
+	ListDeadLetters() ([]domain.DeadLetteredJob, error)
+	// RequeueDeadLetter moves a dead-lettered job back onto the queue with a fresh attempt count.
+	RequeueDeadLetter(jobID string) error
+}
+
+// QueueDelivery is one leased attempt at processing a job.
+type QueueDelivery struct {
+	JobID       string
+	Attempt     int // 1 on the first delivery
+	MaxAttempts int
+
+	lease string // token of this delivery's lease
+}
+
+// LastAttempt reports whether a retry would exceed the attempt limit.
+func (d *QueueDelivery) LastAttempt() bool {
+	return d.Attempt >= d.MaxAttempts
+}
+
+// Exhausted reports whether the job was redelivered after its last allowed attempt, e.g.
+// because it crashes the worker every time. It must not be processed again.
+func (d *QueueDelivery) Exhausted() bool {
+	return d.Attempt > d.MaxAttempts
+}
+
+// QueueOptions tunes leasing and retries.
+type QueueOptions struct {
+	VisibilityTimeout time.Duration // how long a job stays leased before it is redelivered
+	MaxAttempts       int
+	BaseBackoff       time.Duration // delay before the first retry; doubles per attempt
+	MaxBackoff        time.Duration
+	PollInterval      time.Duration // how often an idle Dequeue checks for due jobs
+}
+
+func DefaultQueueOptions() QueueOptions {
+	return QueueOptions{
+		VisibilityTimeout: 5 * time.Minute,
+		MaxAttempts:       5,
+		BaseBackoff:       10 * time.Second,
+		MaxBackoff:        10 * time.Minute,
+		PollInterval:      500 * time.Millisecond,
+	}
 }
 
+// leaseMargin is how long a lease outlives the job timeout, leaving the worker time to record
+// the outcome and settle the delivery after the job is cut off.
+const leaseMargin = time.Minute
+
+// QueueOptionsForJobTimeout returns the default options with a lease long enough that a job
+// running up to jobTimeout is never redelivered to a second worker while it still runs.
+func QueueOptionsForJobTimeout(jobTimeout time.Duration) QueueOptions {
+	opts := DefaultQueueOptions()
+	if lease := jobTimeout + leaseMargin; lease > opts.VisibilityTimeout {
+		opts.VisibilityTimeout = lease
+	}
+	return opts
+}
+
+func (o QueueOptions) withDefaults() QueueOptions {
+	def := DefaultQueueOptions()
+	if o.VisibilityTimeout <= 0 {
+		o.VisibilityTimeout = def.VisibilityTimeout
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = def.MaxAttempts
+	}
+	if o.BaseBackoff <= 0 {
+		o.BaseBackoff = def.BaseBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = def.MaxBackoff
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = def.PollInterval
+	}
+	return o
+}
+
+// backoff returns the delay before retrying after the given attempt, with ±20% jitter so
+// jobs failing together do not retry in lockstep.
+func (o QueueOptions) backoff(attempt int) time.Duration {
+	d := o.BaseBackoff
+	for i := 1; i < attempt && d < o.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > o.MaxBackoff {
+		d = o.MaxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/5+1)) * 2
+	return d - d/5 + jitter
+}
+
+// redisQueueService keeps ready jobs in a list and leased and delayed jobs in sorted sets
+// scored by the time they become due. Moves between them run as Lua scripts so a job is
+// never in two places or lost between commands. Settling a job checks the delivery's lease
+// token in the same script, so a worker whose lease expired cannot touch the next lease.
 type redisQueueService struct {
-	client    *redis.Client
-	queueName string
+	client *redis.Client
+	opts   QueueOptions
+
+	readyKey    string // list of job IDs
+	leasedKey   string // zset: job ID -> lease expiry (unix ms)
+	leasesKey   string // hash: job ID -> token of the current lease
+	delayedKey  string // zset: job ID -> retry time (unix ms)
+	attemptsKey string // hash: job ID -> deliveries so far
+	deadKey     string // hash: job ID -> JSON dead letter
 }
 
-func NewQueueService(client *redis.Client, queueName string) QueueService {
+func NewQueueService(client *redis.Client, queueName string, opts QueueOptions) QueueService {
 	return &redisQueueService{
-		client:    client,
-		queueName: queueName,
+		client:      client,
+		opts:        opts.withDefaults(),
+		readyKey:    queueName,
+		leasedKey:   queueName + ":leased",
+		leasesKey:   queueName + ":leases",
+		delayedKey:  queueName + ":delayed",
+		attemptsKey: queueName + ":attempts",
+		deadKey:     queueName + ":dead",
 	}
 }
 
+// KEYS: ready, leased, attempts, leases; ARGV: lease expiry, lease token
+var dequeueScript = redis.NewScript(`
+local id = redis.call('RPOP', KEYS[1])
+if not id then return false end
+redis.call('ZADD', KEYS[2], ARGV[1], id)
+redis.call('HSET', KEYS[4], id, ARGV[2])
+local n = redis.call('HINCRBY', KEYS[3], id, 1)
+return {id, n}
+`)
+
+// KEYS: ready, leased, delayed, leases; ARGV: now
+var promoteDueScript = redis.NewScript(`
+local moved = 0
+for i = 2, 3 do
+  local due = redis.call('ZRANGEBYSCORE', KEYS[i], '-inf', ARGV[1], 'LIMIT', 0, 100)
+  for _, id in ipairs(due) do
+    redis.call('ZREM', KEYS[i], id)
+    if i == 2 then redis.call('HDEL', KEYS[4], id) end
+    redis.call('LPUSH', KEYS[1], id)
+    moved = moved + 1
+  end
+end
+return moved
+`)
+
+// The settle scripts return 0 without changing anything unless the token still holds the lease.
+
+// KEYS: leased, leases, attempts; ARGV: job ID, lease token
+var ackScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then return 0 end
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+return 1
+`)
+
+// KEYS: leased, leases, delayed; ARGV: job ID, lease token, retry time
+var retryScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then return 0 end
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[1])
+return 1
+`)
+
+// KEYS: leased, leases, attempts, dead; ARGV: job ID, lease token, dead letter JSON
+var deadLetterScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then return 0 end
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+redis.call('HSET', KEYS[4], ARGV[1], ARGV[3])
+return 1
+`)
+
 func (q *redisQueueService) Enqueue(jobID string) error {
-	return q.client.LPush(context.Background(), q.queueName, jobID).Err()
+	return q.client.LPush(context.Background(), q.readyKey, jobID).Err()
+}
+
+func (q *redisQueueService) Dequeue(ctx context.Context) (*QueueDelivery, error) {
+	for {
+		// Expired leases and due retries go back on the ready list first
+		now := time.Now()
+		if err := promoteDueScript.Run(ctx, q.client, []string{q.readyKey, q.leasedKey, q.delayedKey, q.leasesKey}, now.UnixMilli()).Err(); err != nil {
+			return nil, err
+		}
+
+		lease := uuid.NewString()
+		res, err := dequeueScript.Run(ctx, q.client, []string{q.readyKey, q.leasedKey, q.attemptsKey, q.leasesKey},
+			now.Add(q.opts.VisibilityTimeout).UnixMilli(), lease).Slice()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		if err == nil && len(res) == 2 {
+			return &QueueDelivery{JobID: res[0].(string), Attempt: int(res[1].(int64)), MaxAttempts: q.opts.MaxAttempts, lease: lease}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(q.opts.PollInterval):
+		}
+	}
+}
+
+func (q *redisQueueService) Ack(d *QueueDelivery) error {
+	return q.settle(ackScript, []string{q.leasedKey, q.leasesKey, q.attemptsKey}, d.JobID, d.lease)
 }
 
-func (q *redisQueueService) Dequeue() (string, error) {
-	// Blocking pop: waits forever until a job is available.
-	result, err := q.client.BRPop(context.Background(), 0*time.Second, q.queueName).Result()
+func (q *redisQueueService) Retry(d *QueueDelivery) error {
+	due := time.Now().Add(q.opts.backoff(d.Attempt))
+	return q.settle(retryScript, []string{q.leasedKey, q.leasesKey, q.delayedKey}, d.JobID, d.lease, due.UnixMilli())
+}
+
+func (q *redisQueueService) DeadLetter(d *QueueDelivery, reason string) error {
+	entry, err := json.Marshal(domain.DeadLetteredJob{
+		JobID:    d.JobID,
+		Attempts: d.Attempt,
+		Reason:   reason,
+		FailedAt: time.Now().UTC(),
+	})
 	if err != nil {
-		return "", err
+		return err
 	}
-	return result[1], nil // result is a slice [queueName, value]
+	return q.settle(deadLetterScript, []string{q.leasedKey, q.leasesKey, q.attemptsKey, q.deadKey}, d.JobID, d.lease, entry)
+}
+
+// settle runs one of the settle scripts and reports ErrLeaseLost if the lease was not held.
+func (q *redisQueueService) settle(script *redis.Script, keys []string, args ...interface{}) error {
+	held, err := script.Run(context.Background(), q.client, keys, args...).Int()
+	if err != nil {
+		return err
+	}
+	if held == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (q *redisQueueService) ListDeadLetters() ([]domain.DeadLetteredJob, error) {
+	entries, err := q.client.HGetAll(context.Background(), q.deadKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]domain.DeadLetteredJob, 0, len(entries))
+	for id, raw := range entries {
+		var job domain.DeadLetteredJob
+		if err := json.Unmarshal([]byte(raw), &job); err != nil {
+			job = domain.DeadLetteredJob{JobID: id, Reason: "unreadable dead letter entry"}
+		}
+		jobs = append(jobs, job)
+	}
+	sortDeadLetters(jobs)
+	return jobs, nil
+}
+
+func (q *redisQueueService) RequeueDeadLetter(jobID string) error {
+	ctx := context.Background()
+	removed, err := q.client.HDel(ctx, q.deadKey, jobID).Result()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return domain.ErrNotFound
+	}
+	return q.client.LPush(ctx, q.readyKey, jobID).Err()
+}
+
+// sortDeadLetters orders dead letters newest first.
+func sortDeadLetters(jobs []domain.DeadLetteredJob) {
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].FailedAt.Equal(jobs[j].FailedAt) {
+			return jobs[i].JobID < jobs[j].JobID
+		}
+		return jobs[i].FailedAt.After(jobs[j].FailedAt)
+	})
 }
@@ -2,47 +2,65 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	domain "jobgen-backend/Domain"
+	"log"
 	"math"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// Feedback learning parameters
+const (
+	feedbackLearningRate = 0.05 // how far a single verdict moves the weights
+	minComponentWeight   = 0.05 // no component is ever switched off entirely
+)
+
+// matchScoreBands are the boundaries used by the admin precision report.
+var matchScoreBands = []float64{0, 30, 40, 50, 60, 70, 80, 90, 100.0001}
+
 type JobMatchingService struct {
-	jobRepo  domain.IJobRepository
-	userRepo domain.IUserRepository
+	jobRepo      domain.IJobRepository
+	userRepo     domain.IUserRepository
+	feedbackRepo domain.IJobFeedbackRepository
 }
 
-func NewJobMatchingService(jobRepo domain.IJobRepository, userRepo domain.IUserRepository) domain.IJobMatchingService {
+func NewJobMatchingService(jobRepo domain.IJobRepository, userRepo domain.IUserRepository, feedbackRepo domain.IJobFeedbackRepository) domain.IJobMatchingService {
 	return &JobMatchingService{
-		jobRepo:  jobRepo,
-		userRepo: userRepo,
+		jobRepo:      jobRepo,
+		userRepo:     userRepo,
+		feedbackRepo: feedbackRepo,
 	}
 }
 
+// CalculateMatchScore scores a job using the global default weights.
 func (j *JobMatchingService) CalculateMatchScore(job domain.Job, preferences domain.UserJobPreferences) float64 {
-	var totalScore float64
-	
-	// Skills matching (70% weight)
-	skillScore := j.calculateSkillScore(job.ExtractedSkills, preferences.Skills)
-	totalScore += skillScore * 0.7
-	
-	// Experience matching (20% weight)
-	expScore := j.calculateExperienceScore(job.Description, preferences.ExperienceYears)
-	totalScore += expScore * 0.2
-	
-	// Location matching (10% weight)
-	locationScore := j.calculateLocationScore(job.Location, preferences.Locations)
-	totalScore += locationScore * 0.1
-	
+	return j.calculateWeightedScore(job, preferences, domain.DefaultMatchWeights())
+}
+
+func (j *JobMatchingService) calculateWeightedScore(job domain.Job, preferences domain.UserJobPreferences, weights domain.MatchWeights) float64 {
+	skillScore, expScore, locationScore := j.componentScores(job, preferences)
+
+	totalScore := skillScore*weights.Skills + expScore*weights.Experience + locationScore*weights.Location
+
 	// Ensure score is between 0 and 100
 	totalScore = math.Min(100, math.Max(0, totalScore))
-	
+
 	return totalScore
 }
 
+// componentScores returns the skills, experience and location scores (each 0-100) for a job.
+func (j *JobMatchingService) componentScores(job domain.Job, preferences domain.UserJobPreferences) (float64, float64, float64) {
+	skillScore := j.calculateSkillScore(job.ExtractedSkills, preferences.Skills)
+	expScore := j.calculateExperienceScore(job.Description, preferences.ExperienceYears)
+	locationScore := j.calculateLocationScore(job.Location, preferences.Locations)
+	return skillScore, expScore, locationScore
+}
+
 func (j *JobMatchingService) calculateSkillScore(jobSkills, userSkills []string) float64 {
 	if len(userSkills) == 0 {
 		return 0
@@ -172,14 +190,26 @@ func (j *JobMatchingService) GetMatchedJobs(ctx context.Context, userID string,
 	}
 	
 	// Create user preferences from user profile
-	preferences := domain.UserJobPreferences{
-		Skills:          user.Skills,
-		ExperienceYears: user.ExperienceYears,
-		Locations:       []string{user.Location}, // Can be expanded to support multiple preferred locations
+	preferences := preferencesFromUser(user)
+
+	// Learned weights and dismissed jobs from the user's feedback
+	weights, err := j.userWeights(ctx, userID)
+	if err != nil {
+		log.Printf("failed to get match weights for user %s, scoring with the defaults: %v", userID, err)
+	}
+	dismissed := make(map[string]bool)
+	if j.feedbackRepo != nil {
+		ids, err := j.feedbackRepo.GetDismissedJobIDs(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dismissed jobs: %w", err)
+		}
+		for _, id := range ids {
+			dismissed[id] = true
+		}
 	}
 	
 	// Get jobs for matching (you might want to implement pagination here too)
-	jobs, err := j.jobRepo.GetJobsForMatching(ctx, limit*2, offset) // Get more jobs to allow for filtering
+	jobs, err := j.jobRepo.GetJobsForMatching(ctx, limit*2+len(dismissed), offset) // Get more jobs to allow for filtering
 	if err != nil {
 		return nil, fmt.Errorf("failed to get jobs for matching: %w", err)
 	}
@@ -187,7 +217,10 @@ func (j *JobMatchingService) GetMatchedJobs(ctx context.Context, userID string,
 	// Calculate match scores and filter
 	var matchedJobs []domain.Job
 	for _, job := range jobs {
-		score := j.CalculateMatchScore(job, preferences)
+		if dismissed[job.ID] {
+			continue
+		}
+		score := j.calculateWeightedScore(job, preferences, weights)
 		
 		// Only include jobs with score above threshold
 		if score >= 30 { // 30% minimum match
@@ -207,6 +240,155 @@ func (j *JobMatchingService) GetMatchedJobs(ctx context.Context, userID string,
 	return matchedJobs, nil
 }
 
+func preferencesFromUser(user *domain.User) domain.UserJobPreferences {
+	return domain.UserJobPreferences{
+		Skills:          user.Skills,
+		ExperienceYears: user.ExperienceYears,
+		Locations:       []string{user.Location}, // Can be expanded to support multiple preferred locations
+	}
+}
+
+// userWeights returns the learned weights for a user, or the defaults if the user has none.
+// The defaults also come back with any other error, which callers must not save over the
+// user's weights.
+func (j *JobMatchingService) userWeights(ctx context.Context, userID string) (domain.MatchWeights, error) {
+	defaults := domain.DefaultMatchWeights()
+	defaults.UserID = userID
+	if j.feedbackRepo == nil {
+		return defaults, nil
+	}
+	weights, err := j.feedbackRepo.GetWeights(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return defaults, nil
+	}
+	if err != nil {
+		return defaults, err
+	}
+	return *weights, nil
+}
+
+// RecordFeedback stores a verdict on a job and nudges the user's component weights.
+// A thumbs-up shifts weight towards the components that scored above the job's average,
+// a thumbs-down or dismissal shifts it away from them. Repeating a verdict, or switching
+// between the two negative ones, leaves the weights alone; flipping it undoes the previous
+// nudge before applying the new one, so each job counts once.
+func (j *JobMatchingService) RecordFeedback(ctx context.Context, userID string, feedback *domain.JobFeedback) error {
+	if j.feedbackRepo == nil {
+		return fmt.Errorf("feedback storage not configured")
+	}
+
+	user, err := j.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	job, err := j.jobRepo.GetByID(ctx, feedback.JobID)
+	if err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+
+	previous, err := j.feedbackRepo.Get(ctx, userID, feedback.JobID)
+	if errors.Is(err, domain.ErrNotFound) {
+		previous = nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get previous feedback: %w", err)
+	}
+
+	preferences := preferencesFromUser(user)
+	weights, err := j.userWeights(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get match weights: %w", err)
+	}
+
+	feedback.UserID = userID
+	feedback.MatchScore = j.calculateWeightedScore(*job, preferences, weights)
+
+	if previous != nil && previous.Type.IsPositive() == feedback.Type.IsPositive() {
+		// Same verdict as before; the weights already reflect it
+		feedback.Adjustment = previous.Adjustment
+		if err := j.feedbackRepo.Upsert(ctx, feedback); err != nil {
+			return fmt.Errorf("failed to save feedback: %w", err)
+		}
+		return nil
+	}
+
+	if previous != nil && previous.Adjustment != nil {
+		weights.Skills -= previous.Adjustment.Skills
+		weights.Experience -= previous.Adjustment.Experience
+		weights.Location -= previous.Adjustment.Location
+	}
+
+	skillScore, expScore, locationScore := j.componentScores(*job, preferences)
+	direction := -1.0
+	if feedback.Type.IsPositive() {
+		direction = 1.0
+	}
+	avg := (skillScore + expScore + locationScore) / 3
+	adjustment := &domain.MatchWeightAdjustment{
+		Skills:     direction * feedbackLearningRate * (skillScore - avg) / 100,
+		Experience: direction * feedbackLearningRate * (expScore - avg) / 100,
+		Location:   direction * feedbackLearningRate * (locationScore - avg) / 100,
+	}
+	weights.Skills += adjustment.Skills
+	weights.Experience += adjustment.Experience
+	weights.Location += adjustment.Location
+	normalizeWeights(&weights)
+	if previous == nil {
+		weights.Samples++
+	}
+
+	feedback.Adjustment = adjustment
+	if err := j.feedbackRepo.Upsert(ctx, feedback); err != nil {
+		return fmt.Errorf("failed to save feedback: %w", err)
+	}
+
+	if err := j.feedbackRepo.SaveWeights(ctx, &weights); err != nil {
+		return fmt.Errorf("failed to save match weights: %w", err)
+	}
+	return nil
+}
+
+// normalizeWeights clamps each component to the minimum and rescales them to sum to 1.
+func normalizeWeights(w *domain.MatchWeights) {
+	w.Skills = math.Max(minComponentWeight, w.Skills)
+	w.Experience = math.Max(minComponentWeight, w.Experience)
+	w.Location = math.Max(minComponentWeight, w.Location)
+	sum := w.Skills + w.Experience + w.Location
+	w.Skills /= sum
+	w.Experience /= sum
+	w.Location /= sum
+}
+
+// GetMatchPrecisionReport aggregates feedback by score band. Precision is the share of
+// feedback in a band that was a thumbs-up.
+func (j *JobMatchingService) GetMatchPrecisionReport(ctx context.Context) (*domain.MatchPrecisionReport, error) {
+	if j.feedbackRepo == nil {
+		return nil, fmt.Errorf("feedback storage not configured")
+	}
+
+	bands, err := j.feedbackRepo.GetScoreBands(ctx, matchScoreBands)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate feedback: %w", err)
+	}
+
+	report := &domain.MatchPrecisionReport{Bands: bands, GeneratedAt: time.Now()}
+	var positive int64
+	for i := range report.Bands {
+		band := &report.Bands[i]
+		if band.MaxScore > 100 {
+			band.MaxScore = 100
+		}
+		if band.Total > 0 {
+			band.Precision = float64(band.Positive) / float64(band.Total)
+		}
+		positive += band.Positive
+		report.TotalFeedback += band.Total
+	}
+	if report.TotalFeedback > 0 {
+		report.Precision = float64(positive) / float64(report.TotalFeedback)
+	}
+	return report, nil
+}
+
 func (j *JobMatchingService) sortJobsByMatchScore(jobs []domain.Job) {
 	// Simple bubble sort by match score (for small arrays)
 	n := len(jobs)
@@ -253,6 +435,101 @@ func (j *JobMatchingService) UpdateUserPreferences(ctx context.Context, userID s
 	return nil
 }
 
+// AnalyzeGap compares a job's skills and experience requirement against a parsed CV.
+// Skill coverage is measured against the job's requirements, so a CV with many unrelated
+// skills does not score higher than one that covers exactly what the job asks for.
+func (j *JobMatchingService) AnalyzeGap(job domain.Job, cv domain.CV) domain.JobGapAnalysis {
+	analysis := domain.JobGapAnalysis{
+		JobID:          job.ID,
+		CVID:           cv.ID,
+		MatchingSkills: []string{},
+		MissingSkills:  []string{},
+	}
+
+	cvSkills := make(map[string]bool)
+	for _, skill := range cv.Skills {
+		cvSkills[strings.ToLower(strings.TrimSpace(skill))] = true
+	}
+	var evidence strings.Builder
+	for _, exp := range cv.Experiences {
+		evidence.WriteString(strings.ToLower(exp.Title + " " + exp.Description + " "))
+	}
+	evidenceText := evidence.String()
+
+	seen := make(map[string]bool)
+	for _, skill := range job.ExtractedSkills {
+		key := strings.ToLower(strings.TrimSpace(skill))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if cvSkills[key] || containsWord(evidenceText, key) {
+			analysis.MatchingSkills = append(analysis.MatchingSkills, skill)
+		} else {
+			analysis.MissingSkills = append(analysis.MissingSkills, skill)
+		}
+	}
+
+	if len(seen) == 0 {
+		analysis.SkillScore = 75 // No listed skills to compare against
+	} else {
+		analysis.SkillScore = float64(len(analysis.MatchingSkills)) / float64(len(seen)) * 100
+	}
+
+	analysis.RequiredExperienceYears = j.extractExperienceRequirement(job.Description)
+	analysis.CVExperienceYears = math.Round(cvExperienceYears(cv.Experiences)*10) / 10
+	if gap := float64(analysis.RequiredExperienceYears) - analysis.CVExperienceYears; gap > 0 {
+		analysis.ExperienceGapYears = math.Round(gap*10) / 10
+	}
+	analysis.ExperienceScore = j.calculateExperienceScore(job.Description, int(analysis.CVExperienceYears))
+
+	// Skills (75% weight), experience (25% weight)
+	analysis.FitScore = math.Round(analysis.SkillScore*0.75 + analysis.ExperienceScore*0.25)
+
+	return analysis
+}
+
+// cvExperienceYears adds up the time covered by dated experiences, counting overlapping roles
+// once. Open-ended roles run until now.
+func cvExperienceYears(experiences []domain.Experience) float64 {
+	type span struct{ start, end time.Time }
+	var spans []span
+	for _, exp := range experiences {
+		if exp.StartDate.IsZero() {
+			continue
+		}
+		end := time.Now()
+		if exp.EndDate != nil && !exp.EndDate.IsZero() {
+			end = *exp.EndDate
+		}
+		if end.After(exp.StartDate) {
+			spans = append(spans, span{exp.StartDate, end})
+		}
+	}
+	sort.Slice(spans, func(a, b int) bool { return spans[a].start.Before(spans[b].start) })
+
+	var total time.Duration
+	for i := 0; i < len(spans); {
+		merged := spans[i]
+		for i++; i < len(spans) && !spans[i].start.After(merged.end); i++ {
+			if spans[i].end.After(merged.end) {
+				merged.end = spans[i].end
+			}
+		}
+		total += merged.end.Sub(merged.start)
+	}
+	return total.Hours() / 24 / 365
+}
+
+// containsWord reports whether word appears in text on word boundaries.
+func containsWord(text, word string) bool {
+	re, err := regexp.Compile(`(^|[^a-z0-9+#])` + regexp.QuoteMeta(word) + `($|[^a-z0-9+#])`)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(text)
+}
+
 // GetJobRecommendations provides more advanced recommendations
 func (j *JobMatchingService) GetJobRecommendations(ctx context.Context, userID string, limit int) ([]domain.Job, error) {
 	// This could be expanded with ML algorithms in the future
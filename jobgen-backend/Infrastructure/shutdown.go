@@ -0,0 +1,35 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ShutdownStage is one component to drain when the process stops.
+type ShutdownStage struct {
+	Name string
+	Stop func(ctx context.Context) error
+}
+
+// DrainInParallel stops the stages at the same time, each with its own timeout, so a slow
+// stage cannot use up the time another one needs. It returns every stage's error.
+func DrainInParallel(timeout time.Duration, stages ...ShutdownStage) error {
+	errs := make([]error, len(stages))
+	var wg sync.WaitGroup
+	for i, stage := range stages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if err := stage.Stop(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", stage.Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
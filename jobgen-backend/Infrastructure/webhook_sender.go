@@ -0,0 +1,132 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Webhook request headers. The signature covers "<timestamp>.<body>" so receivers can
+// reject replayed callbacks by checking the timestamp.
+const (
+	WebhookSignatureHeader = "X-JobGen-Signature"
+	WebhookTimestampHeader = "X-JobGen-Timestamp"
+	WebhookEventHeader     = "X-JobGen-Event"
+)
+
+// WebhookSender posts signed JSON callbacks to user-registered URLs.
+type WebhookSender interface {
+	Send(ctx context.Context, url, secret, event string, payload []byte) error
+}
+
+// ErrWebhookAddressBlocked is returned when a callback would connect to a loopback, private,
+// link-local, multicast or unspecified address.
+var ErrWebhookAddressBlocked = errors.New("webhook address is not publicly routable")
+
+// maxWebhookRedirects bounds how far a callback may be redirected.
+const maxWebhookRedirects = 3
+
+// BlockedWebhookIP reports whether callbacks must not reach ip because it belongs to this
+// host or its private network.
+func BlockedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+type webhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender checks every address a callback connects to after DNS resolution, for
+// redirects too, so a registered hostname cannot later resolve into our own network.
+// allowedNetworks exempts ranges that are otherwise blocked, e.g. a receiver in the same
+// cluster.
+func NewWebhookSender(timeout time.Duration, allowedNetworks ...*net.IPNet) WebhookSender {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("%w: %s", ErrWebhookAddressBlocked, host)
+			}
+			for _, allowed := range allowedNetworks {
+				if allowed.Contains(ip) {
+					return nil
+				}
+			}
+			if BlockedWebhookIP(ip) {
+				return fmt.Errorf("%w: %s", ErrWebhookAddressBlocked, ip)
+			}
+			return nil
+		},
+	}
+	return &webhookSender{client: &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy: the guard must see the callback's own address
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          20,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+		},
+		// Redirect targets go through the same dial guard
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxWebhookRedirects {
+				return fmt.Errorf("webhook redirected more than %d times", maxWebhookRedirects)
+			}
+			if req.URL.Scheme != "https" && req.URL.Scheme != "http" {
+				return fmt.Errorf("webhook redirected to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}}
+}
+
+func (s *webhookSender) Send(ctx context.Context, url, secret, event string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "JobGen-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(secret, timestamp, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint responded with HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of "<timestamp>.<payload>" keyed by secret.
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	domain "jobgen-backend/Domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AIUsageRepository keeps one document per user, day and operation with running totals.
+type AIUsageRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAIUsageRepository(db *mongo.Database) domain.IAIUsageRepository {
+	repo := &AIUsageRepository{
+		collection: db.Collection("ai_usage"),
+	}
+
+	repo.createIndexes()
+
+	return repo
+}
+
+func (r *AIUsageRepository) createIndexes() {
+	ctx := context.Background()
+
+	r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "day", Value: 1}, {Key: "operation", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "day", Value: 1}}},
+	})
+}
+
+func (r *AIUsageRepository) Add(ctx context.Context, record domain.AIUsageRecord) error {
+	filter := bson.M{"userId": record.UserID, "day": record.Day, "operation": record.Operation}
+	update := bson.M{"$inc": bson.M{
+		"requests":         record.Usage.Requests,
+		"failures":         record.Usage.Failures,
+		"retries":          record.Usage.Retries,
+		"cacheHits":        record.Usage.CacheHits,
+		"deduplicated":     record.Usage.Deduplicated,
+		"promptTokens":     record.Usage.PromptTokens,
+		"completionTokens": record.Usage.CompletionTokens,
+		"costUsd":          record.CostUSD,
+	}}
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *AIUsageRepository) List(ctx context.Context, filter domain.AIUsageFilter) ([]domain.AIUsageRecord, error) {
+	query := bson.M{}
+	if filter.UserID != "" {
+		query["userId"] = filter.UserID
+	}
+	// Days are stored as YYYY-MM-DD, so they compare correctly as strings
+	days := bson.M{}
+	if filter.FromDay != "" {
+		days["$gte"] = filter.FromDay
+	}
+	if filter.ToDay != "" {
+		days["$lte"] = filter.ToDay
+	}
+	if len(days) > 0 {
+		query["day"] = days
+	}
+
+	cursor, err := r.collection.Find(ctx, query, options.Find().SetProjection(bson.M{"_id": 0}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	records := []domain.AIUsageRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
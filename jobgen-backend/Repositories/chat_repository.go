@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"jobgen-backend/Domain"
@@ -17,7 +18,26 @@ type chatRepository struct {
 }
 
 func NewChatRepository(db *mongo.Database) domain.IChatRepository {
-	return &chatRepository{db: db}
+	repo := &chatRepository{db: db}
+	repo.createIndexes()
+	return repo
+}
+
+func (r *chatRepository) createIndexes() {
+	ctx := context.Background()
+
+	// Messages are paged back from the newest one
+	r.db.Collection("chat_messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "session_id", Value: 1}, {Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}},
+	})
+	// Full-text search across a user's messages
+	r.db.Collection("chat_messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "content", Value: "text"}},
+	})
+	// Session lists show pinned sessions first, then the most recently active
+	r.db.Collection("chat_sessions").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "pinned", Value: -1}, {Key: "updated_at", Value: -1}},
+	})
 }
 
 func (r *chatRepository) CreateSession(ctx context.Context, session *domain.ChatSession) error {
@@ -36,6 +56,9 @@ func (r *chatRepository) GetSession(ctx context.Context, sessionID, userID strin
 		"user_id": userID,
 	}).Decode(&session)
 	
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -50,13 +73,44 @@ func (r *chatRepository) UpdateSession(ctx context.Context, session *domain.Chat
 	return err
 }
 
-func (r *chatRepository) GetUserSessions(ctx context.Context, userID string, limit, offset int) ([]domain.ChatSession, error) {
+// RecordExchange increments the message count rather than writing the loaded session back, so
+// messages saved by another request and settings changed meanwhile are kept.
+func (r *chatRepository) RecordExchange(ctx context.Context, sessionID, userID string, exchange domain.ChatExchange) (*domain.ChatSession, error) {
+	set := bson.M{
+		"updated_at":       time.Now(),
+		"summary":          exchange.Summary,
+		"summarized_count": exchange.SummarizedCount,
+	}
+	if exchange.Title != "" {
+		set["title"] = exchange.Title
+	}
+
+	var session domain.ChatSession
+	err := r.db.Collection("chat_sessions").FindOneAndUpdate(ctx,
+		bson.M{"_id": sessionID, "user_id": userID},
+		bson.M{"$inc": bson.M{"message_count": exchange.Messages}, "$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&session)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *chatRepository) GetUserSessions(ctx context.Context, userID string, archived bool, limit, offset int) ([]domain.ChatSession, error) {
 	opts := options.Find().
-		SetSort(bson.M{"updated_at": -1}).
+		SetSort(bson.D{{Key: "pinned", Value: -1}, {Key: "updated_at", Value: -1}}).
 		SetLimit(int64(limit)).
 		SetSkip(int64(offset))
 	
-	cursor, err := r.db.Collection("chat_sessions").Find(ctx, bson.M{"user_id": userID}, opts)
+	filter := bson.M{"user_id": userID, "archived": bson.M{"$ne": true}}
+	if archived {
+		filter["archived"] = true
+	}
+	cursor, err := r.db.Collection("chat_sessions").Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +123,34 @@ func (r *chatRepository) GetUserSessions(ctx context.Context, userID string, lim
 	return sessions, nil
 }
 
+func (r *chatRepository) UpdateSessionSettings(ctx context.Context, sessionID, userID string, update domain.ChatSessionUpdate) (*domain.ChatSession, error) {
+	set := bson.M{}
+	if update.Title != nil {
+		set["title"] = *update.Title
+	}
+	if update.Pinned != nil {
+		set["pinned"] = *update.Pinned
+	}
+	if update.Archived != nil {
+		set["archived"] = *update.Archived
+	}
+	
+	// updated_at is left alone so a rename or pin does not reorder the session list
+	var session domain.ChatSession
+	err := r.db.Collection("chat_sessions").FindOneAndUpdate(ctx,
+		bson.M{"_id": sessionID, "user_id": userID},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&session)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
 func (r *chatRepository) SaveMessage(ctx context.Context, message *domain.ChatMessage) error {
 	message.ID = primitive.NewObjectID().Hex()
 	message.Timestamp = time.Now()
@@ -77,7 +159,7 @@ func (r *chatRepository) SaveMessage(ctx context.Context, message *domain.ChatMe
 	return err
 }
 
-func (r *chatRepository) GetSessionMessages(ctx context.Context, sessionID, userID string, limit int) ([]domain.ChatMessage, error) {
+func (r *chatRepository) GetSessionMessages(ctx context.Context, sessionID, userID string, limit, offset int) ([]domain.ChatMessage, error) {
 	// Verify the session belongs to the user
 	var session domain.ChatSession
 	err := r.db.Collection("chat_sessions").FindOne(ctx, bson.M{
@@ -89,7 +171,12 @@ func (r *chatRepository) GetSessionMessages(ctx context.Context, sessionID, user
 		return nil, err
 	}
 	
-	opts := options.Find().SetSort(bson.M{"timestamp": 1})
+	// Newest first so the page can be counted back from the latest message; the user's
+	// message and the reply can share a timestamp, so the ID breaks ties
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}})
+	if offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
 	if limit > 0 {
 		opts.SetLimit(int64(limit))
 	}
@@ -104,6 +191,9 @@ func (r *chatRepository) GetSessionMessages(ctx context.Context, sessionID, user
 	if err = cursor.All(ctx, &messages); err != nil {
 		return nil, err
 	}
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
 	return messages, nil
 }
 
@@ -117,13 +207,17 @@ func (r *chatRepository) DeleteSession(ctx context.Context, sessionID, userID st
 	
 	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
 		// Delete the session
-		_, err := r.db.Collection("chat_sessions").DeleteOne(sessCtx, bson.M{
+		res, err := r.db.Collection("chat_sessions").DeleteOne(sessCtx, bson.M{
 			"_id":     sessionID,
 			"user_id": userID,
 		})
 		if err != nil {
 			return nil, err
 		}
+		if res.DeletedCount == 0 {
+			// Not the user's session; leave its messages alone
+			return nil, domain.ErrNotFound
+		}
 		
 		// Delete all messages in the session
 		_, err = r.db.Collection("chat_messages").DeleteMany(sessCtx, bson.M{
@@ -134,3 +228,48 @@ func (r *chatRepository) DeleteSession(ctx context.Context, sessionID, userID st
 	
 	return err
 }
+
+func (r *chatRepository) SearchMessages(ctx context.Context, userID, query string, limit int) ([]domain.ChatSearchHit, error) {
+	// Messages do not store their owner, so search within the user's sessions
+	sessionCursor, err := r.db.Collection("chat_sessions").Find(ctx, bson.M{"user_id": userID},
+		options.Find().SetProjection(bson.M{"_id": 1, "title": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var sessions []domain.ChatSession
+	if err = sessionCursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return []domain.ChatSearchHit{}, nil
+	}
+	titles := make(map[string]string, len(sessions))
+	sessionIDs := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		titles[s.ID] = s.Title
+		sessionIDs = append(sessionIDs, s.ID)
+	}
+	
+	opts := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}, "cv_data": 0}).
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "timestamp", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.db.Collection("chat_messages").Find(ctx, bson.M{
+		"$text":      bson.M{"$search": query},
+		"session_id": bson.M{"$in": sessionIDs},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	
+	var messages []domain.ChatMessage
+	if err = cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	hits := make([]domain.ChatSearchHit, 0, len(messages))
+	for _, msg := range messages {
+		hits = append(hits, domain.ChatSearchHit{SessionID: msg.SessionID, SessionTitle: titles[msg.SessionID], Message: msg})
+	}
+	return hits, nil
+}
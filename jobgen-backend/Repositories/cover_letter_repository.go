@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	domain "jobgen-backend/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CoverLetterRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCoverLetterRepository(db *mongo.Database) domain.ICoverLetterRepository {
+	repo := &CoverLetterRepository{
+		collection: db.Collection("cover_letters"),
+	}
+
+	repo.createIndexes()
+
+	return repo
+}
+
+func (r *CoverLetterRepository) createIndexes() {
+	ctx := context.Background()
+
+	// One draft per version number for each user and job
+	r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "job_id", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	})
+}
+
+func (r *CoverLetterRepository) Create(ctx context.Context, draft *domain.CoverLetterDraft) error {
+	draft.ID = primitive.NewObjectID().Hex()
+	draft.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, draft)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDraftVersionConflict
+	}
+	return err
+}
+
+func (r *CoverLetterRepository) GetLatest(ctx context.Context, userID, jobID string) (*domain.CoverLetterDraft, error) {
+	var draft domain.CoverLetterDraft
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+	err := r.collection.FindOne(ctx, bson.M{"user_id": userID, "job_id": jobID}, opts).Decode(&draft)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (r *CoverLetterRepository) GetVersion(ctx context.Context, userID, jobID string, version int) (*domain.CoverLetterDraft, error) {
+	var draft domain.CoverLetterDraft
+	err := r.collection.FindOne(ctx, bson.M{"user_id": userID, "job_id": jobID, "version": version}).Decode(&draft)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (r *CoverLetterRepository) ListVersions(ctx context.Context, userID, jobID string) ([]domain.CoverLetterDraft, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID, "job_id": jobID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	drafts := []domain.CoverLetterDraft{}
+	if err := cursor.All(ctx, &drafts); err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
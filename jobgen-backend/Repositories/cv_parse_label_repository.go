@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	domain "jobgen-backend/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CVParseLabelRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCVParseLabelRepository(db *mongo.Database) domain.ICVParseLabelRepository {
+	repo := &CVParseLabelRepository{
+		collection: db.Collection("cv_parse_labels"),
+	}
+
+	repo.createIndexes()
+
+	return repo
+}
+
+func (r *CVParseLabelRepository) createIndexes() {
+	ctx := context.Background()
+
+	r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "field", Value: 1}, {Key: "createdAt", Value: -1}},
+	})
+	r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "cvId", Value: 1}},
+	})
+}
+
+func (r *CVParseLabelRepository) CreateMany(ctx context.Context, labels []domain.CVParseLabel) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	now := time.Now()
+	docs := make([]interface{}, len(labels))
+	for i := range labels {
+		labels[i].ID = primitive.NewObjectID().Hex()
+		labels[i].CreatedAt = now
+		docs[i] = labels[i]
+	}
+	_, err := r.collection.InsertMany(ctx, docs)
+	return err
+}
+
+func (r *CVParseLabelRepository) List(ctx context.Context, field string, limit int) ([]domain.CVParseLabel, error) {
+	filter := bson.M{}
+	if field != "" {
+		filter["field"] = field
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	labels := []domain.CVParseLabel{}
+	if err := cursor.All(ctx, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
@@ -18,12 +18,20 @@ type mongoCVRepository struct {
 func NewCVRepository(db *mongo.Database) (domain.CVRepository, error) {
 	collection := db.Collection("cvs")
 
+	// The lineage index used to be non-unique; replace it so versions cannot collide
+	_, _ = collection.Indexes().DropOne(context.Background(), "lineageId_1_version_1")
+
 	// Create indexes for performance and searching
 	_, err := collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
 		{
 			Keys:    bson.D{{Key: "userId", Value: 1}},
 			Options: options.Index().SetUnique(false),
 		},
+		{
+			Keys: bson.D{{Key: "lineageId", Value: 1}, {Key: "version", Value: 1}},
+			Options: options.Index().SetName("lineage_version_unique").SetUnique(true).
+				SetPartialFilterExpression(bson.M{"lineageId": bson.M{"$exists": true}}),
+		},
 		{
 			Keys:    bson.D{{Key: "rawText", Value: "text"}, {Key: "skills", Value: "text"}},
 			Options: options.Index().SetName("TextSearchIndex"),
@@ -40,13 +48,22 @@ func NewCVRepository(db *mongo.Database) (domain.CVRepository, error) {
 
 func (r *mongoCVRepository) Create(cv *domain.CV) error {
 	_, err := r.collection.InsertOne(context.Background(), cv)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrCVVersionConflict
+	}
 	return err
 }
 
 func (r *mongoCVRepository) GetByID(id string) (*domain.CV, error) {
 	var cv domain.CV
 	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&cv)
-	return &cv, err
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cv, nil
 }
 
 func (r *mongoCVRepository) UpdateStatus(id string, status domain.JobStatus, procError ...string) error {
@@ -56,8 +73,11 @@ func (r *mongoCVRepository) UpdateStatus(id string, status domain.JobStatus, pro
 			"updatedAt": time.Now().UTC(),
 		},
 	}
+	// A message left by an earlier attempt must not outlive the status it described
 	if len(procError) > 0 && procError[0] != "" {
 		update["$set"].(bson.M)["processingError"] = procError[0]
+	} else {
+		update["$unset"] = bson.M{"processingError": ""}
 	}
 
 	_, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": id}, update)
@@ -67,17 +87,115 @@ func (r *mongoCVRepository) UpdateStatus(id string, status domain.JobStatus, pro
 func (r *mongoCVRepository) UpdateWithResults(id string, results *domain.CV) error {
 	update := bson.M{
 		"$set": bson.M{
-			"status":         domain.StatusCompleted,
-			"rawText":        results.RawText,
-			"profileSummary": results.ProfileSummary,
-			"experiences":    results.Experiences,
-			"educations":     results.Educations,
-			"skills":         results.Skills,
-			"suggestions":    results.Suggestions,
-			"score":          results.Score,
-			"updatedAt":      time.Now().UTC(),
+			"status":          domain.StatusCompleted,
+			"rawText":         results.RawText,
+			"profileSummary":  results.ProfileSummary,
+			"experiences":     results.Experiences,
+			"educations":      results.Educations,
+			"skills":          results.Skills,
+			"contact":         results.Contact,
+			"languages":       results.Languages,
+			"certifications":  results.Certifications,
+			"projects":        results.Projects,
+			"fieldConfidence": results.FieldConfidence,
+			"reviewFields":    results.ReviewFields,
+			"fieldSources":    results.FieldSources,
+			"suggestions":     results.Suggestions,
+			"score":           results.Score,
+			"processingError": results.ProcessingError,
+			"updatedAt":       time.Now().UTC(),
 		},
 	}
 	_, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": id}, update)
 	return err
 }
+
+func (r *mongoCVRepository) ListByUser(userID string) ([]domain.CV, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := r.collection.Find(context.Background(), bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	cvs := []domain.CV{}
+	if err := cursor.All(context.Background(), &cvs); err != nil {
+		return nil, err
+	}
+	return cvs, nil
+}
+
+func (r *mongoCVRepository) ListByStatus(statuses ...domain.JobStatus) ([]domain.CV, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := r.collection.Find(context.Background(), bson.M{"status": bson.M{"$in": statuses}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	cvs := []domain.CV{}
+	if err := cursor.All(context.Background(), &cvs); err != nil {
+		return nil, err
+	}
+	return cvs, nil
+}
+
+// lineageFilter matches every version of a CV, including the pre-versioning root record
+// which has no lineageId of its own.
+func lineageFilter(lineageID string) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"lineageId": lineageID},
+		bson.M{"_id": lineageID, "lineageId": bson.M{"$exists": false}},
+	}}
+}
+
+func (r *mongoCVRepository) ListVersions(lineageID string) ([]domain.CV, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}, {Key: "createdAt", Value: 1}})
+	cursor, err := r.collection.Find(context.Background(), lineageFilter(lineageID), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	cvs := []domain.CV{}
+	if err := cursor.All(context.Background(), &cvs); err != nil {
+		return nil, err
+	}
+	return cvs, nil
+}
+
+func (r *mongoCVRepository) SetPrimary(userID, lineageID string) error {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"userId": userID, "isPrimary": true},
+		bson.M{"$set": bson.M{"isPrimary": false, "updatedAt": now}})
+	if err != nil {
+		return err
+	}
+
+	filter := lineageFilter(lineageID)
+	filter["userId"] = userID
+	filter["origin"] = bson.M{"$ne": domain.OriginTailored}
+	result, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"isPrimary": true, "updatedAt": now}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoCVRepository) UpdateSuggestions(id string, suggestions []domain.Suggestion) error {
+	update := bson.M{"$set": bson.M{"suggestions": suggestions, "updatedAt": time.Now().UTC()}}
+	result, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	domain "jobgen-backend/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CVWebhookRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCVWebhookRepository(db *mongo.Database) domain.ICVWebhookRepository {
+	repo := &CVWebhookRepository{
+		collection: db.Collection("cv_webhooks"),
+	}
+
+	repo.createIndexes()
+
+	return repo
+}
+
+func (r *CVWebhookRepository) createIndexes() {
+	ctx := context.Background()
+
+	r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: 1}},
+	})
+}
+
+func (r *CVWebhookRepository) Create(ctx context.Context, hook *domain.CVWebhook) error {
+	_, err := r.collection.InsertOne(ctx, hook)
+	return err
+}
+
+func (r *CVWebhookRepository) ListByUser(ctx context.Context, userID string) ([]domain.CVWebhook, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	hooks := []domain.CVWebhook{}
+	if err := cursor.All(ctx, &hooks); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+func (r *CVWebhookRepository) Delete(ctx context.Context, userID, id string) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "userId": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *CVWebhookRepository) RecordDelivery(ctx context.Context, id string, at time.Time, deliveryErr string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"lastDeliveryAt":    at,
+		"lastDeliveryError": deliveryErr,
+	}})
+	return err
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	domain "jobgen-backend/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type JobFeedbackRepository struct {
+	collection        *mongo.Collection
+	weightsCollection *mongo.Collection
+}
+
+func NewJobFeedbackRepository(db *mongo.Database) domain.IJobFeedbackRepository {
+	repo := &JobFeedbackRepository{
+		collection:        db.Collection("job_feedback"),
+		weightsCollection: db.Collection("user_match_weights"),
+	}
+
+	repo.createIndexes()
+
+	return repo
+}
+
+func (r *JobFeedbackRepository) createIndexes() {
+	ctx := context.Background()
+
+	// One verdict per user and job
+	userJobIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "job_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+
+	// Index on match_score for the precision report
+	scoreIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "match_score", Value: 1}},
+	}
+
+	r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{userJobIndex, scoreIndex})
+}
+
+func (r *JobFeedbackRepository) Upsert(ctx context.Context, feedback *domain.JobFeedback) error {
+	now := time.Now()
+	feedback.UpdatedAt = now
+
+	filter := bson.M{"user_id": feedback.UserID, "job_id": feedback.JobID}
+	update := bson.M{
+		"$set": bson.M{
+			"type":        feedback.Type,
+			"reason":      feedback.Reason,
+			"match_score": feedback.MatchScore,
+			"adjustment":  feedback.Adjustment,
+			"updated_at":  feedback.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID().Hex(),
+			"created_at": now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	return r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(feedback)
+}
+
+func (r *JobFeedbackRepository) Get(ctx context.Context, userID, jobID string) (*domain.JobFeedback, error) {
+	var feedback domain.JobFeedback
+	err := r.collection.FindOne(ctx, bson.M{"user_id": userID, "job_id": jobID}).Decode(&feedback)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &feedback, nil
+}
+
+func (r *JobFeedbackRepository) GetDismissedJobIDs(ctx context.Context, userID string) ([]string, error) {
+	filter := bson.M{
+		"user_id": userID,
+		"type":    bson.M{"$in": []domain.FeedbackType{domain.FeedbackThumbsDown, domain.FeedbackNotInterested}},
+	}
+	opts := options.Find().SetProjection(bson.M{"job_id": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		JobID string `bson:"job_id"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.JobID)
+	}
+	return ids, nil
+}
+
+func (r *JobFeedbackRepository) GetWeights(ctx context.Context, userID string) (*domain.MatchWeights, error) {
+	var weights domain.MatchWeights
+	err := r.weightsCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(&weights)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &weights, nil
+}
+
+func (r *JobFeedbackRepository) SaveWeights(ctx context.Context, weights *domain.MatchWeights) error {
+	weights.UpdatedAt = time.Now()
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.weightsCollection.ReplaceOne(ctx, bson.M{"_id": weights.UserID}, weights, opts)
+	return err
+}
+
+// GetScoreBands buckets feedback by the match score it was given at. boundaries must be sorted
+// ascending; the last boundary is exclusive.
+func (r *JobFeedbackRepository) GetScoreBands(ctx context.Context, boundaries []float64) ([]domain.MatchScoreBand, error) {
+	if len(boundaries) < 2 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	countType := func(t domain.FeedbackType) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$type", t}}, 1, 0}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$bucket", Value: bson.M{
+			"groupBy":    "$match_score",
+			"boundaries": boundaries,
+			"default":    "out_of_range",
+			"output": bson.M{
+				"positive":  countType(domain.FeedbackThumbsUp),
+				"negative":  countType(domain.FeedbackThumbsDown),
+				"dismissed": countType(domain.FeedbackNotInterested),
+				"total":     bson.M{"$sum": 1},
+			},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ID        interface{} `bson:"_id"`
+		Positive  int64       `bson:"positive"`
+		Negative  int64       `bson:"negative"`
+		Dismissed int64       `bson:"dismissed"`
+		Total     int64       `bson:"total"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	// Emit every band, including empty ones, so the report shape is stable
+	bands := make([]domain.MatchScoreBand, len(boundaries)-1)
+	for i := range bands {
+		bands[i].MinScore = boundaries[i]
+		bands[i].MaxScore = boundaries[i+1]
+	}
+	for _, row := range rows {
+		lower, ok := row.ID.(float64)
+		if !ok {
+			if n, isInt := row.ID.(int32); isInt {
+				lower = float64(n)
+			} else {
+				continue // out_of_range bucket
+			}
+		}
+		for i := range bands {
+			if bands[i].MinScore == lower {
+				bands[i].Positive = row.Positive
+				bands[i].Negative = row.Negative
+				bands[i].Dismissed = row.Dismissed
+				bands[i].Total = row.Total
+				break
+			}
+		}
+	}
+	return bands, nil
+}
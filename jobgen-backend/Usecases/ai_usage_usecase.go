@@ -0,0 +1,157 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	domain "jobgen-backend/Domain"
+	"sort"
+	"time"
+)
+
+// aiUsageDayLayout is the format of the days usage is recorded under (UTC).
+const aiUsageDayLayout = "2006-01-02"
+
+// AIUsageUsecase meters the AI calls made for users and reports on them.
+type AIUsageUsecase interface {
+	domain.IAIUsageMeter
+	Report(ctx context.Context, filter domain.AIUsageFilter) (*domain.AIUsageReport, error)
+}
+
+type aiUsageUsecase struct {
+	repo     domain.IAIUsageRepository
+	userRepo domain.IUserRepository
+	quotas   map[domain.Role]domain.AIQuota
+	pricing  domain.AIPricing
+	now      func() time.Time
+}
+
+// NewAIUsageUsecase creates the meter. Roles without an entry in quotas are unlimited.
+func NewAIUsageUsecase(repo domain.IAIUsageRepository, userRepo domain.IUserRepository, quotas map[domain.Role]domain.AIQuota, pricing domain.AIPricing) AIUsageUsecase {
+	return &aiUsageUsecase{repo: repo, userRepo: userRepo, quotas: quotas, pricing: pricing, now: time.Now}
+}
+
+// Allow checks the user's usage today and this month against the quota of their role. The
+// check happens before the call, so concurrent calls can overshoot a quota slightly.
+func (uc *aiUsageUsecase) Allow(ctx context.Context, userID string) error {
+	quota, err := uc.quotaFor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if quota == (domain.AIQuota{}) {
+		return nil
+	}
+
+	now := uc.now().UTC()
+	today := now.Format(aiUsageDayLayout)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	records, err := uc.repo.List(ctx, domain.AIUsageFilter{UserID: userID, FromDay: monthStart.Format(aiUsageDayLayout), ToDay: today})
+	if err != nil {
+		return fmt.Errorf("failed to load ai usage: %w", err)
+	}
+	var daily, monthly domain.AIUsage
+	for _, r := range records {
+		monthly.Add(r.Usage)
+		if r.Day == today {
+			daily.Add(r.Usage)
+		}
+	}
+
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	nextMonth := monthStart.AddDate(0, 1, 0)
+	for _, check := range []domain.AIQuotaError{
+		{Period: "daily", Unit: "requests", Limit: quota.DailyRequests, Used: daily.Requests, ResetAt: tomorrow},
+		{Period: "daily", Unit: "tokens", Limit: quota.DailyTokens, Used: daily.Tokens(), ResetAt: tomorrow},
+		{Period: "monthly", Unit: "requests", Limit: quota.MonthlyRequests, Used: monthly.Requests, ResetAt: nextMonth},
+		{Period: "monthly", Unit: "tokens", Limit: quota.MonthlyTokens, Used: monthly.Tokens(), ResetAt: nextMonth},
+	} {
+		if check.Limit > 0 && check.Used >= check.Limit {
+			quotaErr := check
+			return &quotaErr
+		}
+	}
+	return nil
+}
+
+// quotaFor returns the quota of the user's role; unknown users get the user role's quota.
+func (uc *aiUsageUsecase) quotaFor(ctx context.Context, userID string) (domain.AIQuota, error) {
+	role := domain.RoleUser
+	if uc.userRepo != nil {
+		user, err := uc.userRepo.GetByID(ctx, userID)
+		if err != nil && err != domain.ErrUserNotFound {
+			return domain.AIQuota{}, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user != nil && user.Role != "" {
+			role = user.Role
+		}
+	}
+	return uc.quotas[role], nil
+}
+
+// Record adds the usage of one call to today's totals for the user and operation.
+func (uc *aiUsageUsecase) Record(ctx context.Context, userID, operation string, usage domain.AIUsage) error {
+	return uc.repo.Add(ctx, domain.AIUsageRecord{
+		UserID:    userID,
+		Day:       uc.now().UTC().Format(aiUsageDayLayout),
+		Operation: operation,
+		Usage:     usage,
+		CostUSD:   uc.pricing.Cost(usage),
+	})
+}
+
+// Report sums usage per user between two days, inclusive. The period defaults to the
+// current month.
+func (uc *aiUsageUsecase) Report(ctx context.Context, filter domain.AIUsageFilter) (*domain.AIUsageReport, error) {
+	now := uc.now().UTC()
+	if filter.FromDay == "" {
+		filter.FromDay = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format(aiUsageDayLayout)
+	}
+	if filter.ToDay == "" {
+		filter.ToDay = now.Format(aiUsageDayLayout)
+	}
+	from, err := time.Parse(aiUsageDayLayout, filter.FromDay)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from must be a date like 2024-01-31", domain.ErrInvalidInput)
+	}
+	to, err := time.Parse(aiUsageDayLayout, filter.ToDay)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to must be a date like 2024-01-31", domain.ErrInvalidInput)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: from must not be after to", domain.ErrInvalidInput)
+	}
+
+	records, err := uc.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ai usage: %w", err)
+	}
+
+	report := &domain.AIUsageReport{From: filter.FromDay, To: filter.ToDay, Users: []domain.AIUserUsage{}}
+	byUser := make(map[string]*domain.AIUserUsage)
+	var order []string
+	for _, r := range records {
+		u := byUser[r.UserID]
+		if u == nil {
+			u = &domain.AIUserUsage{UserID: r.UserID, Operations: make(map[string]domain.AIUsage)}
+			byUser[r.UserID] = u
+			order = append(order, r.UserID)
+		}
+		op := u.Operations[r.Operation]
+		op.Add(r.Usage)
+		u.Operations[r.Operation] = op
+		u.Total.Add(r.Usage)
+		u.CostUSD += r.CostUSD
+		report.Total.Add(r.Usage)
+		report.CostUSD += r.CostUSD
+	}
+	for _, userID := range order {
+		report.Users = append(report.Users, *byUser[userID])
+	}
+	sort.SliceStable(report.Users, func(i, j int) bool {
+		a, b := report.Users[i], report.Users[j]
+		if a.CostUSD != b.CostUSD {
+			return a.CostUSD > b.CostUSD
+		}
+		return a.Total.Tokens() > b.Total.Tokens()
+	})
+	return report, nil
+}
@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	domain "jobgen-backend/Domain"
+)
+
+// maxSessionTitleLength bounds titles users give their sessions.
+const maxSessionTitleLength = 100
+
+func (u *chatUsecase) GetUserSessions(ctx context.Context, userID string, archived bool, limit, offset int) ([]domain.ChatSession, error) {
+	return u.chatRepo.GetUserSessions(ctx, userID, archived, limit, offset)
+}
+
+// UpdateSessionSettings renames, pins or archives one of the user's sessions.
+func (u *chatUsecase) UpdateSessionSettings(ctx context.Context, sessionID, userID string, update domain.ChatSessionUpdate) (*domain.ChatSession, error) {
+	if update.Title == nil && update.Pinned == nil && update.Archived == nil {
+		return nil, fmt.Errorf("%w: nothing to update", domain.ErrInvalidInput)
+	}
+	if update.Title != nil {
+		title := strings.Join(strings.Fields(*update.Title), " ")
+		if title == "" {
+			return nil, fmt.Errorf("%w: title cannot be empty", domain.ErrInvalidInput)
+		}
+		if len([]rune(title)) > maxSessionTitleLength {
+			return nil, fmt.Errorf("%w: title must be at most %d characters", domain.ErrInvalidInput, maxSessionTitleLength)
+		}
+		update.Title = &title
+	}
+	return u.chatRepo.UpdateSessionSettings(ctx, sessionID, userID, update)
+}
+
+func (u *chatUsecase) DeleteSession(ctx context.Context, sessionID, userID string) error {
+	return u.chatRepo.DeleteSession(ctx, sessionID, userID)
+}
+
+// SearchMessages searches the text of every message in the user's sessions.
+func (u *chatUsecase) SearchMessages(ctx context.Context, userID, query string, limit int) ([]domain.ChatSearchHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: search query is required", domain.ErrInvalidInput)
+	}
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+	return u.chatRepo.SearchMessages(ctx, userID, query, limit)
+}
+
+// ExportSession renders a whole session as a transcript (Markdown, plain text or PDF) or as
+// JSON holding the session and its messages.
+func (u *chatUsecase) ExportSession(ctx context.Context, sessionID, userID string, format domain.ExportFormat) (*domain.ExportedDocument, error) {
+	switch format {
+	case domain.ExportMarkdown, domain.ExportPlainText, domain.ExportPDF, domain.ExportJSON:
+	default:
+		return nil, fmt.Errorf("%w: unsupported export format %q", domain.ErrInvalidInput, format)
+	}
+	session, err := u.chatRepo.GetSession(ctx, sessionID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	messages, err := u.chatRepo.GetSessionMessages(ctx, sessionID, userID, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+	baseName := "chat-" + slugify(session.Title)
+	if baseName == "chat-" {
+		baseName = "chat-" + session.ID
+	}
+
+	if format == domain.ExportJSON {
+		data, err := json.MarshalIndent(struct {
+			Session  *domain.ChatSession  `json:"session"`
+			Messages []domain.ChatMessage `json:"messages"`
+		}{session, messages}, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode session: %w", err)
+		}
+		return &domain.ExportedDocument{FileName: baseName + ".json", ContentType: "application/json", Data: data}, nil
+	}
+
+	if u.exporter == nil {
+		return nil, fmt.Errorf("%w: only json export is available", domain.ErrInvalidInput)
+	}
+	doc, err := u.exporter.Export(baseName, session.Title, chatTranscript(session, messages), format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export session: %w", err)
+	}
+	return doc, nil
+}
+
+// chatTranscript writes a session's messages as Markdown.
+func chatTranscript(session *domain.ChatSession, messages []domain.ChatMessage) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Started %s\n", session.CreatedAt.Format("2 January 2006"))
+	for _, msg := range messages {
+		speaker := "You"
+		if msg.Role == "assistant" {
+			speaker = "JobGen"
+		}
+		fmt.Fprintf(&sb, "\n## %s (%s)\n\n%s\n", speaker, msg.Timestamp.Format(time.DateTime), strings.TrimSpace(msg.Content))
+		if msg.Partial {
+			sb.WriteString("\n*This reply was interrupted.*\n")
+		}
+		if len(msg.Suggestions) > 0 {
+			sb.WriteString("\nSuggestions:\n\n")
+		}
+		for _, sg := range msg.Suggestions {
+			fmt.Fprintf(&sb, "- %s\n", sg.Content)
+		}
+	}
+	return sb.String()
+}
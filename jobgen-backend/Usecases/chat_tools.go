@@ -0,0 +1,352 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	domain "jobgen-backend/Domain"
+)
+
+// maxToolJobs caps how many jobs a tool returns to the model.
+const maxToolJobs = 10
+
+// chatToolset is the set of tools available while answering one message. It remembers every
+// job a tool returned, which are the only jobs the reply may cite.
+type chatToolset struct {
+	uc     *chatUsecase
+	userID string
+	cited  map[string]domain.Job
+}
+
+// toolJob is the compact form of a job sent to the model.
+type toolJob struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Company     string   `json:"company"`
+	Location    string   `json:"location,omitempty"`
+	Skills      []string `json:"skills,omitempty"`
+	Salary      string   `json:"salary,omitempty"`
+	PostedAt    string   `json:"posted_at,omitempty"`
+	MatchScore  *float64 `json:"match_score,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+func newChatToolset(uc *chatUsecase, userID string) *chatToolset {
+	return &chatToolset{uc: uc, userID: userID, cited: make(map[string]domain.Job)}
+}
+
+// tools lists the tools whose dependencies are configured.
+func (t *chatToolset) tools() []domain.ChatTool {
+	var tools []domain.ChatTool
+	if t.uc.jobUsecase != nil {
+		tools = append(tools,
+			domain.ChatTool{
+				Name:        "search_jobs",
+				Description: "Searches the job listings.",
+				Parameters:  `{"query": "keywords, optional", "skills": ["skill, optional"], "location": "optional", "limit": "1-10, default 5"}`,
+				Run:         t.searchJobs,
+			},
+			domain.ChatTool{
+				Name:        "get_matched_jobs",
+				Description: "Returns the jobs that best match the user's CV and profile.",
+				Parameters:  `{"limit": "1-10, default 5"}`,
+				Run:         t.matchedJobs,
+			},
+		)
+	}
+	if t.uc.cvRepo != nil {
+		tools = append(tools, domain.ChatTool{
+			Name:        "get_my_cv",
+			Description: "Returns the user's latest parsed CV: summary, skills, experience and education.",
+			Parameters:  `{}`,
+			Run:         t.myCV,
+		})
+	}
+	if t.uc.jobUsecase != nil && t.uc.cvRepo != nil {
+		tools = append(tools, domain.ChatTool{
+			Name:        "analyze_job_fit",
+			Description: "Compares the user's CV with a job: matching and missing skills, experience gap and fit score.",
+			Parameters:  `{"job_id": "id of a job returned by another tool", "cv_id": "optional, defaults to the latest CV"}`,
+			Run:         t.analyzeJobFit,
+		})
+	}
+	return tools
+}
+
+func (t *chatToolset) searchJobs(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Query    string   `json:"query"`
+		Skills   []string `json:"skills"`
+		Location string   `json:"location"`
+		Limit    int      `json:"limit"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	resp, err := t.uc.jobUsecase.GetJobs(ctx, domain.JobFilter{
+		Query:    strings.TrimSpace(args.Query),
+		Skills:   args.Skills,
+		Location: strings.TrimSpace(args.Location),
+		Page:     1,
+		Limit:    toolLimit(args.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"total": resp.Total, "jobs": t.cite(resp.Jobs)}, nil
+}
+
+func (t *chatToolset) matchedJobs(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Limit int `json:"limit"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	resp, err := t.uc.jobUsecase.GetMatchedJobs(ctx, t.userID, toolLimit(args.Limit), 0)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"jobs": t.cite(resp.Jobs)}, nil
+}
+
+func (t *chatToolset) myCV(_ context.Context, _ json.RawMessage) (interface{}, error) {
+	cv, err := t.latestCV()
+	if err != nil {
+		return nil, err
+	}
+	type experience struct {
+		Title       string `json:"title"`
+		Company     string `json:"company"`
+		Start       string `json:"start,omitempty"`
+		End         string `json:"end,omitempty"`
+		Description string `json:"description,omitempty"`
+	}
+	type education struct {
+		Degree      string `json:"degree"`
+		Institution string `json:"institution"`
+	}
+	out := struct {
+		CVID        string       `json:"cv_id"`
+		Summary     string       `json:"summary,omitempty"`
+		Skills      []string     `json:"skills,omitempty"`
+		Experiences []experience `json:"experiences,omitempty"`
+		Educations  []education  `json:"educations,omitempty"`
+	}{CVID: cv.ID, Summary: cv.ProfileSummary, Skills: cv.Skills}
+	for _, exp := range cv.Experiences {
+		e := experience{Title: exp.Title, Company: exp.Company, Start: formatToolDate(exp.StartDate), Description: truncateString(exp.Description, 300)}
+		if exp.EndDate != nil {
+			e.End = formatToolDate(*exp.EndDate)
+		}
+		out.Experiences = append(out.Experiences, e)
+	}
+	for _, edu := range cv.Educations {
+		out.Educations = append(out.Educations, education{Degree: edu.Degree, Institution: edu.Institution})
+	}
+	return out, nil
+}
+
+func (t *chatToolset) analyzeJobFit(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		JobID string `json:"job_id"`
+		CVID  string `json:"cv_id"`
+	}
+	if err := decodeToolArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.JobID) == "" {
+		return nil, errors.New("job_id is required")
+	}
+	if args.CVID == "" {
+		cv, err := t.latestCV()
+		if err != nil {
+			return nil, err
+		}
+		args.CVID = cv.ID
+	}
+
+	job, err := t.uc.jobUsecase.GetJobByID(ctx, args.JobID)
+	if err != nil {
+		return nil, fmt.Errorf("job %s not found", args.JobID)
+	}
+	analysis, err := t.uc.jobUsecase.AnalyzeJobGap(ctx, t.userID, job.ID, args.CVID, false)
+	if err != nil {
+		return nil, err
+	}
+	t.cite([]domain.Job{*job})
+	return analysis, nil
+}
+
+// latestCV returns the user's primary parsed CV, or the newest parsed one.
+func (t *chatToolset) latestCV() (*domain.CV, error) {
+	cvs, err := t.uc.cvRepo.ListByUser(t.userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cvs: %w", err)
+	}
+	var latest *domain.CV
+	for i := range cvs {
+		cv := &cvs[i]
+		if !cv.IsParsed() || cv.IsTailored() {
+			continue
+		}
+		if latest == nil || (cv.IsPrimary && !latest.IsPrimary) ||
+			(cv.IsPrimary == latest.IsPrimary && cv.CreatedAt.After(latest.CreatedAt)) {
+			latest = cv
+		}
+	}
+	if latest == nil {
+		return nil, errors.New("the user has no parsed CV yet; suggest uploading one")
+	}
+	return latest, nil
+}
+
+// cite remembers jobs so the reply may cite them and returns their compact form.
+func (t *chatToolset) cite(jobs []domain.Job) []toolJob {
+	out := make([]toolJob, 0, len(jobs))
+	for _, job := range jobs {
+		t.cited[job.ID] = job
+		skills := job.ExtractedSkills
+		if len(skills) == 0 {
+			skills = job.Tags
+		}
+		out = append(out, toolJob{
+			ID:          job.ID,
+			Title:       job.Title,
+			Company:     job.CompanyName,
+			Location:    job.Location,
+			Skills:      skills,
+			Salary:      job.Salary,
+			PostedAt:    formatToolDate(job.PostedAt),
+			MatchScore:  job.MatchScore,
+			Description: truncateString(strings.TrimSpace(job.Description), 300),
+		})
+	}
+	return out
+}
+
+// jobCitationPattern matches a [job:<id>] citation along with the space before it.
+var jobCitationPattern = regexp.MustCompile(`(\s*)\[job:\s*([^\]\s]+)\s*\]`)
+
+// partialCitationPattern matches the start of a [job:<id>] citation that is not complete yet.
+var partialCitationPattern = regexp.MustCompile(`^\[(?:j(?:o(?:b(?::\s*[^\]\s]*\s*)?)?)?)?$`)
+
+// maxHeldCitation bounds how much text a stream holds back while waiting for a citation to end.
+const maxHeldCitation = 100
+
+// resolveCitations returns the reply without citations of jobs no tool returned, and the
+// cited jobs in the order they first appear.
+func (t *chatToolset) resolveCitations(reply string) (string, []domain.Job) {
+	var jobs []domain.Job
+	seen := make(map[string]bool)
+	cleaned := jobCitationPattern.ReplaceAllStringFunc(reply, func(marker string) string {
+		resolved, job := t.resolveCitation(marker)
+		if job != nil && !seen[job.ID] {
+			seen[job.ID] = true
+			jobs = append(jobs, *job)
+		}
+		return resolved
+	})
+	return cleaned, jobs
+}
+
+// resolveCitation returns what a single citation becomes in the reply and the job it cites,
+// which is nil when no tool returned that job.
+func (t *chatToolset) resolveCitation(marker string) (string, *domain.Job) {
+	match := jobCitationPattern.FindStringSubmatch(marker)
+	space, id := match[1], match[2]
+	job, ok := t.cited[id]
+	if !ok {
+		return "", nil
+	}
+	return space + "[job:" + id + "]", &job
+}
+
+// citationStream resolves citations in a streamed reply before passing it on. Text that may
+// still turn into a citation is held back until it does or cannot, so the deltas add up to
+// the reply resolveCitations returns.
+type citationStream struct {
+	tools   *chatToolset
+	next    func(delta string) error
+	pending string
+}
+
+func (t *chatToolset) streamCitations(next func(delta string) error) *citationStream {
+	return &citationStream{tools: t, next: next}
+}
+
+func (s *citationStream) write(delta string) error {
+	s.pending += delta
+	var out strings.Builder
+	for {
+		open := strings.IndexByte(s.pending, '[')
+		if open < 0 {
+			// Trailing space is held as it belongs to a citation that may follow
+			keep := len(strings.TrimRightFunc(s.pending, unicode.IsSpace))
+			out.WriteString(s.pending[:keep])
+			s.pending = s.pending[keep:]
+			break
+		}
+		start := len(strings.TrimRightFunc(s.pending[:open], unicode.IsSpace))
+		out.WriteString(s.pending[:start])
+		s.pending, open = s.pending[start:], open-start
+
+		if loc := jobCitationPattern.FindStringIndex(s.pending); loc != nil && loc[0] == 0 {
+			resolved, _ := s.tools.resolveCitation(s.pending[:loc[1]])
+			out.WriteString(resolved)
+			s.pending = s.pending[loc[1]:]
+			continue
+		}
+		if rest := s.pending[open:]; len(rest) <= maxHeldCitation && partialCitationPattern.MatchString(rest) {
+			break
+		}
+		out.WriteString(s.pending[:open+1])
+		s.pending = s.pending[open+1:]
+	}
+	if out.Len() == 0 {
+		return nil
+	}
+	return s.next(out.String())
+}
+
+// flush sends the text still held back once the reply is complete.
+func (s *citationStream) flush() error {
+	if s.pending == "" {
+		return nil
+	}
+	rest := s.pending
+	s.pending = ""
+	return s.next(rest)
+}
+
+func decodeToolArgs(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid arguments: %v", err)
+	}
+	return nil
+}
+
+func toolLimit(limit int) int {
+	if limit <= 0 {
+		return 5
+	}
+	if limit > maxToolJobs {
+		return maxToolJobs
+	}
+	return limit
+}
+
+func formatToolDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
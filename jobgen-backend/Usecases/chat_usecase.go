@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -11,146 +12,377 @@ import (
 )
 
 type chatUsecase struct {
-	chatRepo  domain.IChatRepository
-	aiService domain.IAIService
+	chatRepo      domain.IChatRepository
+	aiService     domain.IAIService
+	jobUsecase    domain.IJobUsecase  // optional; enables the job tools
+	cvRepo        domain.CVRepository // optional; enables the CV tools
+	exporter      domain.IDocumentExporter
+	historyTokens int // budget for the recent messages sent to the AI
 }
 
-func NewChatUsecase(chatRepo domain.IChatRepository, aiService domain.IAIService) domain.IChatUsecase {
+// defaultChatHistoryTokens is used when no history budget is configured.
+const defaultChatHistoryTokens = 3000
+
+// maxContextMessages caps the unsummarized messages loaded for one reply, so a session whose
+// summaries keep failing still sends a bounded history.
+const maxContextMessages = 50
+
+func NewChatUsecase(chatRepo domain.IChatRepository, aiService domain.IAIService, jobUsecase domain.IJobUsecase, cvRepo domain.CVRepository, exporter domain.IDocumentExporter, historyTokens int) domain.IChatUsecase {
+	if historyTokens <= 0 {
+		historyTokens = defaultChatHistoryTokens
+	}
 	return &chatUsecase{
-		chatRepo:  chatRepo,
-		aiService: aiService,
+		chatRepo:      chatRepo,
+		aiService:     aiService,
+		jobUsecase:    jobUsecase,
+		cvRepo:        cvRepo,
+		exporter:      exporter,
+		historyTokens: historyTokens,
 	}
 }
 
+// chatReply is the assistant's answer to a message.
+type chatReply struct {
+	text        string
+	suggestions []domain.Suggestion
+	jobs        []domain.Job // jobs the text cites
+	toolCalls   []domain.ChatToolCall
+}
+
 func (u *chatUsecase) SendMessage(ctx context.Context, req *domain.ChatRequest) (*domain.ChatResponse, error) {
-	// Get or create session
-	var session *domain.ChatSession
-	var err error
+	// AI calls made for this message count towards the user's quota
+	ctx = domain.WithAIUser(ctx, req.UserID)
 
-	if req.SessionID != "" {
-		session, err = u.chatRepo.GetSession(ctx, req.SessionID, req.UserID)
-		if err != nil {
-			return nil, fmt.Errorf("session not found: %v", err)
+	session, history, err := u.openSession(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := u.generateReply(ctx, req, history, nil)
+	if errors.Is(err, domain.ErrAIQuotaExceeded) {
+		// Nothing has been saved, so the user can send the message again once the quota resets
+		return nil, err
+	}
+	if err != nil {
+		// Do NOT fail the whole request. Surface a friendly message and continue.
+		log.Printf("AI generation error: %v", err)
+		reply = &chatReply{text: aiUnavailableReply}
+	}
+
+	aiMessage, err := u.saveExchange(ctx, session, req, reply, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ChatResponse{
+		SessionID:   session.ID,
+		MessageID:   aiMessage.ID,
+		Message:     reply.text,
+		Suggestions: reply.suggestions,
+		Jobs:        reply.jobs,
+		Session:     session,
+	}, nil
+}
+
+// SendMessageStream answers like SendMessage, passing the reply to onDelta as the model
+// generates it. If the client goes away or onDelta fails, the text generated so far is saved
+// as a partial reply and the error is returned.
+func (u *chatUsecase) SendMessageStream(ctx context.Context, req *domain.ChatRequest, onDelta func(delta string) error) (*domain.ChatResponse, error) {
+	ctx = domain.WithAIUser(ctx, req.UserID)
+
+	session, history, err := u.openSession(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var streamed strings.Builder
+	var streamErr error
+	relay := func(delta string) error {
+		if err := onDelta(delta); err != nil {
+			streamErr = err
+			return err
 		}
-	} else {
-		// Create new session
-		session = &domain.ChatSession{
+		streamed.WriteString(delta)
+		return nil
+	}
+
+	reply, err := u.generateReply(ctx, req, history, relay)
+	if errors.Is(err, domain.ErrAIQuotaExceeded) {
+		return nil, err
+	}
+	partial := false
+	if streamErr != nil || ctx.Err() != nil {
+		// The client is gone; keep what it was shown
+		if streamErr == nil {
+			streamErr = ctx.Err()
+		}
+		if streamed.Len() > 0 {
+			if _, err := u.saveExchange(context.WithoutCancel(ctx), session, req, &chatReply{text: streamed.String()}, true); err != nil {
+				log.Printf("failed to save partial chat reply: %v", err)
+			}
+		}
+		return nil, streamErr
+	}
+	if err != nil {
+		log.Printf("AI generation error: %v", err)
+		if streamed.Len() > 0 {
+			// The model failed part way through; keep the partial reply
+			reply, partial = &chatReply{text: streamed.String()}, true
+		} else {
+			reply = &chatReply{text: aiUnavailableReply}
+			if err := relay(reply.text); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	aiMessage, err := u.saveExchange(ctx, session, req, reply, partial)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ChatResponse{
+		SessionID:   session.ID,
+		MessageID:   aiMessage.ID,
+		Message:     reply.text,
+		Suggestions: reply.suggestions,
+		Jobs:        reply.jobs,
+		Session:     session,
+		Partial:     partial,
+	}, nil
+}
+
+const aiUnavailableReply = "I'm currently having trouble reaching the AI service. You can still ask me to upload or parse your CV, or try again in a moment."
+
+// openSession loads the request's session and the history to send to the AI, or prepares a
+// new session that is only stored once the exchange is saved.
+func (u *chatUsecase) openSession(ctx context.Context, req *domain.ChatRequest) (*domain.ChatSession, []domain.ChatMessage, error) {
+	if req.SessionID == "" {
+		return &domain.ChatSession{
 			UserID:    req.UserID,
 			Title:     truncateString(req.Message, 50), // Use first message as temporary title
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
+		}, nil, nil
+	}
+
+	session, err := u.chatRepo.GetSession(ctx, req.SessionID, req.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	history, err := u.conversationContext(ctx, session)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, history, nil
+}
+
+// conversationContext returns the session summary followed by as many recent messages as fit
+// the token budget. Messages that no longer fit are folded into the summary, which is stored
+// with the session when the exchange is saved; if that fails they are left out this time and
+// summarized on a later message.
+func (u *chatUsecase) conversationContext(ctx context.Context, session *domain.ChatSession) ([]domain.ChatMessage, error) {
+	// A backlog left by failed summaries is summarized a page at a time, oldest first
+	for backlog := session.MessageCount - session.SummarizedCount; backlog > maxContextMessages; backlog = session.MessageCount - session.SummarizedCount {
+		size := min(maxContextMessages, backlog-maxContextMessages)
+		page, err := u.chatRepo.GetSessionMessages(ctx, session.ID, session.UserID, size, backlog-size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get message history: %v", err)
+		}
+		if len(page) == 0 || !u.summarize(ctx, session, page) {
+			break
 		}
-		err = u.chatRepo.CreateSession(ctx, session)
+	}
+
+	unsummarized := min(session.MessageCount-session.SummarizedCount, maxContextMessages)
+	var recent []domain.ChatMessage
+	if unsummarized > 0 {
+		var err error
+		recent, err = u.chatRepo.GetSessionMessages(ctx, session.ID, session.UserID, unsummarized, 0)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create session: %v", err)
+			return nil, fmt.Errorf("failed to get message history: %v", err)
 		}
 	}
 
-	// Get recent message history (last 10 messages)
-	history, err := u.chatRepo.GetSessionMessages(ctx, session.ID, req.UserID, 10)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get message history: %v", err)
+	window, older := splitHistoryWindow(recent, u.historyTokens)
+	// Older messages only follow the summarized ones when no backlog is left before them
+	if len(older) > 0 && session.SummarizedCount+len(recent) == session.MessageCount {
+		u.summarize(ctx, session, older)
 	}
 
-	// Save user message
-	userMessage := &domain.ChatMessage{
-		SessionID: session.ID,
-		Role:      "user",
-		Content:   req.Message,
-		CVData:    req.CVData,
+	if session.Summary == "" {
+		return window, nil
 	}
-	err = u.chatRepo.SaveMessage(ctx, userMessage)
+	history := make([]domain.ChatMessage, 0, len(window)+1)
+	history = append(history, domain.ChatMessage{Role: domain.ChatSummaryRole, Content: session.Summary})
+	return append(history, window...), nil
+}
+
+// summarize folds the messages that follow the summarized ones into the session summary and
+// reports whether it succeeded.
+func (u *chatUsecase) summarize(ctx context.Context, session *domain.ChatSession, messages []domain.ChatMessage) bool {
+	summary, err := u.aiService.SummarizeConversation(ctx, session.Summary, messages)
 	if err != nil {
-		return nil, fmt.Errorf("failed to save user message: %v", err)
+		log.Printf("failed to summarize chat session %s: %v", session.ID, err)
+		return false
+	}
+	session.Summary = summary
+	session.SummarizedCount += len(messages)
+	return true
+}
+
+// splitHistoryWindow keeps the newest messages whose estimated tokens fit the budget, always
+// including the latest one, and returns the older messages separately.
+func splitHistoryWindow(messages []domain.ChatMessage, budget int) (window, older []domain.ChatMessage) {
+	start := len(messages)
+	used := 0
+	for start > 0 {
+		tokens := estimateTokens(messages[start-1].Content)
+		if used+tokens > budget && start < len(messages) {
+			break
+		}
+		used += tokens
+		start--
 	}
+	return messages[start:], messages[:start]
+}
 
-	// Generate AI response
-	var aiResponse string
-	var suggestions []domain.Suggestion
+// estimateTokens approximates a text's token count at four characters per token.
+func estimateTokens(s string) int {
+	return len(s)/4 + 1
+}
+
+// generateReply picks how to answer the message. Anything that is not about the CV sent with
+// it is answered by the assistant, which can look up jobs and the user's CV with tools. With
+// onDelta set the assistant's answer is streamed and CV replies are sent in one piece.
+func (u *chatUsecase) generateReply(ctx context.Context, req *domain.ChatRequest, history []domain.ChatMessage, onDelta func(string) error) (*chatReply, error) {
+	reply := &chatReply{}
+	var err error
 
 	if req.CVData != nil {
 		// CV improvement mode
-		aiResponse, suggestions, err = u.aiService.ImproveCV(ctx, req.CVData, req.Message, history)
+		reply.text, reply.suggestions, err = u.aiService.ImproveCV(ctx, req.CVData, req.Message, history)
 	} else if strings.Contains(strings.ToLower(req.Message), "analyze my cv") {
 		// Special handling for CV analysis
-		aiResponse, err = u.aiService.AnalyzeCV(ctx, extractCVText(req.Message))
-	} else if strings.Contains(strings.ToLower(req.Message), "find job") ||
-		strings.Contains(strings.ToLower(req.Message), "job search") {
-		// Special handling for job search
-		aiResponse, err = u.aiService.FindJobs(ctx, "", req.Message)
+		reply.text, reply.suggestions, err = u.aiService.AnalyzeCV(ctx, extractCVText(req.Message))
 	} else {
-		// General conversation
-		aiResponse, err = u.aiService.GenerateResponse(ctx, req.Message, history)
+		tools := newChatToolset(u, req.UserID)
+		// Citations are resolved before they are streamed, as they are in the saved reply
+		var stream *citationStream
+		agentDelta := onDelta
+		if onDelta != nil {
+			stream = tools.streamCitations(onDelta)
+			agentDelta = stream.write
+		}
+		agentReply, err := u.aiService.RunChatAgent(ctx, req.Message, history, tools.tools(), agentDelta)
+		if err != nil {
+			return nil, err
+		}
+		if stream != nil {
+			if err := stream.flush(); err != nil {
+				return nil, err
+			}
+		}
+		reply.text, reply.jobs = tools.resolveCitations(agentReply.Reply)
+		reply.toolCalls = agentReply.ToolCalls
+		return reply, nil
 	}
 
 	if err != nil {
-		// Do NOT fail the whole request. Surface a friendly message and continue.
-		log.Printf("AI generation error: %v", err)
-		aiResponse = "I'm currently having trouble reaching the AI service. You can still ask me to upload or parse your CV, or try again in a moment."
-		suggestions = nil
+		return nil, err
+	}
+	if onDelta != nil {
+		if err := onDelta(reply.text); err != nil {
+			return nil, err
+		}
+	}
+	return reply, nil
+}
+
+// saveExchange stores the session if it is new, the user's message and the reply, and
+// returns the saved reply.
+func (u *chatUsecase) saveExchange(ctx context.Context, session *domain.ChatSession, req *domain.ChatRequest, reply *chatReply, partial bool) (*domain.ChatMessage, error) {
+	if session.ID == "" {
+		if err := u.chatRepo.CreateSession(ctx, session); err != nil {
+			return nil, fmt.Errorf("failed to create session: %v", err)
+		}
+	}
+
+	// Save user message
+	userMessage := &domain.ChatMessage{
+		SessionID: session.ID,
+		Role:      "user",
+		Content:   req.Message,
+		CVData:    req.CVData,
+	}
+	if err := u.chatRepo.SaveMessage(ctx, userMessage); err != nil {
+		return nil, fmt.Errorf("failed to save user message: %v", err)
 	}
 
 	// Save AI response
 	aiMessage := &domain.ChatMessage{
 		SessionID:   session.ID,
 		Role:        "assistant",
-		Content:     aiResponse,
+		Content:     reply.text,
 		CVData:      req.CVData,
-		Suggestions: suggestions,
+		Suggestions: reply.suggestions,
+		ToolCalls:   reply.toolCalls,
+		Partial:     partial,
 	}
-	err = u.chatRepo.SaveMessage(ctx, aiMessage)
-	if err != nil {
+	for _, job := range reply.jobs {
+		aiMessage.JobIDs = append(aiMessage.JobIDs, job.ID)
+	}
+	if err := u.chatRepo.SaveMessage(ctx, aiMessage); err != nil {
 		return nil, fmt.Errorf("failed to save AI message: %v", err)
 	}
 
-	// Update session
-	session.MessageCount += 2 // User + AI messages
-	session.UpdatedAt = time.Now()
-
+	exchange := domain.ChatExchange{
+		Messages:        2, // User + AI messages
+		Summary:         session.Summary,
+		SummarizedCount: session.SummarizedCount,
+	}
 	// If this is a new session and we have an AI response, generate a better title
-	if session.Title == truncateString(req.Message, 50) {
-		titlePrompt := fmt.Sprintf("Generate a short title (max 5 words) for a conversation that started with: %s", req.Message)
-		title, err := u.aiService.GenerateResponse(ctx, titlePrompt, nil)
+	if !partial && session.Title == truncateString(req.Message, 50) {
+		title, err := u.aiService.GenerateTitle(ctx, req.Message)
 		if err == nil {
-			session.Title = truncateString(title, 30)
+			exchange.Title = truncateString(title, 30)
 		}
 	}
 
-	err = u.chatRepo.UpdateSession(ctx, session)
+	updated, err := u.chatRepo.RecordExchange(ctx, session.ID, session.UserID, exchange)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update session: %v", err)
 	}
-
-	// Get updated message history
-	updatedHistory, err := u.chatRepo.GetSessionMessages(ctx, session.ID, req.UserID, 0)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get updated message history: %v", err)
-	}
-
-	return &domain.ChatResponse{
-		SessionID:   session.ID,
-		Message:     aiResponse,
-		History:     updatedHistory,
-		Suggestions: suggestions,
-	}, nil
+	*session = *updated
+	return aiMessage, nil
 }
 
-func (u *chatUsecase) GetSessionHistory(ctx context.Context, sessionID, userID string) ([]domain.ChatMessage, error) {
+func (u *chatUsecase) GetSessionHistory(ctx context.Context, sessionID, userID string, limit, offset int) (*domain.ChatHistoryPage, error) {
 	// Verify the session belongs to the user
-	_, err := u.chatRepo.GetSession(ctx, sessionID, userID)
+	session, err := u.chatRepo.GetSession(ctx, sessionID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("session not found: %v", err)
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
 	}
 
-	return u.chatRepo.GetSessionMessages(ctx, sessionID, userID, 0)
-}
-
-func (u *chatUsecase) GetUserSessions(ctx context.Context, userID string, limit, offset int) ([]domain.ChatSession, error) {
-	return u.chatRepo.GetUserSessions(ctx, userID, limit, offset)
-}
-
-func (u *chatUsecase) DeleteSession(ctx context.Context, sessionID, userID string) error {
-	return u.chatRepo.DeleteSession(ctx, sessionID, userID)
+	messages, err := u.chatRepo.GetSessionMessages(ctx, sessionID, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message history: %v", err)
+	}
+	if messages == nil {
+		messages = []domain.ChatMessage{}
+	}
+	return &domain.ChatHistoryPage{
+		Messages: messages,
+		Total:    session.MessageCount,
+		Limit:    limit,
+		Offset:   offset,
+		HasMore:  offset+len(messages) < session.MessageCount,
+	}, nil
 }
 
 // Helper function to truncate strings
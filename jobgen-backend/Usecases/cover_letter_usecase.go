@@ -0,0 +1,207 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	domain "jobgen-backend/Domain"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type coverLetterUsecase struct {
+	coverLetterRepo domain.ICoverLetterRepository
+	jobRepo         domain.IJobRepository
+	cvRepo          domain.CVRepository
+	aiService       domain.IAIService
+	exporter        domain.IDocumentExporter
+	contextTimeout  time.Duration
+}
+
+func NewCoverLetterUsecase(
+	coverLetterRepo domain.ICoverLetterRepository,
+	jobRepo domain.IJobRepository,
+	cvRepo domain.CVRepository,
+	aiService domain.IAIService,
+	exporter domain.IDocumentExporter,
+	timeout time.Duration,
+) domain.ICoverLetterUsecase {
+	return &coverLetterUsecase{
+		coverLetterRepo: coverLetterRepo,
+		jobRepo:         jobRepo,
+		cvRepo:          cvRepo,
+		aiService:       aiService,
+		exporter:        exporter,
+		contextTimeout:  timeout,
+	}
+}
+
+// Generate creates a new AI draft. When cvID or an option is omitted, the values from the
+// latest draft are reused so that re-generating only needs the job ID.
+func (u *coverLetterUsecase) Generate(ctx context.Context, userID, jobID, cvID string, opts domain.CoverLetterOptions) (*domain.CoverLetterDraft, error) {
+	// AI generation can be slow; give it more room than the default timeout
+	ctx, cancel := context.WithTimeout(ctx, 2*u.contextTimeout)
+	defer cancel()
+
+	if userID == "" || jobID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	latest, err := u.coverLetterRepo.GetLatest(ctx, userID, jobID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get previous draft: %w", err)
+	}
+	if latest != nil {
+		if cvID == "" {
+			cvID = latest.CVID
+		}
+		if opts.Tone == "" {
+			opts.Tone = latest.Tone
+		}
+		if opts.Length == "" {
+			opts.Length = latest.Length
+		}
+	}
+	if cvID == "" {
+		return nil, fmt.Errorf("%w: cv_id is required", domain.ErrInvalidInput)
+	}
+	if opts.Tone == "" {
+		opts.Tone = domain.ToneProfessional
+	}
+	if opts.Length == "" {
+		opts.Length = domain.LengthMedium
+	}
+
+	cv, err := getOwnedCompletedCV(u.cvRepo, userID, cvID)
+	if err != nil {
+		return nil, err
+	}
+	job, err := u.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	content, err := u.aiService.GenerateCoverLetter(domain.WithAIUser(ctx, userID), cv, job, opts)
+	if errors.Is(err, domain.ErrAIQuotaExceeded) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrServiceUnavailable, err)
+	}
+
+	draft := &domain.CoverLetterDraft{
+		UserID:  userID,
+		JobID:   jobID,
+		CVID:    cvID,
+		Tone:    opts.Tone,
+		Length:  opts.Length,
+		Content: strings.TrimSpace(content),
+		Source:  domain.DraftGenerated,
+	}
+	if err := u.saveNextDraft(ctx, draft, latest); err != nil {
+		return nil, fmt.Errorf("failed to save draft: %w", err)
+	}
+	return draft, nil
+}
+
+// Edit stores the user's own text as a new version on top of the latest draft.
+func (u *coverLetterUsecase) Edit(ctx context.Context, userID, jobID, content string) (*domain.CoverLetterDraft, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, fmt.Errorf("%w: content is required", domain.ErrInvalidInput)
+	}
+
+	latest, err := u.coverLetterRepo.GetLatest(ctx, userID, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest draft: %w", err)
+	}
+
+	draft := &domain.CoverLetterDraft{
+		UserID:  userID,
+		JobID:   jobID,
+		CVID:    latest.CVID,
+		Tone:    latest.Tone,
+		Length:  latest.Length,
+		Content: content,
+		Source:  domain.DraftEdited,
+	}
+	if err := u.saveNextDraft(ctx, draft, latest); err != nil {
+		return nil, fmt.Errorf("failed to save draft: %w", err)
+	}
+	return draft, nil
+}
+
+func (u *coverLetterUsecase) ListDrafts(ctx context.Context, userID, jobID string) ([]domain.CoverLetterDraft, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	return u.coverLetterRepo.ListVersions(ctx, userID, jobID)
+}
+
+// Export renders a draft; version 0 means the latest one.
+func (u *coverLetterUsecase) Export(ctx context.Context, userID, jobID string, version int, format domain.ExportFormat) (*domain.ExportedDocument, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	var draft *domain.CoverLetterDraft
+	var err error
+	if version > 0 {
+		draft, err = u.coverLetterRepo.GetVersion(ctx, userID, jobID, version)
+	} else {
+		draft, err = u.coverLetterRepo.GetLatest(ctx, userID, jobID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+
+	title := "Cover Letter"
+	baseName := "cover-letter"
+	if job, err := u.jobRepo.GetByID(ctx, jobID); err == nil {
+		title = fmt.Sprintf("Cover Letter - %s at %s", job.Title, job.CompanyName)
+		baseName = "cover-letter-" + slugify(job.CompanyName+" "+job.Title)
+	}
+	baseName = fmt.Sprintf("%s-v%d", baseName, draft.Version)
+
+	doc, err := u.exporter.Export(baseName, title, draft.Content, format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	return doc, nil
+}
+
+// saveNextDraft stores the draft as the version after latest. When a concurrent request has
+// taken that number first, it moves the draft past the new latest version and tries again.
+func (u *coverLetterUsecase) saveNextDraft(ctx context.Context, draft, latest *domain.CoverLetterDraft) error {
+	for attempt := 1; ; attempt++ {
+		draft.Version = nextDraftVersion(latest)
+		err := u.coverLetterRepo.Create(ctx, draft)
+		if !errors.Is(err, domain.ErrDraftVersionConflict) || attempt == maxVersionSaveAttempts {
+			return err
+		}
+		if latest, err = u.coverLetterRepo.GetLatest(ctx, draft.UserID, draft.JobID); err != nil {
+			return fmt.Errorf("failed to get latest draft: %w", err)
+		}
+	}
+}
+
+func nextDraftVersion(latest *domain.CoverLetterDraft) int {
+	if latest == nil {
+		return 1
+	}
+	return latest.Version + 1
+}
+
+var slugInvalidRE = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify turns free text into a short, filename-safe identifier.
+func slugify(s string) string {
+	s = strings.Trim(slugInvalidRE.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(s) > 60 {
+		s = strings.TrimRight(s[:60], "-")
+	}
+	return s
+}
@@ -0,0 +1,139 @@
+package usecases
+
+import (
+	domain "jobgen-backend/Domain"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DiffCVs returns the field-level changes needed to turn before into after.
+// Experiences and educations are matched by ID; added or removed entries are reported
+// as a single change on the entry path with an empty Before or After.
+func DiffCVs(before, after *domain.CV) []domain.CVFieldChange {
+	var changes []domain.CVFieldChange
+	add := func(field, b, a string) {
+		if b != a {
+			changes = append(changes, domain.CVFieldChange{Field: field, Before: b, After: a})
+		}
+	}
+
+	add("profileSummary", before.ProfileSummary, after.ProfileSummary)
+	add("skills", strings.Join(before.Skills, ", "), strings.Join(after.Skills, ", "))
+
+	add("contact.name", before.Contact.Name, after.Contact.Name)
+	add("contact.email", before.Contact.Email, after.Contact.Email)
+	add("contact.phone", before.Contact.Phone, after.Contact.Phone)
+	add("contact.location", before.Contact.Location, after.Contact.Location)
+	add("contact.linkedin", before.Contact.LinkedIn, after.Contact.LinkedIn)
+	add("contact.github", before.Contact.GitHub, after.Contact.GitHub)
+	add("contact.portfolio", before.Contact.Portfolio, after.Contact.Portfolio)
+	add("languages", summarizeLanguages(before.Languages), summarizeLanguages(after.Languages))
+	add("certifications", summarizeNames(before.Certifications, func(c domain.Certification) string { return c.Name }),
+		summarizeNames(after.Certifications, func(c domain.Certification) string { return c.Name }))
+	add("projects", summarizeNames(before.Projects, func(p domain.Project) string { return p.Name }),
+		summarizeNames(after.Projects, func(p domain.Project) string { return p.Name }))
+
+	beforeExp := make(map[string]domain.Experience)
+	for _, e := range before.Experiences {
+		beforeExp[e.ID] = e
+	}
+	afterExp := make(map[string]domain.Experience)
+	for _, e := range after.Experiences {
+		afterExp[e.ID] = e
+	}
+	for _, id := range unionKeys(beforeExp, afterExp) {
+		path := "experiences[" + id + "]"
+		b, inBefore := beforeExp[id]
+		a, inAfter := afterExp[id]
+		switch {
+		case !inBefore:
+			add(path, "", summarizeExperience(a))
+		case !inAfter:
+			add(path, summarizeExperience(b), "")
+		default:
+			add(path+".title", b.Title, a.Title)
+			add(path+".company", b.Company, a.Company)
+			add(path+".location", b.Location, a.Location)
+			add(path+".startDate", formatCVDate(&b.StartDate), formatCVDate(&a.StartDate))
+			add(path+".endDate", formatCVDate(b.EndDate), formatCVDate(a.EndDate))
+			add(path+".description", b.Description, a.Description)
+		}
+	}
+
+	beforeEdu := make(map[string]domain.Education)
+	for _, e := range before.Educations {
+		beforeEdu[e.ID] = e
+	}
+	afterEdu := make(map[string]domain.Education)
+	for _, e := range after.Educations {
+		afterEdu[e.ID] = e
+	}
+	for _, id := range unionKeys(beforeEdu, afterEdu) {
+		path := "educations[" + id + "]"
+		b, inBefore := beforeEdu[id]
+		a, inAfter := afterEdu[id]
+		switch {
+		case !inBefore:
+			add(path, "", summarizeEducation(a))
+		case !inAfter:
+			add(path, summarizeEducation(b), "")
+		default:
+			add(path+".degree", b.Degree, a.Degree)
+			add(path+".institution", b.Institution, a.Institution)
+			add(path+".location", b.Location, a.Location)
+			add(path+".graduationDate", formatCVDate(&b.GraduationDate), formatCVDate(&a.GraduationDate))
+		}
+	}
+
+	return changes
+}
+
+func unionKeys[T any](a, b map[string]T) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func summarizeLanguages(langs []domain.Language) string {
+	parts := make([]string, 0, len(langs))
+	for _, l := range langs {
+		if l.Proficiency != "" {
+			parts = append(parts, l.Name+" ("+l.Proficiency+")")
+		} else {
+			parts = append(parts, l.Name)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func summarizeNames[T any](items []T, name func(T) string) string {
+	parts := make([]string, 0, len(items))
+	for _, it := range items {
+		parts = append(parts, name(it))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func summarizeExperience(e domain.Experience) string {
+	return strings.TrimSpace(e.Title + " at " + e.Company)
+}
+
+func summarizeEducation(e domain.Education) string {
+	return strings.TrimSpace(e.Degree + ", " + e.Institution)
+}
+
+func formatCVDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01")
+}
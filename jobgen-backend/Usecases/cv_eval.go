@@ -0,0 +1,331 @@
+package usecases
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+)
+
+// goldenSuffix marks the annotation file of a corpus sample: "alice.pdf" is annotated by
+// "alice.golden.json".
+const goldenSuffix = ".golden.json"
+
+// Fields scored by EvaluateCVCorpus, in report order.
+var evalFields = []string{
+	"skills",
+	"experience.title",
+	"experience.company",
+	"experience.dates",
+	"education.degree",
+	"education.institution",
+	"education.graduation",
+}
+
+// CVGolden is the hand-written annotation of a corpus sample.
+type CVGolden struct {
+	Skills      []string `json:"skills"`
+	Experiences []struct {
+		Title     string `json:"title"`
+		Company   string `json:"company"`
+		StartDate string `json:"startDate"` // YYYY-MM
+		EndDate   string `json:"endDate"`   // YYYY-MM, empty while current
+	} `json:"experiences"`
+	Educations []struct {
+		Degree         string `json:"degree"`
+		Institution    string `json:"institution"`
+		GraduationDate string `json:"graduationDate"` // YYYY
+	} `json:"educations"`
+}
+
+// FieldScore counts matched, spurious and missed values for one field.
+type FieldScore struct {
+	TruePositives  int      `json:"truePositives"`
+	FalsePositives int      `json:"falsePositives"`
+	FalseNegatives int      `json:"falseNegatives"`
+	Missed         []string `json:"missed,omitempty"`
+	Unexpected     []string `json:"unexpected,omitempty"`
+}
+
+func (s FieldScore) Precision() float64 {
+	return ratio(s.TruePositives, s.TruePositives+s.FalsePositives)
+}
+
+func (s FieldScore) Recall() float64 {
+	return ratio(s.TruePositives, s.TruePositives+s.FalseNegatives)
+}
+
+func (s FieldScore) F1() float64 {
+	p, r := s.Precision(), s.Recall()
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+func (s *FieldScore) add(o FieldScore) {
+	s.TruePositives += o.TruePositives
+	s.FalsePositives += o.FalsePositives
+	s.FalseNegatives += o.FalseNegatives
+}
+
+// ratio treats an empty denominator as a perfect score: nothing expected, nothing missed.
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 1
+	}
+	return float64(n) / float64(d)
+}
+
+// CVEvalDocument is the result for a single corpus sample.
+type CVEvalDocument struct {
+	File        string                `json:"file"`
+	Format      string                `json:"format,omitempty"`
+	Error       string                `json:"error,omitempty"`
+	ExtractTime time.Duration         `json:"extractTime"`
+	ParseTime   time.Duration         `json:"parseTime"`
+	Fields      map[string]FieldScore `json:"fields,omitempty"`
+}
+
+// CVEvalReport aggregates the scores over the whole corpus.
+type CVEvalReport struct {
+	Documents []CVEvalDocument      `json:"documents"`
+	Fields    map[string]FieldScore `json:"fields"`
+	Failed    int                   `json:"failed"`
+	TotalTime time.Duration         `json:"totalTime"`
+	MaxTime   time.Duration         `json:"maxTime"`
+}
+
+// FieldNames returns the scored fields in report order.
+func (r *CVEvalReport) FieldNames() []string {
+	return evalFields
+}
+
+// MeanTime is the average extract+parse time per document.
+func (r *CVEvalReport) MeanTime() time.Duration {
+	if len(r.Documents) == 0 {
+		return 0
+	}
+	return r.TotalTime / time.Duration(len(r.Documents))
+}
+
+// EvaluateCVCorpus runs every sample in dir that has a golden annotation through the same
+// extract and parse steps as the CV worker and scores the result field by field.
+func EvaluateCVCorpus(dir string, parser infrastructure.CVParserService) (*CVEvalReport, error) {
+	samples, err := findCorpusSamples(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no annotated samples found in %s", dir)
+	}
+
+	report := &CVEvalReport{Fields: make(map[string]FieldScore, len(evalFields))}
+	for _, sample := range samples {
+		golden, err := loadGolden(goldenPath(sample))
+		if err != nil {
+			return nil, err
+		}
+		doc := evaluateSample(sample, golden, parser)
+		doc.File = filepath.Base(sample)
+		report.Documents = append(report.Documents, doc)
+
+		elapsed := doc.ExtractTime + doc.ParseTime
+		report.TotalTime += elapsed
+		if elapsed > report.MaxTime {
+			report.MaxTime = elapsed
+		}
+		if doc.Error != "" {
+			report.Failed++
+		}
+		for _, field := range evalFields {
+			total := report.Fields[field]
+			total.add(doc.Fields[field])
+			report.Fields[field] = total
+		}
+	}
+	return report, nil
+}
+
+func evaluateSample(path string, golden *CVGolden, parser infrastructure.CVParserService) CVEvalDocument {
+	var result CVEvalDocument
+	parsed := &domain.CV{}
+
+	f, err := os.Open(path)
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		start := time.Now()
+		doc, extractErr := parser.ExtractDocument(f)
+		result.ExtractTime = time.Since(start)
+		f.Close()
+
+		if extractErr != nil {
+			result.Error = extractErr.Error()
+		} else {
+			result.Format = doc.Format
+			start = time.Now()
+			cv, parseErr := ParseDocumentToCVSections(doc)
+			result.ParseTime = time.Since(start)
+			if parseErr != nil {
+				result.Error = parseErr.Error()
+			} else {
+				parsed = cv
+			}
+		}
+	}
+
+	// A failed document still counts: every golden value becomes a miss
+	result.Fields = scoreCV(parsed, golden)
+	return result
+}
+
+func scoreCV(cv *domain.CV, golden *CVGolden) map[string]FieldScore {
+	var (
+		wantTitles, wantCompanies, wantDates []string
+		gotTitles, gotCompanies, gotDates    []string
+	)
+	for _, e := range golden.Experiences {
+		wantTitles = append(wantTitles, e.Title)
+		wantCompanies = append(wantCompanies, e.Company)
+		wantDates = append(wantDates, dateRangeKey(e.StartDate, e.EndDate))
+	}
+	for _, e := range cv.Experiences {
+		gotTitles = append(gotTitles, e.Title)
+		gotCompanies = append(gotCompanies, e.Company)
+		gotDates = append(gotDates, dateRangeKey(monthKey(&e.StartDate), monthKey(e.EndDate)))
+	}
+
+	var (
+		wantDegrees, wantInstitutions, wantGraduations []string
+		gotDegrees, gotInstitutions, gotGraduations    []string
+	)
+	for _, e := range golden.Educations {
+		wantDegrees = append(wantDegrees, e.Degree)
+		wantInstitutions = append(wantInstitutions, e.Institution)
+		wantGraduations = append(wantGraduations, e.GraduationDate)
+	}
+	for _, e := range cv.Educations {
+		gotDegrees = append(gotDegrees, e.Degree)
+		gotInstitutions = append(gotInstitutions, e.Institution)
+		if !e.GraduationDate.IsZero() {
+			gotGraduations = append(gotGraduations, e.GraduationDate.Format("2006"))
+		}
+	}
+
+	return map[string]FieldScore{
+		"skills":                matchValues(golden.Skills, cv.Skills),
+		"experience.title":      matchValues(wantTitles, gotTitles),
+		"experience.company":    matchValues(wantCompanies, gotCompanies),
+		"experience.dates":      matchValues(wantDates, gotDates),
+		"education.degree":      matchValues(wantDegrees, gotDegrees),
+		"education.institution": matchValues(wantInstitutions, gotInstitutions),
+		"education.graduation":  matchValues(wantGraduations, gotGraduations),
+	}
+}
+
+// matchValues pairs expected and parsed values as multisets after normalization. Empty
+// values are ignored on both sides.
+func matchValues(want, got []string) FieldScore {
+	var score FieldScore
+	remaining := make(map[string]int)
+	for _, g := range got {
+		if k := evalKey(g); k != "" {
+			remaining[k]++
+		}
+	}
+	for _, w := range want {
+		k := evalKey(w)
+		if k == "" {
+			continue
+		}
+		if remaining[k] > 0 {
+			remaining[k]--
+			score.TruePositives++
+			continue
+		}
+		score.FalseNegatives++
+		score.Missed = append(score.Missed, w)
+	}
+	for _, g := range got {
+		k := evalKey(g)
+		if remaining[k] > 0 {
+			remaining[k]--
+			score.FalsePositives++
+			score.Unexpected = append(score.Unexpected, g)
+		}
+	}
+	return score
+}
+
+// evalKey lowercases and drops punctuation other than the characters that carry meaning in
+// skill names such as C++, C# or Node.js.
+func evalKey(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' || r == '.' {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(strings.Trim(s, ". ")), " ")
+}
+
+func dateRangeKey(start, end string) string {
+	if start == "" && end == "" {
+		return ""
+	}
+	if end == "" {
+		end = "present"
+	}
+	return start + ".." + end
+}
+
+func monthKey(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01")
+}
+
+// findCorpusSamples lists the files in dir that have a golden annotation next to them.
+func findCorpusSamples(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read corpus: %w", err)
+	}
+	var samples []string
+	for _, e := range entries {
+		if e.IsDir() || strings.HasSuffix(e.Name(), goldenSuffix) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if _, err := os.Stat(goldenPath(path)); err == nil {
+			samples = append(samples, path)
+		}
+	}
+	sort.Strings(samples)
+	return samples, nil
+}
+
+func goldenPath(sample string) string {
+	return strings.TrimSuffix(sample, filepath.Ext(sample)) + goldenSuffix
+}
+
+func loadGolden(path string) (*CVGolden, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden file: %w", err)
+	}
+	var golden CVGolden
+	if err := json.Unmarshal(data, &golden); err != nil {
+		return nil, fmt.Errorf("invalid golden file %s: %w", filepath.Base(path), err)
+	}
+	return &golden, nil
+}
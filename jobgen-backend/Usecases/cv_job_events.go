@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+	"log"
+	"sync"
+	"time"
+)
+
+// WebhookEventJobFinished is the only webhook event so far.
+const WebhookEventJobFinished = "cv.job.finished"
+
+// webhookRetryDelays are the pauses between delivery attempts of one callback.
+var webhookRetryDelays = []time.Duration{2 * time.Second, 10 * time.Second, 30 * time.Second}
+
+// CVJobNotifier publishes a CV job's progress on the event bus and, when the job finishes,
+// calls the owner's webhooks in the background.
+type CVJobNotifier struct {
+	events      infrastructure.CVEventBus
+	cvRepo      domain.CVRepository
+	webhookRepo domain.ICVWebhookRepository
+	sender      infrastructure.WebhookSender
+
+	deliveries sync.WaitGroup
+}
+
+func NewCVJobNotifier(events infrastructure.CVEventBus, cvRepo domain.CVRepository, webhookRepo domain.ICVWebhookRepository, sender infrastructure.WebhookSender) *CVJobNotifier {
+	return &CVJobNotifier{events: events, cvRepo: cvRepo, webhookRepo: webhookRepo, sender: sender}
+}
+
+func (n *CVJobNotifier) StatusChanged(jobID string, status domain.JobStatus, message string) {
+	event := domain.CVJobEvent{JobID: jobID, Type: domain.CVEventStatus, Status: status, Message: message, At: time.Now().UTC()}
+	n.publish(event)
+	if event.Terminal() {
+		n.deliveries.Add(1)
+		go func() {
+			defer n.deliveries.Done()
+			n.deliverWebhooks(event)
+		}()
+	}
+}
+
+func (n *CVJobNotifier) StageCompleted(jobID string, stage domain.CVJobStage, message string) {
+	n.publish(domain.CVJobEvent{JobID: jobID, Type: domain.CVEventStage, Stage: stage, Message: message, At: time.Now().UTC()})
+}
+
+// Wait blocks until pending webhook deliveries are done or ctx expires.
+func (n *CVJobNotifier) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		n.deliveries.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (n *CVJobNotifier) publish(event domain.CVJobEvent) {
+	if err := n.events.Publish(event); err != nil {
+		log.Printf("🟠 Error publishing %s event for job %s: %v", event.Type, event.JobID, err)
+	}
+}
+
+func (n *CVJobNotifier) deliverWebhooks(event domain.CVJobEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	cv, err := n.cvRepo.GetByID(event.JobID)
+	if err != nil {
+		log.Printf("🔴 Error loading CV %s for webhooks: %v", event.JobID, err)
+		return
+	}
+	hooks, err := n.webhookRepo.ListByUser(ctx, cv.UserID)
+	if err != nil {
+		log.Printf("🔴 Error listing webhooks of user %s: %v", cv.UserID, err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(domain.CVWebhookPayload{
+		Event:           WebhookEventJobFinished,
+		JobID:           cv.ID,
+		Status:          event.Status,
+		Score:           cv.Score,
+		ProcessingError: cv.ProcessingError,
+		OccurredAt:      event.At,
+	})
+	if err != nil {
+		log.Printf("🔴 Error encoding webhook payload for job %s: %v", cv.ID, err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, hook := range hooks {
+		wg.Add(1)
+		go func(hook domain.CVWebhook) {
+			defer wg.Done()
+			n.deliver(ctx, hook, payload)
+		}(hook)
+	}
+	wg.Wait()
+}
+
+// deliver sends one callback, retrying failed attempts, and records the outcome on the webhook.
+func (n *CVJobNotifier) deliver(ctx context.Context, hook domain.CVWebhook, payload []byte) {
+	err := n.sender.Send(ctx, hook.URL, hook.Secret, WebhookEventJobFinished, payload)
+	for _, delay := range webhookRetryDelays {
+		if err == nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+		err = n.sender.Send(ctx, hook.URL, hook.Secret, WebhookEventJobFinished, payload)
+	}
+
+	deliveryErr := ""
+	if err != nil {
+		deliveryErr = err.Error()
+		log.Printf("🟠 Webhook %s failed for user %s: %v", hook.ID, hook.UserID, err)
+	}
+	if recErr := n.webhookRepo.RecordDelivery(context.Background(), hook.ID, time.Now().UTC(), deliveryErr); recErr != nil {
+		log.Printf("🟠 Error recording delivery of webhook %s: %v", hook.ID, recErr)
+	}
+}
+
+// SubscribeJobEvents checks that the user owns the job and returns its current state along
+// with the events that follow it. The subscription ends with ctx.
+func (uc *cvUsecase) SubscribeJobEvents(ctx context.Context, userID, jobID string) (*domain.CV, <-chan domain.CVJobEvent, error) {
+	if _, err := getOwnedCV(uc.repo, userID, jobID); err != nil {
+		return nil, nil, err
+	}
+	events, err := uc.events.Subscribe(ctx, jobID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to subscribe to job events: %w", err)
+	}
+	// Read the state only after subscribing so no transition falls in between
+	cv, err := uc.repo.GetByID(jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cv, events, nil
+}
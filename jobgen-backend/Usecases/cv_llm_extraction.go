@@ -0,0 +1,278 @@
+package usecases
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	domain "jobgen-backend/Domain"
+)
+
+// llmSectionConfidence is the confidence given to a section taken from validated model
+// output; it clears the review threshold but stays below user-confirmed values.
+const llmSectionConfidence = 0.8
+
+// Limits applied while validating model output.
+const (
+	maxLLMSummaryLen     = 2000
+	maxLLMTextLen        = 200
+	maxLLMDescriptionLen = 4000
+	maxLLMEntries        = 30
+	maxLLMSkills         = 200
+)
+
+// coreSourceFields are the fields whose extraction path is recorded in CV.FieldSources.
+var coreSourceFields = []string{"profileSummary", "experiences", "educations", "skills"}
+
+// llmCVExtraction is the JSON object the model is asked to return. Each section stays raw
+// until it is validated so one malformed section does not discard the others.
+type llmCVExtraction struct {
+	ProfileSummary json.RawMessage `json:"profileSummary"`
+	Experiences    json.RawMessage `json:"experiences"`
+	Educations     json.RawMessage `json:"educations"`
+	Skills         json.RawMessage `json:"skills"`
+}
+
+type llmExperience struct {
+	Title       string `json:"title"`
+	Company     string `json:"company"`
+	Location    string `json:"location"`
+	StartDate   string `json:"startDate"`
+	EndDate     string `json:"endDate"`
+	Description string `json:"description"`
+}
+
+type llmEducation struct {
+	Degree         string `json:"degree"`
+	Institution    string `json:"institution"`
+	Location       string `json:"location"`
+	GraduationDate string `json:"graduationDate"`
+}
+
+// MarkHeuristicSources records that every core field of cv came from the heuristic parser.
+func MarkHeuristicSources(cv *domain.CV) {
+	cv.FieldSources = make(map[string]domain.CVFieldSource, len(coreSourceFields))
+	for _, field := range coreSourceFields {
+		cv.FieldSources[field] = domain.FieldSourceHeuristic
+	}
+}
+
+// ReconcileLLMExtraction merges the model's JSON response into a heuristically parsed CV.
+// Each section is validated on its own: a valid, non-empty section replaces the heuristic
+// value, anything malformed, ungrounded or empty keeps the heuristic result. The path taken
+// per field is recorded in cv.FieldSources. The returned errors describe rejected sections.
+func ReconcileLLMExtraction(cv *domain.CV, rawText, response string) []error {
+	MarkHeuristicSources(cv)
+
+	var extraction llmCVExtraction
+	dec := json.NewDecoder(strings.NewReader(response))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&extraction); err != nil {
+		return []error{fmt.Errorf("llm response is not a valid extraction object: %w", err)}
+	}
+
+	text := strings.Join(strings.Fields(strings.ToLower(rawText)), " ")
+	var problems []error
+	reject := func(field string, err error) {
+		problems = append(problems, fmt.Errorf("%s: %w", field, err))
+	}
+
+	if summary, err := validateLLMSummary(extraction.ProfileSummary); err != nil {
+		reject("profileSummary", err)
+	} else if summary != "" {
+		cv.ProfileSummary = summary
+		useLLMSource(cv, "profileSummary")
+	}
+
+	if experiences, err := validateLLMExperiences(extraction.Experiences, text); err != nil {
+		reject("experiences", err)
+	} else if len(experiences) > 0 {
+		cv.Experiences = experiences
+		useLLMSource(cv, "experiences")
+	}
+
+	if educations, err := validateLLMEducations(extraction.Educations, text); err != nil {
+		reject("educations", err)
+	} else if len(educations) > 0 {
+		cv.Educations = educations
+		useLLMSource(cv, "educations")
+	}
+
+	if skills, err := validateLLMSkills(extraction.Skills, text); err != nil {
+		reject("skills", err)
+	} else if len(skills) > 0 {
+		cv.Skills = skills
+		useLLMSource(cv, "skills")
+	}
+
+	return problems
+}
+
+func useLLMSource(cv *domain.CV, field string) {
+	cv.FieldSources[field] = domain.FieldSourceLLM
+	if cv.FieldConfidence == nil {
+		cv.FieldConfidence = make(map[string]float64)
+	}
+	if cv.FieldConfidence[field] < llmSectionConfidence {
+		cv.FieldConfidence[field] = llmSectionConfidence
+	}
+}
+
+// decodeLLMSection strictly decodes one section; a missing or null section decodes to the
+// zero value.
+func decodeLLMSection(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func validateLLMSummary(raw json.RawMessage) (string, error) {
+	var summary string
+	if err := decodeLLMSection(raw, &summary); err != nil {
+		return "", err
+	}
+	summary = strings.TrimSpace(summary)
+	if len(summary) > maxLLMSummaryLen {
+		return "", errors.New("summary is too long")
+	}
+	return summary, nil
+}
+
+func validateLLMExperiences(raw json.RawMessage, text string) ([]domain.Experience, error) {
+	var entries []llmExperience
+	if err := decodeLLMSection(raw, &entries); err != nil {
+		return nil, err
+	}
+	if len(entries) > maxLLMEntries {
+		return nil, fmt.Errorf("%d entries exceed the limit of %d", len(entries), maxLLMEntries)
+	}
+
+	out := make([]domain.Experience, 0, len(entries))
+	for i, e := range entries {
+		title, company := strings.TrimSpace(e.Title), strings.TrimSpace(e.Company)
+		if title == "" && company == "" {
+			return nil, fmt.Errorf("entry %d has neither title nor company", i)
+		}
+		if len(title) > maxLLMTextLen || len(company) > maxLLMTextLen || len(e.Description) > maxLLMDescriptionLen {
+			return nil, fmt.Errorf("entry %d exceeds the length limits", i)
+		}
+		if !groundedIn(text, title) || !groundedIn(text, company) {
+			return nil, fmt.Errorf("entry %d is not found in the CV text", i)
+		}
+		start, err := parseLLMDate(e.StartDate, "2006-01")
+		if err != nil {
+			return nil, fmt.Errorf("entry %d start date: %w", i, err)
+		}
+		end, err := parseLLMDate(e.EndDate, "2006-01")
+		if err != nil {
+			return nil, fmt.Errorf("entry %d end date: %w", i, err)
+		}
+		if start != nil && end != nil && end.Before(*start) {
+			return nil, fmt.Errorf("entry %d ends before it starts", i)
+		}
+
+		header := title + " - " + company
+		out = append(out, domain.Experience{
+			ID:          "exp-" + hashString(header),
+			Title:       title,
+			Company:     normalizeName(company),
+			Location:    strings.TrimSpace(e.Location),
+			StartDate:   derefOrZero(start),
+			EndDate:     end,
+			Description: strings.TrimSpace(e.Description),
+		})
+	}
+	return out, nil
+}
+
+func validateLLMEducations(raw json.RawMessage, text string) ([]domain.Education, error) {
+	var entries []llmEducation
+	if err := decodeLLMSection(raw, &entries); err != nil {
+		return nil, err
+	}
+	if len(entries) > maxLLMEntries {
+		return nil, fmt.Errorf("%d entries exceed the limit of %d", len(entries), maxLLMEntries)
+	}
+
+	out := make([]domain.Education, 0, len(entries))
+	for i, e := range entries {
+		institution, degree := strings.TrimSpace(e.Institution), strings.TrimSpace(e.Degree)
+		if institution == "" {
+			return nil, fmt.Errorf("entry %d has no institution", i)
+		}
+		if len(institution) > maxLLMTextLen || len(degree) > maxLLMTextLen {
+			return nil, fmt.Errorf("entry %d exceeds the length limits", i)
+		}
+		if !groundedIn(text, institution) {
+			return nil, fmt.Errorf("entry %d is not found in the CV text", i)
+		}
+		grad, err := parseLLMDate(e.GraduationDate, "2006-01", "2006")
+		if err != nil {
+			return nil, fmt.Errorf("entry %d graduation date: %w", i, err)
+		}
+
+		out = append(out, domain.Education{
+			ID:             "edu-" + hashString(degree+" "+institution),
+			Degree:         degree,
+			Institution:    normalizeName(institution),
+			Location:       strings.TrimSpace(e.Location),
+			GraduationDate: derefOrZero(grad),
+		})
+	}
+	return out, nil
+}
+
+// validateLLMSkills drops skills that do not occur in the CV text; the section is rejected
+// when fewer than half of the suggested skills survive.
+func validateLLMSkills(raw json.RawMessage, text string) ([]string, error) {
+	var skills []string
+	if err := decodeLLMSection(raw, &skills); err != nil {
+		return nil, err
+	}
+	if len(skills) > maxLLMSkills {
+		return nil, fmt.Errorf("%d skills exceed the limit of %d", len(skills), maxLLMSkills)
+	}
+
+	var grounded []string
+	for _, s := range skills {
+		s = sanitizeSkill(s)
+		if s == "" || len(s) > 50 {
+			continue
+		}
+		if groundedIn(text, s) {
+			grounded = append(grounded, s)
+		}
+	}
+	if len(skills) > 0 && len(grounded)*2 < len(skills) {
+		return nil, fmt.Errorf("only %d of %d skills are found in the CV text", len(grounded), len(skills))
+	}
+	return dedupeStrings(grounded), nil
+}
+
+// groundedIn reports whether value occurs in the CV text, which must already be lowercased
+// with its whitespace collapsed. Empty values are trivially grounded.
+func groundedIn(text, value string) bool {
+	value = strings.Join(strings.Fields(strings.ToLower(value)), " ")
+	return value == "" || strings.Contains(text, value)
+}
+
+// parseLLMDate accepts an empty value or "present" as no date and otherwise requires one of
+// the given layouts.
+func parseLLMDate(s string, layouts ...string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "present") {
+		return nil, nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%q does not match %s", s, strings.Join(layouts, " or "))
+}
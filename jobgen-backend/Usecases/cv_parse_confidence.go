@@ -0,0 +1,63 @@
+package usecases
+
+import (
+	domain "jobgen-backend/Domain"
+	"math"
+)
+
+// scoreCoreSections sets the confidence of the skills, experiences and educations sections
+// from how complete the parsed entries are. Sections that were not found get no entry, so
+// they are not sent for review.
+func scoreCoreSections(cv *domain.CV) {
+	set := func(field string, c float64) {
+		if cv.FieldConfidence == nil {
+			cv.FieldConfidence = map[string]float64{}
+		}
+		cv.FieldConfidence[field] = math.Round(c*100) / 100
+	}
+
+	if n := len(cv.Skills); n > 0 {
+		// a handful of skills is normal; one or two usually means the list was not split well
+		set("skills", math.Min(0.95, 0.5+0.09*float64(n)))
+	}
+
+	if len(cv.Experiences) > 0 {
+		total := 0.0
+		for _, e := range cv.Experiences {
+			total += completeness(
+				weighted{0.3, e.Title != ""},
+				weighted{0.3, e.Company != ""},
+				weighted{0.3, !e.StartDate.IsZero()},
+				weighted{0.1, e.Description != ""},
+			)
+		}
+		set("experiences", 0.4+0.55*total/float64(len(cv.Experiences)))
+	}
+
+	if len(cv.Educations) > 0 {
+		total := 0.0
+		for _, e := range cv.Educations {
+			total += completeness(
+				weighted{0.35, e.Degree != ""},
+				weighted{0.35, e.Institution != ""},
+				weighted{0.3, !e.GraduationDate.IsZero()},
+			)
+		}
+		set("educations", 0.4+0.55*total/float64(len(cv.Educations)))
+	}
+}
+
+type weighted struct {
+	weight  float64
+	present bool
+}
+
+func completeness(parts ...weighted) float64 {
+	score := 0.0
+	for _, p := range parts {
+		if p.present {
+			score += p.weight
+		}
+	}
+	return score
+}
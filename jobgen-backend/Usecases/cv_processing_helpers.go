@@ -12,19 +12,19 @@ import (
 // severityWeights controls the initial deduction per suggestion type (before diminishing returns).
 // Tuned to be less punitive.
 var severityWeights = map[string]int{
-	"quantification":    12,
-	"weak_action_verbs": 6,
-	"missing_keywords":  8,
+	domain.SuggestionQuantification:  12,
+	domain.SuggestionWeakActionVerbs: 6,
+	domain.SuggestionMissingKeywords: 8,
 }
 
 // perTypeCaps limits the maximum total deduction per suggestion type.
 var perTypeCaps = map[string]int{
-	"quantification":    24,
-	"weak_action_verbs": 12,
-	"missing_keywords":  16,
+	domain.SuggestionQuantification:  24,
+	domain.SuggestionWeakActionVerbs: 12,
+	domain.SuggestionMissingKeywords: 16,
 }
 
-// CalculateScore computes the CV score based on suggestions.
+// CalculateScore computes the CV score based on the suggestions that have not been applied.
 func CalculateScore(suggestions []domain.Suggestion) int {
 	const overallMaxDeduction = 40 // don't penalize beyond this overall
 
@@ -34,6 +34,9 @@ func CalculateScore(suggestions []domain.Suggestion) int {
 	totalDeduction := 0
 
 	for _, s := range suggestions {
+		if s.Applied {
+			continue // the CV no longer has this problem
+		}
 		base, ok := severityWeights[s.Type]
 		if !ok {
 			continue // ignore unknown types
@@ -94,43 +97,146 @@ func ParseTextToCVSections(rawText string) (*domain.CV, error) {
 	var sectionContent strings.Builder
 
 	processSection := func() {
-		content := strings.TrimSpace(sectionContent.String())
-		if content == "" {
-			return
-		}
-		switch currentSection {
-		case "skills":
-			cv.Skills = dedupeStrings(parseSkills(content, 200))
-		case "experience":
-			cv.Experiences = append(cv.Experiences, parseExperienceBlock(content)...)
-		case "education":
-			cv.Educations = append(cv.Educations, parseEducationBlock(content)...)
-		}
+		applySectionContent(cv, currentSection, sectionContent.String())
 		sectionContent.Reset()
 	}
 
+	var header []string
 	for _, line := range lines {
-		switch {
-		case experienceHeaderRegex.MatchString(line):
-			processSection()
-			currentSection = "experience"
-		case educationHeaderRegex.MatchString(line):
+		if section := sectionForHeading(line); section != "" {
 			processSection()
-			currentSection = "education"
-		case skillsHeaderRegex.MatchString(line):
-			processSection()
-			currentSection = "skills"
-		default:
-			if currentSection != "" {
-				sectionContent.WriteString(line + "\n")
-			}
+			currentSection = section
+			continue
+		}
+		if currentSection != "" {
+			sectionContent.WriteString(line + "\n")
+		} else if trimmed := strings.TrimSpace(line); trimmed != "" && len(header) < 15 {
+			header = append(header, trimmed)
 		}
 	}
 	processSection() // Process the last section
+	scoreCoreSections(cv)
+	extractProfileFields(cv, header, normalized, "")
+
+	return cv, nil
+}
+
+// ParseDocumentToCVSections parses layout blocks into structured CV sections. Headings
+// decide section boundaries, so body lines that merely mention "experience" or "skills" no
+// longer start a new section and sidebar headings such as "Languages" end the previous one.
+// Documents without detected headings fall back to ParseTextToCVSections.
+func ParseDocumentToCVSections(doc *domain.CVDocument) (*domain.CV, error) {
+	if !doc.HasHeadings() {
+		return ParseTextToCVSections(doc.PlainText())
+	}
+
+	// Headings that match a known section set the visual level of a section title; other
+	// headings at that level start an unknown section, smaller ones are entry titles.
+	sectionLevel := 0.0
+	for _, blk := range doc.Blocks {
+		if blk.Kind == domain.BlockHeading && sectionForHeading(blk.Text) != "" {
+			if sectionLevel == 0 || blk.FontSize < sectionLevel {
+				sectionLevel = blk.FontSize
+			}
+		}
+	}
+	if sectionLevel == 0 {
+		return ParseTextToCVSections(doc.PlainText())
+	}
+
+	cv := &domain.CV{}
+	var currentSection string
+	var sectionContent strings.Builder
+	var header []string
+	nameHint := ""
+	inHeader := true
+	for i, blk := range doc.Blocks {
+		if blk.Kind == domain.BlockHeading && (sectionForHeading(blk.Text) != "" || (i > 0 && blk.FontSize <= sectionLevel+0.5)) {
+			inHeader = false
+		}
+		if inHeader {
+			if i == 0 && blk.Kind == domain.BlockHeading {
+				nameHint = blk.Text
+			}
+			header = append(header, nonEmptyLines(blk.Text)...)
+			continue
+		}
+		if blk.Kind == domain.BlockHeading {
+			if section := sectionForHeading(blk.Text); section != "" {
+				applySectionContent(cv, currentSection, sectionContent.String())
+				sectionContent.Reset()
+				currentSection = section
+				continue
+			}
+			if blk.FontSize >= sectionLevel-0.5 {
+				applySectionContent(cv, currentSection, sectionContent.String())
+				sectionContent.Reset()
+				currentSection = ""
+				continue
+			}
+		}
+		if currentSection == "" {
+			continue
+		}
+		if blk.Kind == domain.BlockBullet {
+			sectionContent.WriteString("• " + blk.Text + "\n")
+			continue
+		}
+		if sectionContent.Len() > 0 {
+			sectionContent.WriteString("\n") // blank line starts a new entry
+		}
+		sectionContent.WriteString(blk.Text + "\n")
+	}
+	applySectionContent(cv, currentSection, sectionContent.String())
+	scoreCoreSections(cv)
+	extractProfileFields(cv, header, doc.PlainText(), nameHint)
 
 	return cv, nil
 }
 
+func sectionForHeading(text string) string {
+	switch {
+	case languagesHeaderRegex.MatchString(text):
+		return "languages"
+	case certificationsHeaderRegex.MatchString(text):
+		return "certifications"
+	case projectsHeaderRegex.MatchString(text):
+		return "projects"
+	case experienceHeaderRegex.MatchString(text):
+		return "experience"
+	case educationHeaderRegex.MatchString(text):
+		return "education"
+	case skillsHeaderRegex.MatchString(text):
+		return "skills"
+	}
+	return ""
+}
+
+// applySectionContent parses the collected text of one section into the CV
+func applySectionContent(cv *domain.CV, section, content string) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return
+	}
+	switch section {
+	case "skills":
+		cv.Skills = dedupeStrings(parseSkills(content, 200))
+	case "experience":
+		cv.Experiences = append(cv.Experiences, parseExperienceBlock(content)...)
+	case "education":
+		cv.Educations = append(cv.Educations, parseEducationBlock(content)...)
+	case "languages":
+		cv.Languages = append(cv.Languages, parseLanguages(content)...)
+		setSectionConfidence(cv, "languages", len(cv.Languages), len(splitListItems(content)))
+	case "certifications":
+		cv.Certifications = append(cv.Certifications, parseCertifications(content)...)
+		setSectionConfidence(cv, "certifications", len(cv.Certifications), len(nonEmptyLines(content)))
+	case "projects":
+		cv.Projects = append(cv.Projects, parseProjects(content)...)
+		setSectionConfidence(cv, "projects", len(cv.Projects), len(regexp.MustCompile(`\n\s*\n+`).Split(content, -1)))
+	}
+}
+
 // normalizeText makes line endings consistent and collapses repeated whitespace.
 func normalizeText(s string) string {
 	s = strings.ReplaceAll(s, "\r\n", "\n")
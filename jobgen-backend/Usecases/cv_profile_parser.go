@@ -0,0 +1,354 @@
+package usecases
+
+import (
+	domain "jobgen-backend/Domain"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Extraction of contact details, links, spoken languages, certifications and projects.
+// Every value found is given a confidence between 0 and 1 in cv.FieldConfidence; values
+// taken from the CV header or from a dedicated section score higher than ones picked up
+// elsewhere in the text.
+
+var (
+	languagesHeaderRegex      = regexp.MustCompile(`(?i)^\W*(languages?|language\s*skills|spoken\s*languages)\W*$`)
+	certificationsHeaderRegex = regexp.MustCompile(`(?i)^\W*(certifications?|certificates|licen[sc]es(\s*(&|and)\s*certifications?)?|certifications?\s*(&|and)\s*licen[sc]es)\W*$`)
+	projectsHeaderRegex       = regexp.MustCompile(`(?i)^\W*((personal|selected|key|side|academic)\s+)?projects\W*$`)
+
+	emailRE      = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`)
+	phoneRE      = regexp.MustCompile(`(?:\+|\b)\d[\d\s().-]{7,18}\d\b`)
+	phoneLabelRE = regexp.MustCompile(`(?i)\b(phone|tel|mobile|cell)\b`)
+	yearRangeRE  = regexp.MustCompile(`^\d{4}\s*[-–]\s*\d{4}$`)
+	urlRE        = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s|,;()<>]+|\b(?:linkedin\.com|github\.com)/[^\s|,;()<>]+`)
+	domainRE     = regexp.MustCompile(`(?i)^[a-z0-9-]+(\.[a-z0-9-]+)*\.(dev|io|me|com|net|org|app|site|tech|xyz)(/[^\s]*)?$`)
+	locationRE   = regexp.MustCompile(`^[\p{Lu}][\p{L} .'-]+,\s*[\p{Lu}][\p{L} .'-]+$`)
+	locLabelRE   = regexp.MustCompile(`(?i)^\s*(location|address|based in)\s*:\s*(.+)$`)
+	cefrRE       = regexp.MustCompile(`\b([ABC][12])\b`)
+	techLabelRE  = regexp.MustCompile(`(?i)^\W*(tech(nologies|nology|\s*stack)?|stack|built\s+with|tools)\s*:\s*(.+)$`)
+)
+
+// proficiencyWords maps wording found on CVs to the normalised proficiency levels
+var proficiencyWords = []struct{ word, level string }{
+	{"mother tongue", "native"}, {"native", "native"}, {"bilingual", "fluent"},
+	{"full professional", "fluent"}, {"fluent", "fluent"}, {"advanced", "fluent"},
+	{"professional working", "professional"}, {"professional", "professional"}, {"proficient", "professional"},
+	{"upper intermediate", "intermediate"}, {"intermediate", "intermediate"}, {"conversational", "intermediate"},
+	{"limited working", "basic"}, {"elementary", "basic"}, {"beginner", "basic"}, {"basic", "basic"},
+}
+
+// extractProfileFields fills the contact details from the CV header (with the whole text
+// as a lower-confidence fallback) and records the confidence of each field found.
+// nameHint is a name already identified from layout, such as the largest heading.
+func extractProfileFields(cv *domain.CV, header []string, fullText, nameHint string) {
+	setConfidence := func(field string, c float64) {
+		if cv.FieldConfidence == nil {
+			cv.FieldConfidence = map[string]float64{}
+		}
+		cv.FieldConfidence[field] = c
+	}
+
+	// Split header lines on the separators used in contact bars ("a | b • c")
+	var segments []string
+	for _, line := range header {
+		for _, seg := range regexp.MustCompile(`\s*[|•·]\s*|\s{3,}`).Split(line, -1) {
+			if seg = strings.TrimSpace(seg); seg != "" {
+				segments = append(segments, seg)
+			}
+		}
+	}
+	headerText := strings.Join(header, "\n")
+
+	// Email
+	if m := emailRE.FindString(headerText); m != "" {
+		cv.Contact.Email = strings.ToLower(m)
+		setConfidence("contact.email", 0.95)
+	} else if m := emailRE.FindString(fullText); m != "" {
+		cv.Contact.Email = strings.ToLower(m)
+		setConfidence("contact.email", 0.8)
+	}
+
+	// Phone
+	if phone, labelled := findPhone(header); phone != "" {
+		cv.Contact.Phone = phone
+		switch {
+		case labelled || strings.HasPrefix(phone, "+"):
+			setConfidence("contact.phone", 0.9)
+		default:
+			setConfidence("contact.phone", 0.75)
+		}
+	} else if phone, labelled := findPhone(strings.Split(fullText, "\n")); phone != "" && labelled {
+		cv.Contact.Phone = phone
+		setConfidence("contact.phone", 0.6)
+	}
+
+	// Links
+	links := findLinks(headerText)
+	inHeader := true
+	if len(links) == 0 {
+		links, inHeader = findLinks(fullText), false
+	}
+	for _, link := range links {
+		lower := strings.ToLower(link)
+		switch {
+		case strings.Contains(lower, "linkedin.com/") && cv.Contact.LinkedIn == "":
+			cv.Contact.LinkedIn = link
+			setConfidence("contact.linkedin", pickConfidence(inHeader, 0.95, 0.85))
+		case strings.Contains(lower, "github.com/") && cv.Contact.GitHub == "":
+			cv.Contact.GitHub = link
+			setConfidence("contact.github", pickConfidence(inHeader, 0.95, 0.85))
+		case !strings.Contains(lower, "linkedin.com") && !strings.Contains(lower, "github.com") && cv.Contact.Portfolio == "" && inHeader:
+			cv.Contact.Portfolio = link
+			setConfidence("contact.portfolio", 0.7)
+		}
+	}
+	if cv.Contact.Portfolio == "" {
+		for _, seg := range segments {
+			lower := strings.ToLower(seg)
+			if domainRE.MatchString(seg) && !strings.Contains(lower, "linkedin.com") && !strings.Contains(lower, "github.com") {
+				cv.Contact.Portfolio = normalizeURL(seg)
+				setConfidence("contact.portfolio", 0.6)
+				break
+			}
+		}
+	}
+
+	// Location
+	for _, line := range header {
+		if m := locLabelRE.FindStringSubmatch(line); m != nil {
+			cv.Contact.Location = strings.TrimSpace(m[2])
+			setConfidence("contact.location", 0.85)
+			break
+		}
+	}
+	if cv.Contact.Location == "" {
+		for _, seg := range segments {
+			if locationRE.MatchString(seg) && len(seg) <= 50 {
+				cv.Contact.Location = seg
+				setConfidence("contact.location", 0.65)
+				break
+			}
+		}
+	}
+
+	// Name
+	if looksLikeName(nameHint) {
+		cv.Contact.Name = strings.TrimSpace(nameHint)
+		setConfidence("contact.name", 0.9)
+	} else {
+		for i, line := range header {
+			if looksLikeName(line) {
+				cv.Contact.Name = strings.TrimSpace(line)
+				setConfidence("contact.name", pickConfidence(i == 0, 0.8, 0.55))
+				break
+			}
+		}
+	}
+}
+
+func pickConfidence(cond bool, yes, no float64) float64 {
+	if cond {
+		return yes
+	}
+	return no
+}
+
+// findPhone returns the first phone-like number and whether its line carried a phone label
+func findPhone(lines []string) (string, bool) {
+	for _, line := range lines {
+		for _, m := range phoneRE.FindAllString(line, -1) {
+			m = strings.TrimSpace(m)
+			digits := 0
+			for _, r := range m {
+				if unicode.IsDigit(r) {
+					digits++
+				}
+			}
+			if digits < 9 || digits > 15 || yearRangeRE.MatchString(m) {
+				continue
+			}
+			return m, phoneLabelRE.MatchString(line)
+		}
+	}
+	return "", false
+}
+
+func findLinks(text string) []string {
+	var out []string
+	for _, m := range urlRE.FindAllString(text, -1) {
+		if strings.Contains(m, "@") {
+			continue
+		}
+		out = append(out, normalizeURL(strings.TrimRight(m, ".")))
+	}
+	return out
+}
+
+func normalizeURL(s string) string {
+	lower := strings.ToLower(s)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return s
+	}
+	return "https://" + s
+}
+
+// looksLikeName accepts two to four capitalised words made of letters only
+func looksLikeName(s string) bool {
+	s = strings.TrimSpace(s)
+	words := strings.Fields(s)
+	if len(words) < 2 || len(words) > 4 || len(s) > 50 {
+		return false
+	}
+	if sectionForHeading(s) != "" || looksLikeRole(s) {
+		return false
+	}
+	for _, w := range words {
+		r := []rune(w)
+		if !unicode.IsUpper(r[0]) {
+			return false
+		}
+		for _, c := range r {
+			if !unicode.IsLetter(c) && c != '.' && c != '\'' && c != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseLanguages reads entries such as "English (Native)", "French - B2" or "Amharic: fluent"
+func parseLanguages(content string) []domain.Language {
+	var out []domain.Language
+	seen := map[string]bool{}
+	for _, item := range splitListItems(content) {
+		name, level := item, ""
+		if m := cefrRE.FindString(item); m != "" {
+			level = m
+		}
+		lower := strings.ToLower(item)
+		if level == "" {
+			for _, pw := range proficiencyWords {
+				if strings.Contains(lower, pw.word) {
+					level = pw.level
+					break
+				}
+			}
+		}
+		// The language name is the text before the first separator
+		if i := strings.IndexAny(name, "(:-–—/"); i > 0 {
+			name = name[:i]
+		} else if level != "" {
+			name = strings.Fields(name)[0]
+		}
+		name = strings.TrimSpace(name)
+		if name == "" || len(strings.Fields(name)) > 2 || strings.ContainsFunc(name, unicode.IsDigit) {
+			continue
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, domain.Language{Name: name, Proficiency: level})
+	}
+	return out
+}
+
+// parseCertifications reads one certification per line: "Name - Issuer, 2022"
+func parseCertifications(content string) []domain.Certification {
+	var out []domain.Certification
+	for _, line := range nonEmptyLines(content) {
+		line = strings.TrimSpace(strings.TrimLeft(line, "•-*–—· "))
+		if line == "" {
+			continue
+		}
+		cert := domain.Certification{ID: "cert-" + hashString(line)}
+		if d := extractSingleDate(line); d != nil {
+			cert.Date = d
+			line = strings.TrimSpace(strings.Trim(dateTokenRE.ReplaceAllString(line, ""), " ,()-–—|"))
+		}
+		parts := regexp.MustCompile(`\s+[-–—|]\s+|,\s+`).Split(line, 2)
+		cert.Name = strings.TrimSpace(parts[0])
+		if len(parts) == 2 {
+			cert.Issuer = strings.TrimSpace(strings.Trim(parts[1], " ,()"))
+		}
+		if cert.Name != "" {
+			out = append(out, cert)
+		}
+	}
+	return out
+}
+
+// parseProjects treats each blank-line separated block as a project: the first line holds
+// the name (optionally followed by a URL or technologies), the rest is the description.
+func parseProjects(content string) []domain.Project {
+	var out []domain.Project
+	for _, b := range regexp.MustCompile(`\n\s*\n+`).Split(strings.TrimSpace(content), -1) {
+		lines := nonEmptyLines(b)
+		if len(lines) == 0 {
+			continue
+		}
+		header := strings.TrimSpace(strings.TrimLeft(lines[0], "•-*–—· "))
+		p := domain.Project{ID: "proj-" + hashString(header)}
+
+		if links := findLinks(header); len(links) > 0 {
+			p.URL = links[0]
+			header = strings.TrimSpace(urlRE.ReplaceAllString(header, ""))
+		}
+		parts := regexp.MustCompile(`\s+[-–—|]\s+|\s*\|\s*`).Split(header, 2)
+		p.Name = strings.Trim(strings.TrimSpace(parts[0]), " :-–—|")
+		if len(parts) == 2 && strings.Contains(parts[1], ",") {
+			p.Technologies = parseSkills(parts[1], 30)
+		}
+
+		var desc []string
+		for _, ln := range lines[1:] {
+			if m := techLabelRE.FindStringSubmatch(ln); m != nil {
+				p.Technologies = dedupeStrings(append(p.Technologies, parseSkills(m[3], 30)...))
+				continue
+			}
+			if p.URL == "" {
+				if links := findLinks(ln); len(links) > 0 {
+					p.URL = links[0]
+				}
+			}
+			desc = append(desc, ln)
+		}
+		p.Description = strings.Join(desc, "\n")
+		if p.Name != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// splitListItems splits a section on newlines, bullets, commas and semicolons
+func splitListItems(content string) []string {
+	var out []string
+	for _, item := range strings.FieldsFunc(content, func(r rune) bool {
+		switch r {
+		case '\n', ',', ';', '•', '·', '|':
+			return true
+		}
+		return false
+	}) {
+		if item = strings.TrimSpace(strings.TrimLeft(item, "-*–— ")); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// setSectionConfidence records how sure the parser is about a list section: found under its
+// own heading and with every entry parsed, confidence is high.
+func setSectionConfidence(cv *domain.CV, field string, parsed, candidates int) {
+	if parsed == 0 {
+		return
+	}
+	c := 0.6 + 0.3*float64(parsed)/float64(max(parsed, candidates))
+	if cv.FieldConfidence == nil {
+		cv.FieldConfidence = map[string]float64{}
+	}
+	cv.FieldConfidence[field] = float64(int(c*100+0.5)) / 100
+}
@@ -0,0 +1,248 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	domain "jobgen-backend/Domain"
+	"log"
+	"strings"
+)
+
+// GetReview lists the fields of a parsed CV that need the user's confirmation, with their
+// parsed values. For CVs that were completed without review it lists the fields that are
+// still below the confidence threshold.
+func (uc *cvUsecase) GetReview(userID, cvID string) (*domain.CVReview, error) {
+	cv, err := getOwnedCompletedCV(uc.repo, userID, cvID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := cv.ReviewFields
+	if len(fields) == 0 {
+		fields = cv.LowConfidenceFields()
+	}
+
+	review := &domain.CVReview{CVID: cv.ID, Status: cv.Status, Fields: []domain.CVReviewField{}}
+	for _, f := range fields {
+		value, ok := reviewFieldValue(cv, f)
+		if !ok {
+			continue
+		}
+		review.Fields = append(review.Fields, domain.CVReviewField{
+			Field:      f,
+			Confidence: cv.FieldConfidence[f],
+			Value:      value,
+		})
+	}
+	return review, nil
+}
+
+// SubmitReview applies the user's confirmations and corrections and stores the result as a
+// new version. Reviewed fields get full confidence, and each one is stored as a labelled
+// example for evaluating the parser. Once no field is left to review the new version is
+// Completed rather than NeedsReview. Pending suggestions that quote text the user corrected
+// no longer apply, so they are dropped and the score recalculated.
+func (uc *cvUsecase) SubmitReview(ctx context.Context, userID, cvID string, corrections []domain.CVFieldCorrection) (*domain.CV, error) {
+	if len(corrections) == 0 {
+		return nil, fmt.Errorf("%w: at least one field is required", domain.ErrInvalidInput)
+	}
+
+	base, err := getOwnedCompletedCV(uc.repo, userID, cvID)
+	if err != nil {
+		return nil, err
+	}
+	next := *base
+
+	labels := make([]domain.CVParseLabel, 0, len(corrections))
+	reviewed := map[string]bool{}
+	for _, c := range corrections {
+		before, ok := reviewFieldValue(&next, c.Field)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", domain.ErrInvalidInput, c.Field)
+		}
+		parsed, _ := json.Marshal(before)
+
+		switch c.Action {
+		case domain.ReviewConfirm:
+		case domain.ReviewCorrect:
+			if len(c.Value) == 0 {
+				return nil, fmt.Errorf("%w: value is required to correct %s", domain.ErrInvalidInput, c.Field)
+			}
+			if err := applyFieldCorrection(&next, c.Field, c.Value); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", domain.ErrInvalidInput, c.Field, err)
+			}
+		default:
+			return nil, fmt.Errorf("%w: action must be confirm or correct", domain.ErrInvalidInput)
+		}
+
+		after, _ := reviewFieldValue(&next, c.Field)
+		labelled, _ := json.Marshal(after)
+		labels = append(labels, domain.CVParseLabel{
+			CVID:        base.ID,
+			UserID:      userID,
+			Field:       c.Field,
+			Confidence:  base.FieldConfidence[c.Field],
+			ParsedValue: string(parsed),
+			LabelValue:  string(labelled),
+			Confirmed:   c.Action == domain.ReviewConfirm,
+			RawText:     base.RawText,
+		})
+		reviewed[c.Field] = true
+	}
+
+	assignMissingIDs(&next)
+	next.TargetJobID = ""
+	next.FieldConfidence = make(map[string]float64, len(base.FieldConfidence)+len(reviewed))
+	for f, c := range base.FieldConfidence {
+		next.FieldConfidence[f] = c
+	}
+	for f := range reviewed {
+		next.FieldConfidence[f] = 1
+	}
+	var remaining []string
+	for _, f := range base.ReviewFields {
+		if !reviewed[f] {
+			remaining = append(remaining, f)
+		}
+	}
+	next.ReviewFields = remaining
+	next.Status = domain.StatusCompleted
+	if base.Status == domain.StatusNeedsReview && len(remaining) > 0 {
+		next.Status = domain.StatusNeedsReview
+	}
+
+	keepApplicableSuggestions(&next, base.Suggestions)
+
+	saved, err := uc.saveNewVersion(base, next, domain.OriginReview)
+	if err != nil {
+		return nil, err
+	}
+	// The labels only feed parser evaluation, so a failure here must not fail the review
+	if err := uc.labelRepo.CreateMany(ctx, labels); err != nil {
+		log.Printf("failed to store parse labels for cv %s: %v", base.ID, err)
+	}
+	return saved, nil
+}
+
+// keepApplicableSuggestions gives the CV the suggestions that were already settled or still
+// apply to its content, and scores it on them.
+func keepApplicableSuggestions(cv *domain.CV, suggestions []domain.Suggestion) {
+	cv.Suggestions = nil
+	for _, sg := range suggestions {
+		if sg.Applied || sg.Rejected || suggestionStillApplies(cv, sg) {
+			cv.Suggestions = append(cv.Suggestions, sg)
+		}
+	}
+	cv.Score = CalculateScore(cv.Suggestions)
+}
+
+// suggestionStillApplies reports whether a suggestion could still be applied to the CV, that
+// is whether the text it quotes is still there. Suggestions that cannot be applied
+// automatically are kept.
+func suggestionStillApplies(cv *domain.CV, sg domain.Suggestion) bool {
+	scratch := *cv
+	scratch.Experiences = append([]domain.Experience(nil), cv.Experiences...)
+	scratch.Skills = append([]string(nil), cv.Skills...)
+	return !errors.Is(applySuggestion(&scratch, sg), errSuggestionTargetGone)
+}
+
+// reviewFieldValue returns the current value of a reviewable field
+func reviewFieldValue(cv *domain.CV, field string) (interface{}, bool) {
+	switch field {
+	case "contact.name":
+		return cv.Contact.Name, true
+	case "contact.email":
+		return cv.Contact.Email, true
+	case "contact.phone":
+		return cv.Contact.Phone, true
+	case "contact.location":
+		return cv.Contact.Location, true
+	case "contact.linkedin":
+		return cv.Contact.LinkedIn, true
+	case "contact.github":
+		return cv.Contact.GitHub, true
+	case "contact.portfolio":
+		return cv.Contact.Portfolio, true
+	case "skills":
+		return cv.Skills, true
+	case "experiences":
+		return cv.Experiences, true
+	case "educations":
+		return cv.Educations, true
+	case "languages":
+		return cv.Languages, true
+	case "certifications":
+		return cv.Certifications, true
+	case "projects":
+		return cv.Projects, true
+	}
+	return nil, false
+}
+
+// applyFieldCorrection decodes a corrected value in the field's JSON shape onto the CV
+func applyFieldCorrection(cv *domain.CV, field string, raw json.RawMessage) error {
+	if strings.HasPrefix(field, "contact.") {
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("expected a string")
+		}
+		v = strings.TrimSpace(v)
+		switch field {
+		case "contact.name":
+			cv.Contact.Name = v
+		case "contact.email":
+			cv.Contact.Email = strings.ToLower(v)
+		case "contact.phone":
+			cv.Contact.Phone = v
+		case "contact.location":
+			cv.Contact.Location = v
+		case "contact.linkedin":
+			cv.Contact.LinkedIn = v
+		case "contact.github":
+			cv.Contact.GitHub = v
+		case "contact.portfolio":
+			cv.Contact.Portfolio = v
+		}
+		return nil
+	}
+
+	var err error
+	switch field {
+	case "skills":
+		var skills []string
+		if err = json.Unmarshal(raw, &skills); err == nil {
+			cv.Skills = dedupeStrings(skills)
+		}
+	case "experiences":
+		var v []domain.Experience
+		if err = json.Unmarshal(raw, &v); err == nil {
+			cv.Experiences = v
+		}
+	case "educations":
+		var v []domain.Education
+		if err = json.Unmarshal(raw, &v); err == nil {
+			cv.Educations = v
+		}
+	case "languages":
+		var v []domain.Language
+		if err = json.Unmarshal(raw, &v); err == nil {
+			cv.Languages = v
+		}
+	case "certifications":
+		var v []domain.Certification
+		if err = json.Unmarshal(raw, &v); err == nil {
+			cv.Certifications = v
+		}
+	case "projects":
+		var v []domain.Project
+		if err = json.Unmarshal(raw, &v); err == nil {
+			cv.Projects = v
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("expected a list in the field's format")
+	}
+	return nil
+}
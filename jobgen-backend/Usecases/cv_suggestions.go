@@ -0,0 +1,223 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	domain "jobgen-backend/Domain"
+	"regexp"
+	"strings"
+)
+
+// AcceptSuggestion applies an AI suggestion to the CV and stores the result as a new version
+// in which the suggestion is marked applied and the score is recalculated.
+func (uc *cvUsecase) AcceptSuggestion(userID, cvID, suggestionID string) (*domain.CV, error) {
+	base, err := getOwnedCompletedCV(uc.repo, userID, cvID)
+	if err != nil {
+		return nil, err
+	}
+	i, err := pendingSuggestion(base, suggestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := *base
+	next.TargetJobID = ""
+	// Copy the slices the suggestion may change so the base version is left untouched
+	next.Experiences = append([]domain.Experience(nil), base.Experiences...)
+	next.Skills = append([]string(nil), base.Skills...)
+	next.Suggestions = append([]domain.Suggestion(nil), base.Suggestions...)
+
+	if err := applySuggestion(&next, next.Suggestions[i]); err != nil {
+		return nil, err
+	}
+	next.Suggestions[i].Applied = true
+	next.Score = CalculateScore(next.Suggestions)
+
+	return uc.saveNewVersion(base, next, domain.OriginSuggestion)
+}
+
+// RejectSuggestion marks a suggestion as rejected. The CV content is unchanged, so no version
+// is created.
+func (uc *cvUsecase) RejectSuggestion(userID, cvID, suggestionID string) (*domain.CV, error) {
+	cv, err := getOwnedCompletedCV(uc.repo, userID, cvID)
+	if err != nil {
+		return nil, err
+	}
+	i, err := pendingSuggestion(cv, suggestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	cv.Suggestions[i].Rejected = true
+	if err := uc.repo.UpdateSuggestions(cv.ID, cv.Suggestions); err != nil {
+		return nil, fmt.Errorf("failed to save suggestions: %w", err)
+	}
+	return cv, nil
+}
+
+// pendingSuggestion returns the index of a suggestion that is neither applied nor rejected.
+func pendingSuggestion(cv *domain.CV, suggestionID string) (int, error) {
+	if strings.TrimSpace(suggestionID) == "" {
+		return -1, fmt.Errorf("%w: suggestion id is required", domain.ErrInvalidInput)
+	}
+	for i, sg := range cv.Suggestions {
+		if sg.ID != suggestionID {
+			continue
+		}
+		if sg.Applied {
+			return -1, fmt.Errorf("%w: suggestion has already been applied", domain.ErrInvalidInput)
+		}
+		if sg.Rejected {
+			return -1, fmt.Errorf("%w: suggestion has already been rejected", domain.ErrInvalidInput)
+		}
+		return i, nil
+	}
+	return -1, fmt.Errorf("%w: suggestion %s", domain.ErrNotFound, suggestionID)
+}
+
+// errSuggestionTargetGone marks a suggestion whose quoted text or experience was edited away.
+var errSuggestionTargetGone = errors.New("no longer in the cv")
+
+// applySuggestion writes a suggestion's replacement into the section it targets. A non-empty
+// Original must still be found in that section; an empty one adds the replacement.
+func applySuggestion(cv *domain.CV, sg domain.Suggestion) error {
+	if sg.Replacement == "" {
+		return fmt.Errorf("%w: suggestion has no replacement text; edit the cv instead", domain.ErrInvalidInput)
+	}
+	notFound := fmt.Errorf("%w: the text this suggestion replaces is %w", domain.ErrInvalidInput, errSuggestionTargetGone)
+
+	switch sg.Section {
+	case domain.SectionProfileSummary:
+		if sg.Original == "" {
+			cv.ProfileSummary = strings.TrimSpace(cv.ProfileSummary + " " + sg.Replacement)
+			return nil
+		}
+		updated, ok := replaceQuoted(cv.ProfileSummary, sg.Original, sg.Replacement)
+		if !ok {
+			return notFound
+		}
+		cv.ProfileSummary = updated
+
+	case domain.SectionExperience:
+		i, err := suggestionExperience(cv, sg)
+		if err != nil {
+			return err
+		}
+		exp := &cv.Experiences[i]
+		if sg.Original == "" {
+			exp.Description = strings.TrimSpace(exp.Description + "\n" + sg.Replacement)
+			return nil
+		}
+		updated, ok := replaceQuoted(exp.Description, sg.Original, sg.Replacement)
+		if !ok {
+			return notFound
+		}
+		exp.Description = updated
+
+	case domain.SectionSkills:
+		skills, ok := replaceSkills(cv.Skills, splitSkillList(sg.Original), splitSkillList(sg.Replacement))
+		if !ok {
+			return notFound
+		}
+		cv.Skills = skills
+
+	default:
+		return fmt.Errorf("%w: suggestions for the %q section cannot be applied automatically; edit the cv instead", domain.ErrInvalidInput, sg.Section)
+	}
+	return nil
+}
+
+// suggestionExperience finds the experience a suggestion targets: by ID, or for suggestions
+// made on raw text, the only experience whose description contains the original text.
+func suggestionExperience(cv *domain.CV, sg domain.Suggestion) (int, error) {
+	if sg.TargetID != "" {
+		for i, exp := range cv.Experiences {
+			if exp.ID == sg.TargetID {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("%w: experience %s is %w", domain.ErrInvalidInput, sg.TargetID, errSuggestionTargetGone)
+	}
+
+	match := -1
+	if sg.Original != "" {
+		re := quotedTextRegexp(sg.Original)
+		for i, exp := range cv.Experiences {
+			if !re.MatchString(exp.Description) {
+				continue
+			}
+			if match >= 0 {
+				match = -1 // ambiguous
+				break
+			}
+			match = i
+		}
+	}
+	if match < 0 {
+		return -1, fmt.Errorf("%w: cannot tell which experience this suggestion is for; edit the cv instead", domain.ErrInvalidInput)
+	}
+	return match, nil
+}
+
+// quotedTextRegexp matches text quoted from the CV regardless of case and spacing.
+func quotedTextRegexp(quoted string) *regexp.Regexp {
+	words := strings.Fields(quoted)
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(words, `\s+`))
+}
+
+// replaceQuoted replaces the first occurrence of original in text.
+func replaceQuoted(text, original, replacement string) (string, bool) {
+	if strings.TrimSpace(original) == "" {
+		return text, false
+	}
+	loc := quotedTextRegexp(original).FindStringIndex(text)
+	if loc == nil {
+		return text, false
+	}
+	return text[:loc[0]] + replacement + text[loc[1]:], true
+}
+
+func splitSkillList(s string) []string {
+	var out []string
+	for _, item := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// replaceSkills swaps the original skills for the replacement ones, keeping their position.
+// Every original skill must be present; with none the replacement skills are appended.
+func replaceSkills(skills, original, replacement []string) ([]string, bool) {
+	remove := make(map[string]bool, len(original))
+	for _, s := range original {
+		remove[strings.ToLower(s)] = true
+	}
+
+	out := make([]string, 0, len(skills)+len(replacement))
+	found := make(map[string]bool, len(original))
+	inserted := len(original) == 0
+	for _, s := range skills {
+		key := strings.ToLower(strings.TrimSpace(s))
+		if !remove[key] {
+			out = append(out, s)
+			continue
+		}
+		found[key] = true
+		if !inserted {
+			out = append(out, replacement...)
+			inserted = true
+		}
+	}
+	if len(found) < len(remove) {
+		return skills, false
+	}
+	if !inserted {
+		out = append(out, replacement...)
+	}
+	return dedupeStrings(out), true
+}
@@ -1,30 +1,69 @@
 package usecases
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	domain "jobgen-backend/Domain"
 	infrastructure "jobgen-backend/Infrastructure"
 	"mime/multipart"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// maxVersionSaveAttempts bounds retries when concurrent edits race for the same version number.
+const maxVersionSaveAttempts = 3
+
 type CVUsecase interface {
 	CreateParsingJob(userID string, fileHeader *multipart.FileHeader) (string, error)
 	CreateParsingJobFromFileID(userID string, fileID string) (string, error)
 	GetJobStatusAndResult(jobID string) (*domain.CV, error)
+	TailorForJob(ctx context.Context, userID, cvID, jobID string) (*domain.CV, error)
+
+	// Versioning
+	ListUserCVs(userID string) ([]domain.CV, error)
+	SetPrimaryCV(userID, cvID string) error
+	UpdateSections(userID, cvID string, update domain.CVSectionsUpdate) (*domain.CV, error)
+	GetVersionHistory(userID, cvID string) ([]domain.CVVersionSummary, error)
+	RestoreVersion(userID, cvID string, version int) (*domain.CV, error)
+
+	// AI suggestions: accepting one applies it as a new version
+	AcceptSuggestion(userID, cvID, suggestionID string) (*domain.CV, error)
+	RejectSuggestion(userID, cvID, suggestionID string) (*domain.CV, error)
+
+	// Review of low-confidence parse results
+	GetReview(userID, cvID string) (*domain.CVReview, error)
+	SubmitReview(ctx context.Context, userID, cvID string, corrections []domain.CVFieldCorrection) (*domain.CV, error)
+
+	// Admin: processing jobs the queue gave up on
+	ListDeadLetteredJobs() ([]domain.DeadLetteredJob, error)
+	RequeueDeadLetteredJob(jobID string) error
+
+	// Job progress: live events and webhooks called when a job finishes
+	SubscribeJobEvents(ctx context.Context, userID, jobID string) (*domain.CV, <-chan domain.CVJobEvent, error)
+	RegisterWebhook(ctx context.Context, userID, url string) (*domain.CVWebhook, error)
+	ListWebhooks(ctx context.Context, userID string) ([]domain.CVWebhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID string) error
 }
 
 type cvUsecase struct {
 	repo      domain.CVRepository
 	queue     infrastructure.QueueService
 	fileStore domain.FileStorageService // From file_management.go
+	jobRepo   domain.IJobRepository
+	aiService domain.IAIService
+	labelRepo domain.ICVParseLabelRepository
+
+	events      infrastructure.CVEventBus
+	webhookRepo domain.ICVWebhookRepository
 }
 
-func NewCVUsecase(repo domain.CVRepository, q infrastructure.QueueService, fs domain.FileStorageService) CVUsecase {
-	return &cvUsecase{repo: repo, queue: q, fileStore: fs}
+func NewCVUsecase(repo domain.CVRepository, q infrastructure.QueueService, fs domain.FileStorageService, jobRepo domain.IJobRepository, ai domain.IAIService, labelRepo domain.ICVParseLabelRepository, events infrastructure.CVEventBus, webhookRepo domain.ICVWebhookRepository) CVUsecase {
+	return &cvUsecase{repo: repo, queue: q, fileStore: fs, jobRepo: jobRepo, aiService: ai, labelRepo: labelRepo, events: events, webhookRepo: webhookRepo}
 }
 
 func (uc *cvUsecase) CreateParsingJob(userID string, fileHeader *multipart.FileHeader) (string, error) {
@@ -39,6 +78,18 @@ func (uc *cvUsecase) CreateParsingJob(userID string, fileHeader *multipart.FileH
 	}
 	defer file.Close()
 
+	// Sniff the content rather than trusting the extension so the worker never gets a file it cannot parse
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	if infrastructure.DetectCVFormat(data) == infrastructure.CVFormatUnknown {
+		return "", fmt.Errorf("%w: upload a PDF, DOCX, ODT, RTF or plain text file", domain.ErrUnsupportedCVFormat)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
 	// Integrate with File Storage Service
 	fileID, err := uc.fileStore.UploadFile(userID, "CV", fileHeader.Filename, file)
 	if err != nil {
@@ -52,6 +103,9 @@ func (uc *cvUsecase) CreateParsingJob(userID string, fileHeader *multipart.FileH
 		FileStorageID: fileID,
 		FileName:      fileHeader.Filename,
 		Status:        domain.StatusPending,
+		LineageID:     jobID,
+		Version:       1,
+		Origin:        domain.OriginUpload,
 		CreatedAt:     time.Now().UTC(),
 		UpdatedAt:     time.Now().UTC(),
 	}
@@ -70,6 +124,34 @@ func (uc *cvUsecase) GetJobStatusAndResult(jobID string) (*domain.CV, error) {
 	return uc.repo.GetByID(jobID)
 }
 
+func (uc *cvUsecase) ListDeadLetteredJobs() ([]domain.DeadLetteredJob, error) {
+	return uc.queue.ListDeadLetters()
+}
+
+// RequeueDeadLetteredJob resets the CV to Pending and hands its job back to the workers
+// with a fresh attempt budget.
+func (uc *cvUsecase) RequeueDeadLetteredJob(jobID string) error {
+	dead, err := uc.queue.ListDeadLetters()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, job := range dead {
+		if job.JobID == jobID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return domain.ErrNotFound
+	}
+
+	if err := uc.repo.UpdateStatus(jobID, domain.StatusPending, "requeued"); err != nil {
+		return fmt.Errorf("failed to reset cv status: %w", err)
+	}
+	return uc.queue.RequeueDeadLetter(jobID)
+}
+
 func (uc *cvUsecase) CreateParsingJobFromFileID(userID string, fileID string) (string, error) {
 	if strings.TrimSpace(fileID) == "" {
 		return "", errors.New("fileId is required")
@@ -81,6 +163,9 @@ func (uc *cvUsecase) CreateParsingJobFromFileID(userID string, fileID string) (s
 		FileStorageID: fileID,
 		FileName:      "",
 		Status:        domain.StatusPending,
+		LineageID:     jobID,
+		Version:       1,
+		Origin:        domain.OriginUpload,
 		CreatedAt:     time.Now().UTC(),
 		UpdatedAt:     time.Now().UTC(),
 	}
@@ -92,3 +177,303 @@ func (uc *cvUsecase) CreateParsingJobFromFileID(userID string, fileID string) (s
 	}
 	return jobID, nil
 }
+
+// TailorForJob asks the AI to rewrite the CV's free-text fields for a job and stores the
+// result as a new CV linked to the source CV and the job. Structured facts (titles, companies,
+// dates, education, skills) are copied verbatim; rewrites that introduce numbers not present
+// in the original CV are discarded.
+func (uc *cvUsecase) TailorForJob(ctx context.Context, userID, cvID, jobID string) (*domain.CV, error) {
+	if strings.TrimSpace(cvID) == "" || strings.TrimSpace(jobID) == "" {
+		return nil, fmt.Errorf("%w: cv id and job id are required", domain.ErrInvalidInput)
+	}
+
+	source, err := getOwnedCompletedCV(uc.repo, userID, cvID)
+	if err != nil {
+		return nil, err
+	}
+	job, err := uc.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	tailored, err := uc.aiService.TailorCV(domain.WithAIUser(ctx, userID), source, job)
+	if errors.Is(err, domain.ErrAIQuotaExceeded) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrServiceUnavailable, err)
+	}
+
+	result := *source
+	result.TargetJobID = job.ID
+
+	factText := source.RawText + "\n" + source.ProfileSummary
+	for _, exp := range source.Experiences {
+		factText += "\n" + exp.Description
+	}
+
+	if summary := strings.TrimSpace(tailored.ProfileSummary); summary != "" && !introducesNewNumbers(factText, summary) {
+		result.ProfileSummary = summary
+	}
+	result.Experiences = make([]domain.Experience, len(source.Experiences))
+	for i, exp := range source.Experiences {
+		if desc := strings.TrimSpace(tailored.ExperienceDescriptions[exp.ID]); desc != "" && !introducesNewNumbers(factText, desc) {
+			exp.Description = desc
+		}
+		result.Experiences[i] = exp
+	}
+	// Suggestions quoting text the rewrite replaced could no longer be accepted
+	keepApplicableSuggestions(&result, source.Suggestions)
+
+	return uc.saveNewVersion(source, result, domain.OriginTailored)
+}
+
+// ListUserCVs returns the latest version of each of the user's CVs, newest first. Tailored
+// copies only appear in their CV's version history.
+func (uc *cvUsecase) ListUserCVs(userID string) ([]domain.CV, error) {
+	all, err := uc.repo.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]int) // lineage -> index in out
+	out := []domain.CV{}
+	for _, cv := range all {
+		if cv.IsTailored() {
+			continue
+		}
+		lineage := cv.Lineage()
+		if i, ok := latest[lineage]; ok {
+			if cv.VersionNumber() > out[i].VersionNumber() {
+				out[i] = cv
+			}
+			continue
+		}
+		latest[lineage] = len(out)
+		out = append(out, cv)
+	}
+	return out, nil
+}
+
+// SetPrimaryCV marks the CV (all of its versions) as the user's primary CV
+func (uc *cvUsecase) SetPrimaryCV(userID, cvID string) error {
+	cv, err := getOwnedCV(uc.repo, userID, cvID)
+	if err != nil {
+		return err
+	}
+	return uc.repo.SetPrimary(userID, cv.Lineage())
+}
+
+// UpdateSections applies a manual edit on top of the given version and stores the result as a new version
+func (uc *cvUsecase) UpdateSections(userID, cvID string, update domain.CVSectionsUpdate) (*domain.CV, error) {
+	base, err := getOwnedCompletedCV(uc.repo, userID, cvID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := *base
+	next.TargetJobID = ""
+	if update.ProfileSummary != nil {
+		next.ProfileSummary = strings.TrimSpace(*update.ProfileSummary)
+	}
+	if update.Skills != nil {
+		next.Skills = dedupeStrings(*update.Skills)
+	}
+	if update.Experiences != nil {
+		next.Experiences = *update.Experiences
+	}
+	if update.Educations != nil {
+		next.Educations = *update.Educations
+	}
+	if update.Contact != nil {
+		next.Contact = *update.Contact
+	}
+	if update.Languages != nil {
+		next.Languages = *update.Languages
+	}
+	if update.Certifications != nil {
+		next.Certifications = *update.Certifications
+	}
+	if update.Projects != nil {
+		next.Projects = *update.Projects
+	}
+	assignMissingIDs(&next)
+
+	if len(DiffCVs(base, &next)) == 0 {
+		return nil, fmt.Errorf("%w: update does not change the cv", domain.ErrInvalidInput)
+	}
+	return uc.saveNewVersion(base, next, domain.OriginManualEdit)
+}
+
+// GetVersionHistory lists every version of the CV, oldest first, with the changes each introduced
+func (uc *cvUsecase) GetVersionHistory(userID, cvID string) ([]domain.CVVersionSummary, error) {
+	cv, err := getOwnedCV(uc.repo, userID, cvID)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := uc.repo.ListVersions(cv.Lineage())
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]domain.CVVersionSummary, 0, len(versions))
+	for _, v := range versions {
+		changes := v.Changes
+		if changes == nil {
+			changes = []domain.CVFieldChange{}
+		}
+		history = append(history, domain.CVVersionSummary{
+			ID:          v.ID,
+			Version:     v.VersionNumber(),
+			Origin:      v.Origin,
+			SourceCVID:  v.SourceCVID,
+			TargetJobID: v.TargetJobID,
+			Changes:     changes,
+			CreatedAt:   v.CreatedAt,
+		})
+	}
+	return history, nil
+}
+
+// RestoreVersion copies an earlier version's content into a new latest version
+func (uc *cvUsecase) RestoreVersion(userID, cvID string, version int) (*domain.CV, error) {
+	cv, err := getOwnedCV(uc.repo, userID, cvID)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := uc.repo.ListVersions(cv.Lineage())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range versions {
+		if versions[i].VersionNumber() == version {
+			target := &versions[i]
+			if !target.IsParsed() {
+				return nil, domain.ErrCVNotReady
+			}
+			return uc.saveNewVersion(target, *target, domain.OriginRestore)
+		}
+	}
+	return nil, fmt.Errorf("%w: version %d", domain.ErrNotFound, version)
+}
+
+// assignMissingIDs gives user-supplied entries the IDs that diffs and edits key on
+func assignMissingIDs(cv *domain.CV) {
+	for i := range cv.Experiences {
+		if cv.Experiences[i].ID == "" {
+			cv.Experiences[i].ID = "exp-" + uuid.NewString()[:8]
+		}
+	}
+	for i := range cv.Educations {
+		if cv.Educations[i].ID == "" {
+			cv.Educations[i].ID = "edu-" + uuid.NewString()[:8]
+		}
+	}
+	for i := range cv.Certifications {
+		if cv.Certifications[i].ID == "" {
+			cv.Certifications[i].ID = "cert-" + uuid.NewString()[:8]
+		}
+	}
+	for i := range cv.Projects {
+		if cv.Projects[i].ID == "" {
+			cv.Projects[i].ID = "proj-" + uuid.NewString()[:8]
+		}
+	}
+}
+
+// saveNewVersion stores next as the newest version in base's lineage, retrying with the next
+// number if another request took this one first. Changes are recorded against the latest
+// version, except for tailored copies which are diffed against the CV they were written from.
+func (uc *cvUsecase) saveNewVersion(base *domain.CV, next domain.CV, origin domain.CVVersionOrigin) (*domain.CV, error) {
+	for attempt := 1; ; attempt++ {
+		saved, err := uc.insertNextVersion(base, next, origin)
+		if errors.Is(err, domain.ErrCVVersionConflict) && attempt < maxVersionSaveAttempts {
+			continue
+		}
+		return saved, err
+	}
+}
+
+func (uc *cvUsecase) insertNextVersion(base *domain.CV, next domain.CV, origin domain.CVVersionOrigin) (*domain.CV, error) {
+	lineage := base.Lineage()
+	versions, err := uc.repo.ListVersions(lineage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cv versions: %w", err)
+	}
+	lastVersion := base.VersionNumber()
+	head := base
+	for i := range versions {
+		if versions[i].VersionNumber() > lastVersion {
+			lastVersion = versions[i].VersionNumber()
+		}
+		if !versions[i].IsTailored() {
+			head = &versions[i]
+		}
+	}
+	previous := head
+	if origin == domain.OriginTailored {
+		previous = base
+	}
+
+	now := time.Now().UTC()
+	next.ID = uuid.NewString()
+	next.LineageID = lineage
+	next.Version = lastVersion + 1
+	next.Origin = origin
+	next.IsPrimary = head.IsPrimary && origin != domain.OriginTailored
+	next.SourceCVID = base.ID
+	if origin != domain.OriginReview {
+		next.Status = domain.StatusCompleted
+		next.ReviewFields = nil // versions are authored by the user, so nothing is left to review
+	}
+	next.ProcessingError = ""
+	next.Changes = DiffCVs(previous, &next)
+	next.CreatedAt = now
+	next.UpdatedAt = now
+
+	if err := uc.repo.Create(&next); err != nil {
+		return nil, fmt.Errorf("failed to save cv version: %w", err)
+	}
+	return &next, nil
+}
+
+var numberTokenRE = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+
+// introducesNewNumbers reports whether rewritten contains a number that does not appear in source.
+func introducesNewNumbers(source, rewritten string) bool {
+	known := make(map[string]bool)
+	for _, n := range numberTokenRE.FindAllString(source, -1) {
+		known[n] = true
+	}
+	for _, n := range numberTokenRE.FindAllString(rewritten, -1) {
+		if !known[n] {
+			return true
+		}
+	}
+	return false
+}
+
+// getOwnedCV loads a CV and checks that it belongs to the user
+func getOwnedCV(repo domain.CVRepository, userID, cvID string) (*domain.CV, error) {
+	cv, err := repo.GetByID(cvID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cv: %w", err)
+	}
+	if cv.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+	return cv, nil
+}
+
+// getOwnedCompletedCV loads a CV and checks that it belongs to the user and has finished parsing
+func getOwnedCompletedCV(repo domain.CVRepository, userID, cvID string) (*domain.CV, error) {
+	cv, err := getOwnedCV(repo, userID, cvID)
+	if err != nil {
+		return nil, err
+	}
+	if !cv.IsParsed() {
+		return nil, domain.ErrCVNotReady
+	}
+	return cv, nil
+}
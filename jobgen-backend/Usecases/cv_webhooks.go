@@ -0,0 +1,76 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxWebhooksPerUser keeps a single job from fanning out to an unbounded number of callbacks.
+const maxWebhooksPerUser = 5
+
+// RegisterWebhook stores a callback URL for the user's CV jobs. The returned webhook carries
+// its signing secret, which is not shown again.
+func (uc *cvUsecase) RegisterWebhook(ctx context.Context, userID, rawURL string) (*domain.CVWebhook, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if err := validateWebhookURL(rawURL); err != nil {
+		return nil, err
+	}
+	existing, err := uc.webhookRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		return nil, fmt.Errorf("%w: at most %d webhooks can be registered", domain.ErrInvalidInput, maxWebhooksPerUser)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	hook := &domain.CVWebhook{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		URL:       rawURL,
+		Secret:    "whsec_" + hex.EncodeToString(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := uc.webhookRepo.Create(ctx, hook); err != nil {
+		return nil, fmt.Errorf("failed to save webhook: %w", err)
+	}
+	return hook, nil
+}
+
+func (uc *cvUsecase) ListWebhooks(ctx context.Context, userID string) ([]domain.CVWebhook, error) {
+	return uc.webhookRepo.ListByUser(ctx, userID)
+}
+
+func (uc *cvUsecase) DeleteWebhook(ctx context.Context, userID, webhookID string) error {
+	return uc.webhookRepo.Delete(ctx, userID, webhookID)
+}
+
+// validateWebhookURL accepts absolute http(s) URLs, refusing hosts that obviously point
+// back into our own network. Hostnames are checked again on every delivery, once resolved.
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Hostname() == "" {
+		return fmt.Errorf("%w: webhook url must be an absolute http or https url", domain.ErrInvalidInput)
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: webhook url must not point to localhost", domain.ErrInvalidInput)
+	}
+	if ip := net.ParseIP(host); ip != nil && infrastructure.BlockedWebhookIP(ip) {
+		return fmt.Errorf("%w: webhook url must not point to a private address", domain.ErrInvalidInput)
+	}
+	return nil
+}
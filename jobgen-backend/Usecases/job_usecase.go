@@ -2,31 +2,39 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	domain "jobgen-backend/Domain"
+	"log"
 	"time"
 )
 
 type jobUsecase struct {
 	jobRepo              domain.IJobRepository
 	userRepo             domain.IUserRepository
+	cvRepo               domain.CVRepository
 	jobAggregationSvc    domain.IJobAggregationService
 	jobMatchingSvc       domain.IJobMatchingService
+	aiService            domain.IAIService // optional; used for tailored suggestions
 	contextTimeout       time.Duration
 }
 
 func NewJobUsecase(
 	jobRepo domain.IJobRepository,
 	userRepo domain.IUserRepository,
+	cvRepo domain.CVRepository,
 	jobAggregationSvc domain.IJobAggregationService,
 	jobMatchingSvc domain.IJobMatchingService,
+	aiService domain.IAIService,
 	timeout time.Duration,
 ) domain.IJobUsecase {
 	return &jobUsecase{
 		jobRepo:           jobRepo,
 		userRepo:          userRepo,
+		cvRepo:            cvRepo,
 		jobAggregationSvc: jobAggregationSvc,
 		jobMatchingSvc:    jobMatchingSvc,
+		aiService:         aiService,
 		contextTimeout:    timeout,
 	}
 }
@@ -381,3 +389,81 @@ func (j *jobUsecase) GetTrendingJobs(ctx context.Context, limit int) ([]domain.J
 
 	return jobs, nil
 }
+
+// SubmitJobFeedback records a user's verdict on a matched job
+func (j *jobUsecase) SubmitJobFeedback(ctx context.Context, userID, jobID string, feedbackType domain.FeedbackType, reason string) (*domain.JobFeedback, error) {
+	ctx, cancel := context.WithTimeout(ctx, j.contextTimeout)
+	defer cancel()
+
+	if userID == "" || jobID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	switch feedbackType {
+	case domain.FeedbackThumbsUp, domain.FeedbackThumbsDown, domain.FeedbackNotInterested:
+	default:
+		return nil, domain.ErrInvalidFeedback
+	}
+
+	feedback := &domain.JobFeedback{
+		JobID:  jobID,
+		Type:   feedbackType,
+		Reason: reason,
+	}
+	if err := j.jobMatchingSvc.RecordFeedback(ctx, userID, feedback); err != nil {
+		return nil, fmt.Errorf("failed to record feedback: %w", err)
+	}
+
+	return feedback, nil
+}
+
+// GetMatchPrecisionReport returns aggregate match precision by score band
+func (j *jobUsecase) GetMatchPrecisionReport(ctx context.Context) (*domain.MatchPrecisionReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, j.contextTimeout)
+	defer cancel()
+
+	report, err := j.jobMatchingSvc.GetMatchPrecisionReport(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get match precision report: %w", err)
+	}
+
+	return report, nil
+}
+
+// AnalyzeJobGap compares one of the user's parsed CVs against a job
+func (j *jobUsecase) AnalyzeJobGap(ctx context.Context, userID, jobID, cvID string, withSuggestions bool) (*domain.JobGapAnalysis, error) {
+	ctx, cancel := context.WithTimeout(ctx, j.contextTimeout)
+	defer cancel()
+
+	if userID == "" || jobID == "" || cvID == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	cv, err := getOwnedCompletedCV(j.cvRepo, userID, cvID)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := j.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	analysis := j.jobMatchingSvc.AnalyzeGap(*job, *cv)
+
+	if withSuggestions {
+		if j.aiService == nil {
+			analysis.SuggestionsError = "ai_unavailable"
+		} else if suggestions, err := j.aiService.SuggestForJob(domain.WithAIUser(ctx, userID), cv, job, analysis.MissingSkills); err != nil {
+			// Suggestions are best-effort; the analysis itself is still useful
+			log.Printf("AI gap suggestions failed for job %s, cv %s: %v", jobID, cvID, err)
+			analysis.SuggestionsError = "ai_unavailable"
+			if errors.Is(err, domain.ErrAIQuotaExceeded) {
+				analysis.SuggestionsError = "ai_quota_exceeded"
+			}
+		} else {
+			analysis.Suggestions = suggestions
+		}
+	}
+
+	return &analysis, nil
+}
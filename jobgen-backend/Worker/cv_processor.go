@@ -1,114 +1,349 @@
 package Worker
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	domain "jobgen-backend/Domain"
 	infrastructure "jobgen-backend/Infrastructure"
 	usecases "jobgen-backend/Usecases"
 	"log"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// CVProcessorOptions configures the worker pool.
+type CVProcessorOptions struct {
+	Concurrency int           // jobs processed in parallel
+	JobTimeout  time.Duration // limit for a single attempt, AI calls included
+	// LLMExtraction reconciles the heuristic parse with structured output from the AI service
+	LLMExtraction bool
+}
+
 type CVProcessor struct {
 	queue     infrastructure.QueueService
 	repo      domain.CVRepository
 	parser    infrastructure.CVParserService
 	fileStore infrastructure.FileStorageService
-	aiService domain.AIService
+	aiService domain.IAIService
+	notifier  domain.CVJobNotifier // optional
+	opts      CVProcessorOptions
+
+	// abortCtx is cancelled when shutdown gives up waiting; in-flight jobs then stop and are requeued
+	abortCtx context.Context
+	abort    context.CancelFunc
+	done     chan struct{}
+
+	running                                            atomic.Bool
+	startedAt                                          time.Time
+	inFlight, succeeded, failed, retried, deadLettered atomic.Int64
 }
 
-func NewCVProcessor(q infrastructure.QueueService, r domain.CVRepository, p infrastructure.CVParserService, fs infrastructure.FileStorageService, ai domain.AIService) *CVProcessor {
+func NewCVProcessor(q infrastructure.QueueService, r domain.CVRepository, p infrastructure.CVParserService, fs infrastructure.FileStorageService, ai domain.IAIService, notifier domain.CVJobNotifier, opts CVProcessorOptions) *CVProcessor {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.JobTimeout <= 0 {
+		opts.JobTimeout = 3 * time.Minute
+	}
+	abortCtx, abort := context.WithCancel(context.Background())
 	return &CVProcessor{
 		queue:     q,
 		repo:      r,
 		parser:    p,
 		fileStore: fs,
 		aiService: ai,
+		notifier:  notifier,
+		opts:      opts,
+		abortCtx:  abortCtx,
+		abort:     abort,
+		done:      make(chan struct{}),
+		startedAt: time.Now().UTC(),
+	}
+}
+
+// Run starts the worker pool and blocks until ctx is cancelled and every in-flight job has
+// been settled. Cancelling ctx only stops taking new jobs; see Shutdown.
+func (w *CVProcessor) Run(ctx context.Context) {
+	defer close(w.done)
+	w.running.Store(true)
+	defer w.running.Store(false)
+	log.Printf("✅ CV Processing Worker started with %d workers and waiting for jobs...", w.opts.Concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.work(ctx)
+		}()
 	}
+	wg.Wait()
+	log.Println("✅ CV Processing Worker stopped")
 }
 
-// Start runs the worker loop. This should be run in a separate goroutine.
-func (w *CVProcessor) Start() {
-	log.Println("✅ CV Processing Worker started and waiting for jobs...")
+// Stats reports the pool's state and its job counters since the processor was created.
+func (w *CVProcessor) Stats() domain.CVWorkerStats {
+	return domain.CVWorkerStats{
+		Running:      w.running.Load(),
+		Concurrency:  w.opts.Concurrency,
+		InFlight:     w.inFlight.Load(),
+		Succeeded:    w.succeeded.Load(),
+		Failed:       w.failed.Load(),
+		Retried:      w.retried.Load(),
+		DeadLettered: w.deadLettered.Load(),
+		StartedAt:    w.startedAt,
+	}
+}
+
+// Shutdown waits for Run to return after its context was cancelled. If ctx expires first,
+// in-flight jobs are interrupted and handed back to the queue.
+func (w *CVProcessor) Shutdown(ctx context.Context) error {
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		log.Println("🟠 CV jobs still running at shutdown deadline, requeuing them")
+		w.abort()
+		<-w.done
+		return ctx.Err()
+	}
+}
+
+func (w *CVProcessor) work(ctx context.Context) {
 	for {
-		jobID, err := w.queue.Dequeue()
+		delivery, err := w.queue.Dequeue(ctx)
 		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
 			log.Printf("🔴 Error dequeuing job: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		if delivery.Exhausted() {
+			w.handleExhausted(delivery)
 			continue
 		}
-		log.Printf("🔵 Processing job ID: %s", jobID)
-		w.processJob(jobID)
+		log.Printf("🔵 Processing job ID: %s (attempt %d/%d)", delivery.JobID, delivery.Attempt, delivery.MaxAttempts)
+		w.handle(delivery)
+	}
+}
+
+// RequeueUnfinished enqueues CVs that are still pending or were being processed, for queues
+// that do not survive a restart.
+func (w *CVProcessor) RequeueUnfinished() {
+	cvs, err := w.repo.ListByStatus(domain.StatusPending, domain.StatusProcessing)
+	if err != nil {
+		log.Printf("🔴 Error listing unfinished CV jobs: %v", err)
+		return
+	}
+	for _, cv := range cvs {
+		if err := w.queue.Enqueue(cv.ID); err != nil {
+			log.Printf("🔴 Error requeuing job %s: %v", cv.ID, err)
+		}
+	}
+	if len(cvs) > 0 {
+		log.Printf("🔵 Requeued %d unfinished CV jobs", len(cvs))
+	}
+}
+
+// transientError marks a failure worth retrying, such as storage or AI outages.
+type transientError struct{ err error }
+
+func (e transientError) Error() string { return e.err.Error() }
+func (e transientError) Unwrap() error { return e.err }
+
+// handle processes one delivery and settles it with the queue: successes and permanent
+// failures are acked, transient failures are retried with backoff until the attempts run
+// out, after which the job is dead-lettered.
+func (w *CVProcessor) handle(d *infrastructure.QueueDelivery) {
+	// Jobs are not tied to the pool's context so a shutdown lets them finish
+	ctx, cancel := context.WithTimeout(w.abortCtx, w.opts.JobTimeout)
+	defer cancel()
+	w.inFlight.Add(1)
+	defer w.inFlight.Add(-1)
+
+	err := w.processJob(ctx, d)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = transientError{fmt.Errorf("job timed out after %s: %w", w.opts.JobTimeout, err)}
+	}
+	var transient transientError
+	switch {
+	case err != nil && w.abortCtx.Err() != nil:
+		log.Printf("🟠 Job %s interrupted by shutdown, requeuing", d.JobID)
+		w.setStatus(d.JobID, domain.StatusPending, "interrupted by shutdown")
+		w.retried.Add(1)
+		if retryErr := w.queue.Retry(d); retryErr != nil {
+			log.Printf("🔴 Error requeuing job %s: %v", d.JobID, retryErr)
+		}
+	case err == nil:
+		w.succeeded.Add(1)
+		if ackErr := w.queue.Ack(d); ackErr != nil {
+			log.Printf("🔴 Error acking job %s: %v", d.JobID, ackErr)
+		}
+	case errors.As(err, &transient) && !d.LastAttempt():
+		log.Printf("🟠 Job %s failed on attempt %d/%d, will retry: %v", d.JobID, d.Attempt, d.MaxAttempts, err)
+		w.setStatus(d.JobID, domain.StatusPending, "retrying: "+err.Error())
+		w.retried.Add(1)
+		if retryErr := w.queue.Retry(d); retryErr != nil {
+			log.Printf("🔴 Error scheduling retry for job %s: %v", d.JobID, retryErr)
+		}
+	case errors.As(err, &transient):
+		log.Printf("🔴 Job %s failed after %d attempts, dead-lettering: %v", d.JobID, d.Attempt, err)
+		w.setStatus(d.JobID, domain.StatusFailed, err.Error())
+		w.deadLettered.Add(1)
+		if dlErr := w.queue.DeadLetter(d, err.Error()); dlErr != nil {
+			log.Printf("🔴 Error dead-lettering job %s: %v", d.JobID, dlErr)
+		}
+	default:
+		log.Printf("🔴 Job %s failed: %v", d.JobID, err)
+		w.setStatus(d.JobID, domain.StatusFailed, err.Error())
+		w.failed.Add(1)
+		if ackErr := w.queue.Ack(d); ackErr != nil {
+			log.Printf("🔴 Error acking job %s: %v", d.JobID, ackErr)
+		}
+	}
+}
+
+// handleExhausted fails and dead-letters a job whose lease expired on its final attempt
+// without it being settled, so its CV does not stay processing forever.
+func (w *CVProcessor) handleExhausted(d *infrastructure.QueueDelivery) {
+	const reason = "lease expired on the final attempt"
+	log.Printf("🔴 Job %s %s, dead-lettering", d.JobID, reason)
+	w.setStatus(d.JobID, domain.StatusFailed, reason)
+	w.deadLettered.Add(1)
+	if dlErr := w.queue.DeadLetter(d, reason); dlErr != nil {
+		log.Printf("🔴 Error dead-lettering job %s: %v", d.JobID, dlErr)
 	}
 }
 
-func (w *CVProcessor) processJob(jobID string) {
-	w.repo.UpdateStatus(jobID, domain.StatusProcessing)
+// processJob returns a transientError for failures that may succeed on a later attempt.
+func (w *CVProcessor) processJob(ctx context.Context, d *infrastructure.QueueDelivery) error {
+	jobID := d.JobID
+	w.setStatus(jobID, domain.StatusProcessing, "")
 
 	cv, err := w.repo.GetByID(jobID)
+	if err == domain.ErrNotFound {
+		return fmt.Errorf("fetching CV: %w", err)
+	}
 	if err != nil {
-		log.Printf("🔴 Error fetching CV for job %s: %v", jobID, err)
-		w.repo.UpdateStatus(jobID, domain.StatusFailed, err.Error())
-		return
+		return transientError{fmt.Errorf("fetching CV: %w", err)}
 	}
+	// The AI calls for the CV count towards its owner's quota
+	ctx = domain.WithAIUser(ctx, cv.UserID)
 
 	file, err := w.fileStore.GetFile(cv.FileStorageID)
 	if err != nil {
-		log.Printf("🔴 Error getting file from storage for job %s: %v", jobID, err)
-		w.repo.UpdateStatus(jobID, domain.StatusFailed, err.Error())
-		return
+		return transientError{fmt.Errorf("getting file from storage: %w", err)}
 	}
 	defer file.Close()
 
-	rawText, err := w.parser.ExtractText(file)
+	doc, err := w.parser.ExtractDocument(file)
 	if err != nil {
-		log.Printf("🔴 Error parsing PDF for job %s: %v", jobID, err)
-		w.repo.UpdateStatus(jobID, domain.StatusFailed, err.Error())
-		return
+		return fmt.Errorf("extracting text: %w", err)
 	}
+	rawText := doc.PlainText()
+	w.stageCompleted(jobID, domain.StageTextExtracted, "")
 
-	parsedResults, err := usecases.ParseTextToCVSections(rawText)
+	parsedResults, err := usecases.ParseDocumentToCVSections(doc)
 	if err != nil {
-		log.Printf("🔴 Error structuring text for job %s: %v", jobID, err)
-		w.repo.UpdateStatus(jobID, domain.StatusFailed, err.Error())
-		return
+		return fmt.Errorf("structuring text: %w", err)
 	}
 	parsedResults.RawText = rawText
+	w.applyLLMExtraction(ctx, jobID, parsedResults)
+	w.stageCompleted(jobID, domain.StageSectionsParsed, "")
 
-	// Heuristic low-confidence: if no sections detected, flag for manual review
-	if len(parsedResults.Skills) == 0 && len(parsedResults.Experiences) == 0 && len(parsedResults.Educations) == 0 {
-		parsedResults.ProcessingError = "low_confidence_parse"
+	// Low-confidence fields are left for the user to confirm or correct
+	parsedResults.ReviewFields = parsedResults.LowConfidenceFields()
+	finalStatus := domain.StatusCompleted
+	if len(parsedResults.ReviewFields) > 0 {
+		finalStatus = domain.StatusNeedsReview
 	}
 
-	// Try to get AI suggestions; if it fails (e.g., invalid/absent API key), continue without failing the job
-	suggestions, aiErr := w.aiService.AnalyzeCV(rawText)
+	// Try to get AI suggestions. Outages are retried; if the AI stays unavailable (or is not
+	// configured) the job completes without suggestions.
+	suggestions, aiErr := w.aiService.SuggestCVImprovements(ctx, rawText)
 	if aiErr != nil {
+		if ctx.Err() != nil {
+			return transientError{fmt.Errorf("ai analysis: %w", aiErr)}
+		}
+		if infrastructure.IsTransientLLMError(aiErr) && !d.LastAttempt() {
+			return transientError{fmt.Errorf("ai analysis: %w", aiErr)}
+		}
 		log.Printf("🟠 AI unavailable for job %s: %v", jobID, aiErr)
+		aiCode := "ai_unavailable"
+		if errors.Is(aiErr, domain.ErrAIQuotaExceeded) {
+			aiCode = "ai_quota_exceeded"
+		}
 		if parsedResults.ProcessingError != "" {
-			parsedResults.ProcessingError = fmt.Sprintf("%s; ai_unavailable", parsedResults.ProcessingError)
+			parsedResults.ProcessingError = fmt.Sprintf("%s; %s", parsedResults.ProcessingError, aiCode)
 		} else {
-			parsedResults.ProcessingError = "ai_unavailable"
+			parsedResults.ProcessingError = aiCode
 		}
-		parsedResults.Suggestions = nil
-		parsedResults.Score = usecases.CalculateScore(parsedResults.Suggestions)
+		suggestions = nil
+	}
 
-		if err := w.repo.UpdateWithResults(jobID, parsedResults); err != nil {
-			log.Printf("🔴 Error saving results (no AI) for job %s: %v", jobID, err)
-			w.repo.UpdateStatus(jobID, domain.StatusFailed, err.Error())
-			return
-		}
-		w.repo.UpdateStatus(jobID, domain.StatusCompleted)
-		log.Printf("✅ Processed job %s without AI suggestions", jobID)
-		return
+	if aiErr != nil {
+		w.stageCompleted(jobID, domain.StageAISuggestionsDone, "ai_unavailable")
+	} else {
+		w.stageCompleted(jobID, domain.StageAISuggestionsDone, "")
 	}
 
-	// Ensure suggestions are of the correct type
 	parsedResults.Suggestions = suggestions
 	parsedResults.Score = usecases.CalculateScore(parsedResults.Suggestions)
 
 	if err := w.repo.UpdateWithResults(jobID, parsedResults); err != nil {
-		log.Printf("🔴 Error saving final results for job %s: %v", jobID, err)
-		w.repo.UpdateStatus(jobID, domain.StatusFailed, err.Error())
+		return transientError{fmt.Errorf("saving results: %w", err)}
+	}
+	// The message names any extraction path that degraded, as stored with the results
+	w.setStatus(jobID, finalStatus, parsedResults.ProcessingError)
+	if aiErr != nil {
+		log.Printf("✅ Processed job %s without AI suggestions", jobID)
+	} else {
+		log.Printf("✅ Successfully processed job ID: %s", jobID)
+	}
+	return nil
+}
+
+// setStatus stores a job's status and tells the notifier about the transition.
+func (w *CVProcessor) setStatus(jobID string, status domain.JobStatus, message string) {
+	if err := w.repo.UpdateStatus(jobID, status, message); err != nil {
+		log.Printf("🔴 Error updating status of job %s to %s: %v", jobID, status, err)
+	}
+	if w.notifier != nil {
+		w.notifier.StatusChanged(jobID, status, message)
+	}
+}
+
+func (w *CVProcessor) stageCompleted(jobID string, stage domain.CVJobStage, message string) {
+	if w.notifier != nil {
+		w.notifier.StageCompleted(jobID, stage, message)
+	}
+}
+
+// applyLLMExtraction records the extraction path of each core field. In LLM mode the model's
+// structured output replaces heuristic sections it can be trusted for; when the model is
+// unavailable or its output is rejected the heuristic parse is kept.
+func (w *CVProcessor) applyLLMExtraction(ctx context.Context, jobID string, cv *domain.CV) {
+	if !w.opts.LLMExtraction {
+		usecases.MarkHeuristicSources(cv)
 		return
 	}
-	w.repo.UpdateStatus(jobID, domain.StatusCompleted)
-	log.Printf("✅ Successfully processed job ID: %s", jobID)
+
+	response, err := w.aiService.ExtractCVFields(ctx, cv.RawText)
+	if err != nil {
+		log.Printf("🟠 LLM extraction unavailable for job %s: %v", jobID, err)
+		usecases.MarkHeuristicSources(cv)
+		cv.ProcessingError = "llm_extraction_unavailable"
+		return
+	}
+	for _, problem := range usecases.ReconcileLLMExtraction(cv, cv.RawText, response) {
+		log.Printf("🟠 LLM extraction for job %s rejected %v", jobID, problem)
+	}
 }
@@ -0,0 +1,91 @@
+// Command cv-eval runs the CV parser over an annotated corpus and reports precision and
+// recall per field, so heuristic changes can be compared before and after.
+//
+//	go run ./cmd/cv-eval -dir testdata/cv-corpus
+//
+// Each sample (PDF, DOCX, ODT, RTF, text or Markdown) is annotated by a file of the same
+// name with the extension replaced by ".golden.json".
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	infrastructure "jobgen-backend/Infrastructure"
+	usecases "jobgen-backend/Usecases"
+)
+
+func main() {
+	dir := flag.String("dir", "testdata/cv-corpus", "directory with CV samples and their .golden.json annotations")
+	asJSON := flag.Bool("json", false, "print the full report as JSON")
+	verbose := flag.Bool("v", false, "list missed and unexpected values per document")
+	minF1 := flag.Float64("min-f1", 0, "exit with status 1 if any field's F1 falls below this value")
+	flag.Parse()
+
+	report, err := usecases.EvaluateCVCorpus(*dir, infrastructure.NewCVParserService())
+	if err != nil {
+		log.Fatalf("cv-eval: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("cv-eval: %v", err)
+		}
+	} else {
+		printReport(report, *verbose)
+	}
+
+	if *minF1 > 0 {
+		for _, field := range report.FieldNames() {
+			if f1 := report.Fields[field].F1(); f1 < *minF1 {
+				fmt.Fprintf(os.Stderr, "cv-eval: %s F1 %.3f is below %.3f\n", field, f1, *minF1)
+				os.Exit(1)
+			}
+		}
+	}
+}
+
+func printReport(report *usecases.CVEvalReport, verbose bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "field\tprecision\trecall\tf1\ttp\tfp\tfn\t")
+	for _, field := range report.FieldNames() {
+		s := report.Fields[field]
+		fmt.Fprintf(w, "%s\t%.3f\t%.3f\t%.3f\t%d\t%d\t%d\t\n",
+			field, s.Precision(), s.Recall(), s.F1(), s.TruePositives, s.FalsePositives, s.FalseNegatives)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d documents, %d failed, total %s, mean %s, max %s\n",
+		len(report.Documents), report.Failed, round(report.TotalTime), round(report.MeanTime()), round(report.MaxTime))
+
+	if !verbose {
+		return
+	}
+	for _, doc := range report.Documents {
+		fmt.Printf("\n%s (%s, extract %s, parse %s)\n", doc.File, doc.Format, round(doc.ExtractTime), round(doc.ParseTime))
+		if doc.Error != "" {
+			fmt.Printf("  error: %s\n", doc.Error)
+		}
+		for _, field := range report.FieldNames() {
+			s := doc.Fields[field]
+			if len(s.Missed) > 0 {
+				fmt.Printf("  %s missed: %s\n", field, strings.Join(s.Missed, " | "))
+			}
+			if len(s.Unexpected) > 0 {
+				fmt.Printf("  %s unexpected: %s\n", field, strings.Join(s.Unexpected, " | "))
+			}
+		}
+	}
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	controllers "jobgen-backend/Delivery/Controllers"
 	router "jobgen-backend/Delivery/Router"
 	domain "jobgen-backend/Domain"
@@ -12,11 +15,15 @@ import (
 	worker "jobgen-backend/Worker"
 	_ "jobgen-backend/docs" // This line is important for swagger
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // @title JobGen API
@@ -40,13 +47,156 @@ import (
 // @description Type "Bearer" followed by a space and JWT token.
 
 func main() {
+	roleFlag := flag.String("role", "", "process role: api, worker or all (overrides PROCESS_ROLE)")
+	flag.Parse()
+
 	// Load environment variables
 	infrastructure.LoadEnv()
+	roleName := infrastructure.Env.ProcessRole
+	if *roleFlag != "" {
+		roleName = *roleFlag
+	}
+	role, err := infrastructure.ParseProcessRole(roleName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	runAPI, runWorker := role.API, role.Worker
+	log.Printf("Starting JobGen with role %q", role.Name)
 
 	// Initialize database
 	mongoClient := repositories.NewMongoClient()
 	db := repositories.GetDatabase(mongoClient)
 
+	cvRepo, err := repositories.NewCVRepository(db)
+	if err != nil {
+		log.Fatalf("Could not create CV Repository: %v", err)
+	}
+	rdb := connectRedis()
+	durableQueue := rdb != nil
+	var queueService infrastructure.QueueService
+	var cvEvents infrastructure.CVEventBus
+	// A job's lease has to outlast CV_JOB_TIMEOUT or a running job is handed to another worker
+	queueOptions := infrastructure.QueueOptionsForJobTimeout(infrastructure.Env.CVJobTimeout)
+	if durableQueue {
+		queueService = infrastructure.NewQueueService(rdb, "cv_processing_queue", queueOptions)
+		cvEvents = infrastructure.NewCVEventBus(rdb, "cv_job_events")
+	} else {
+		queueService = infrastructure.NewInMemoryQueueService(200, queueOptions)
+		cvEvents = infrastructure.NewInMemoryCVEventBus()
+	}
+	cvWebhookRepo := repositories.NewCVWebhookRepository(db)
+	cvStorage, cvDomainStorage, sharedStorage := newCVFileStorage()
+	if err := role.CheckSharedServices(durableQueue, sharedStorage); err != nil {
+		log.Fatal(err)
+	}
+
+	// Without a configured provider AI features answer "unavailable" but everything else works
+	llmProvider, err := infrastructure.NewLLMProvider()
+	if err != nil {
+		log.Fatalf("Invalid LLM configuration: %v", err)
+	}
+	log.Printf("LLM provider: %s", llmProvider.Name())
+	llmCache, err := newLLMCache(rdb)
+	if err != nil {
+		log.Fatalf("Invalid LLM cache configuration: %v", err)
+	}
+	llmCacheTTLs, err := infrastructure.ParseLLMCacheTTLs(infrastructure.Env.LLMCacheTTLs)
+	if err != nil {
+		log.Fatalf("Invalid LLM_CACHE_TTLS: %v", err)
+	}
+	aiUsage, err := newAIUsageMeter(db)
+	if err != nil {
+		log.Fatalf("Invalid AI quota configuration: %v", err)
+	}
+	// One client for every AI call in this process, so the rate limit, cache and usage counts are shared
+	llmClient := infrastructure.NewLLMClient(llmProvider, infrastructure.LLMClientOptions{
+		RequestsPerMinute: infrastructure.Env.LLMRPM,
+		Timeout:           infrastructure.Env.LLMTimeout,
+		MaxRetries:        infrastructure.Env.LLMMaxRetries,
+		Cache:             llmCache,
+		CacheTTLs:         llmCacheTTLs,
+		Meter:             aiUsage,
+	})
+	aiService := infrastructure.NewAIService(llmClient)
+
+	// SIGINT/SIGTERM stop the worker pool from taking new jobs and start the shutdown below
+	shutdownSignal, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// --- Start Background Worker ---
+	var cvProcessor *worker.CVProcessor
+	var cvNotifier *usecases.CVJobNotifier
+	if runWorker {
+		cvNotifier = usecases.NewCVJobNotifier(cvEvents, cvRepo, cvWebhookRepo, infrastructure.NewWebhookSender(10*time.Second))
+		cvProcessor = worker.NewCVProcessor(queueService, cvRepo, infrastructure.NewCVParserService(), cvStorage, aiService, cvNotifier, worker.CVProcessorOptions{
+			Concurrency:   infrastructure.Env.CVWorkerConcurrency,
+			JobTimeout:    infrastructure.Env.CVJobTimeout,
+			LLMExtraction: infrastructure.Env.CVExtractionMode == "llm",
+		})
+		if !durableQueue {
+			// The in-memory queue starts empty, so pick up jobs a previous run left unfinished
+			cvProcessor.RequeueUnfinished()
+		}
+		go cvProcessor.Run(shutdownSignal) // Run the worker pool in the background
+	}
+
+	// Start server: the API, or only health and metrics for a worker process
+	var srv *http.Server
+	if runAPI {
+		var workerController *controllers.WorkerController
+		if cvProcessor != nil {
+			workerController = controllers.NewWorkerController(cvProcessor)
+		}
+		r := setupAPIRouter(db, cvRepo, queueService, cvDomainStorage, cvEvents, cvWebhookRepo, aiService, llmClient, aiUsage, workerController)
+
+		port := infrastructure.Env.Port
+		if port == "" {
+			port = "8080"
+		}
+		srv = &http.Server{Addr: ":" + port, Handler: r}
+
+		log.Printf("Starting JobGen API server on port %s", port)
+		log.Printf("Environment: %s", infrastructure.Env.Environment)
+		log.Printf("Swagger documentation available at: http://localhost:%s/swagger/index.html", port)
+		log.Printf("AI Chatbot endpoints available at: /api/v1/chat/*")
+	} else {
+		srv = &http.Server{
+			Addr:    ":" + infrastructure.Env.WorkerPort,
+			Handler: router.SetupWorkerRouter(controllers.NewWorkerController(cvProcessor)),
+		}
+		log.Printf("Worker health and metrics available on port %s at /health and /api/v1/worker/metrics", infrastructure.Env.WorkerPort)
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	<-shutdownSignal.Done()
+	// Requests and CV jobs drain side by side, each with the full timeout; webhooks the
+	// jobs sent while draining get their own timeout afterwards
+	log.Printf("Shutting down, waiting up to %s for requests and CV jobs to finish", infrastructure.Env.ShutdownTimeout)
+	stages := []infrastructure.ShutdownStage{{Name: "HTTP server", Stop: srv.Shutdown}}
+	if cvProcessor != nil {
+		stages = append(stages, infrastructure.ShutdownStage{Name: "CV worker", Stop: cvProcessor.Shutdown})
+	}
+	if err := infrastructure.DrainInParallel(infrastructure.Env.ShutdownTimeout, stages...); err != nil {
+		log.Printf("Shutdown: %v", err)
+	}
+	if cvNotifier != nil {
+		webhooks := infrastructure.ShutdownStage{Name: "webhook deliveries", Stop: cvNotifier.Wait}
+		if err := infrastructure.DrainInParallel(infrastructure.Env.ShutdownTimeout, webhooks); err != nil {
+			log.Printf("Shutdown: %v", err)
+		}
+	}
+	log.Println("Shutdown complete")
+}
+
+// setupAPIRouter wires the HTTP API. CV jobs are only enqueued here; the worker pool
+// processes them, in this process or another one. workerController is nil unless the pool
+// runs in this process, whose worker metrics are then only served to admins.
+func setupAPIRouter(db *mongo.Database, cvRepo domain.CVRepository, queueService infrastructure.QueueService, cvDomainStorage domain.FileStorageService, cvEvents infrastructure.CVEventBus, cvWebhookRepo domain.ICVWebhookRepository, aiService domain.IAIService, llmClient *infrastructure.LLMClient, aiUsage usecases.AIUsageUsecase, workerController *controllers.WorkerController) *gin.Engine {
 	// Initialize infrastructure services
 	jwtService := infrastructure.NewJWTService()
 	passwordService := infrastructure.NewPasswordService()
@@ -60,10 +210,11 @@ func main() {
 	passwordResetRepo := repositories.NewPasswordResetRepository(db)
 	contactRepo := repositories.NewContactRepository(db)
 	jobRepo := repositories.NewJobRepository(db)
+	jobFeedbackRepo := repositories.NewJobFeedbackRepository(db)
 
 	// Initialize job-related services
 	jobAggregationService := services.NewJobAggregationService(jobRepo)
-	jobMatchingService := services.NewJobMatchingService(jobRepo, userRepo)
+	jobMatchingService := services.NewJobMatchingService(jobRepo, userRepo, jobFeedbackRepo)
 
 	// Initialize use cases
 	contextTimeout := 30 * time.Second
@@ -88,23 +239,35 @@ func main() {
 		emailService,
 		contextTimeout,
 	)
+
+	cvParseLabelRepo := repositories.NewCVParseLabelRepository(db)
+
 	jobUsecase := usecases.NewJobUsecase(
 		jobRepo,
 		userRepo,
+		cvRepo,
 		jobAggregationService,
 		jobMatchingService,
+		aiService,
 		contextTimeout,
 	)
 
-	// Initialize AI Service for chatbot
-	aiService, err := infrastructure.NewAIService()
-	if err != nil {
-		log.Fatalf("Failed to initialize AI service: %v", err)
-	}
+	// Cover letters
+	coverLetterRepo := repositories.NewCoverLetterRepository(db)
+	documentExporter := infrastructure.NewDocumentExporter()
+	coverLetterUsecase := usecases.NewCoverLetterUsecase(
+		coverLetterRepo,
+		jobRepo,
+		cvRepo,
+		aiService,
+		documentExporter,
+		contextTimeout,
+	)
+	coverLetterController := controllers.NewCoverLetterController(coverLetterUsecase)
 
 	// Initialize Chat components
 	chatRepo := repositories.NewChatRepository(db)
-	chatUsecase := usecases.NewChatUsecase(chatRepo, aiService)
+	chatUsecase := usecases.NewChatUsecase(chatRepo, aiService, jobUsecase, cvRepo, documentExporter, infrastructure.Env.ChatHistoryTokens)
 	chatController := controllers.NewChatController(chatUsecase)
 
 	// Initialize controllers
@@ -130,59 +293,118 @@ func main() {
 	fileUsecase := usecases.NewFileUsecase(fileRepo, minioService)
 	fileController := controllers.NewFileController(fileUsecase)
 
-	// --- Initialize Repositories ---
-	cvRepo, err := repositories.NewCVRepository(db) // New CV Repo
-	if err != nil {
-		log.Fatalf("Could not create CV Repository: %v", err)
+	// --- Initialize Usecases ---
+	cvUsecase := usecases.NewCVUsecase(cvRepo, queueService, cvDomainStorage, jobRepo, aiService, cvParseLabelRepo, cvEvents, cvWebhookRepo) // New CV Usecase
+
+	// --- Initialize Controllers ---
+	cvController := controllers.NewCVController(cvUsecase) // New CV Controller
+
+	// Setup router (match parameter order defined in router.SetupRouter)
+	r := router.SetupRouter(
+		userController,
+		authController,
+		jobController,
+		authMiddleware,
+		fileController,
+		cvController,
+		contactController,
+		chatController,
+		coverLetterController,
+		controllers.NewAIController(llmClient, aiUsage),
+	)
+
+	if workerController != nil {
+		router.RegisterWorkerRoutes(r, workerController, authMiddleware.RequireAdmin())
 	}
 
-	// --- Initialize Infrastructure & Services ---
-	cvParserService := infrastructure.NewCVParserService() // New CV Parser
+	// Health and root endpoints for platform readiness checks
+	r.GET("/", func(c *gin.Context) { c.String(200, "OK") })
+	r.GET("/api/v1/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"status": "ok",
+			"ai":     gin.H{"provider": llmClient.Name(), "available": llmClient.Available()},
+		})
+	})
+
+	return r
+}
 
-	// Initialize Queue service: use Redis only if configured; otherwise fallback to in-memory
-	var queueService infrastructure.QueueService
-	{
-		redisURL := os.Getenv("REDIS_URL")
-		redisAddr := os.Getenv("REDIS_ADDR")
-		if redisURL == "" && redisAddr == "" {
-			log.Printf("Redis not configured. Using in-memory queue.")
-			queueService = infrastructure.NewInMemoryQueueService(200)
-		} else {
-			var rdb *redis.Client
-			var err error
-			if redisURL != "" {
-				if opt, perr := redis.ParseURL(redisURL); perr == nil {
-					rdb = redis.NewClient(opt)
-					err = nil
-				} else {
-					err = perr
-				}
-			} else {
-				// REDIS_ADDR form host:port with optional REDIS_PASSWORD
-				rdb = redis.NewClient(&redis.Options{Addr: redisAddr, Password: os.Getenv("REDIS_PASSWORD")})
-			}
-
-			if err != nil {
-				log.Printf("Redis config error (%v). Falling back to in-memory queue.", err)
-				queueService = infrastructure.NewInMemoryQueueService(200)
-			} else {
-				ctx, cancel := ctxWithTimeout(2 * time.Second)
-				defer cancel()
-				if pingErr := rdb.Ping(ctx).Err(); pingErr != nil {
-					log.Printf("Redis not available (%v). Using in-memory queue.", pingErr)
-					queueService = infrastructure.NewInMemoryQueueService(200)
-				} else {
-					queueService = infrastructure.NewQueueService(rdb, "cv_processing_queue")
-					log.Printf("Redis connected. Using Redis-backed queue.")
-				}
-			}
+// connectRedis returns a client for the Redis server that carries CV jobs and their events,
+// or nil when Redis is not configured or not reachable. Without Redis, jobs and events stay
+// in memory, which only works when the API and the worker share a process.
+func connectRedis() *redis.Client {
+	redisURL := os.Getenv("REDIS_URL")
+	redisAddr := os.Getenv("REDIS_ADDR")
+	if redisURL == "" && redisAddr == "" {
+		log.Printf("Redis not configured. Using in-memory queue.")
+		return nil
+	}
+
+	var rdb *redis.Client
+	if redisURL != "" {
+		opt, err := redis.ParseURL(redisURL)
+		if err != nil {
+			log.Printf("Redis config error (%v). Falling back to in-memory queue.", err)
+			return nil
+		}
+		rdb = redis.NewClient(opt)
+	} else {
+		// REDIS_ADDR form host:port with optional REDIS_PASSWORD
+		rdb = redis.NewClient(&redis.Options{Addr: redisAddr, Password: os.Getenv("REDIS_PASSWORD")})
+	}
+
+	ctx, cancel := ctxWithTimeout(2 * time.Second)
+	defer cancel()
+	if pingErr := rdb.Ping(ctx).Err(); pingErr != nil {
+		log.Printf("Redis not available (%v). Using in-memory queue.", pingErr)
+		rdb.Close()
+		return nil
+	}
+	log.Printf("Redis connected. Using Redis-backed queue.")
+	return rdb
+}
+
+// newLLMCache picks the AI response cache: Redis shares it between processes, the in-memory
+// LRU serves a single process.
+func newLLMCache(rdb *redis.Client) (infrastructure.LLMCache, error) {
+	switch infrastructure.Env.LLMCache {
+	case "none":
+		return nil, nil
+	case "memory":
+		return infrastructure.NewInMemoryLLMCache(infrastructure.Env.LLMCacheSize), nil
+	case "redis":
+		if rdb == nil {
+			return nil, fmt.Errorf("LLM_CACHE=redis needs Redis; set REDIS_URL or REDIS_ADDR")
+		}
+		return infrastructure.NewLLMCache(rdb, "llm_cache"), nil
+	case "":
+		if rdb != nil {
+			return infrastructure.NewLLMCache(rdb, "llm_cache"), nil
 		}
+		return infrastructure.NewInMemoryLLMCache(infrastructure.Env.LLMCacheSize), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM_CACHE %q (want memory, redis or none)", infrastructure.Env.LLMCache)
 	}
-	aiServiceClient := infrastructure.NewAIServiceClient() // Gemini AI Client
+}
 
-	// CV storage: prefer MinIO when configured; fallback to local disk for dev
-	var cvStorage infrastructure.FileStorageService
-	var cvDomainStorage domain.FileStorageService
+// newAIUsageMeter sets up per-user AI accounting with the quotas configured for each role.
+func newAIUsageMeter(db *mongo.Database) (usecases.AIUsageUsecase, error) {
+	userQuota, err := infrastructure.ParseAIQuota(infrastructure.Env.AIQuotaUser)
+	if err != nil {
+		return nil, fmt.Errorf("AI_QUOTA_USER: %w", err)
+	}
+	adminQuota, err := infrastructure.ParseAIQuota(infrastructure.Env.AIQuotaAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("AI_QUOTA_ADMIN: %w", err)
+	}
+	quotas := map[domain.Role]domain.AIQuota{domain.RoleUser: userQuota, domain.RoleAdmin: adminQuota}
+	pricing := domain.AIPricing{PromptPer1K: infrastructure.Env.AIPricePrompt, CompletionPer1K: infrastructure.Env.AIPriceCompletion}
+	return usecases.NewAIUsageUsecase(repositories.NewAIUsageRepository(db), repositories.NewUserRepository(db), quotas, pricing), nil
+}
+
+// newCVFileStorage prefers MinIO when configured and falls back to local disk for dev.
+// shared reports whether MinIO is in use, so other processes can read the files.
+func newCVFileStorage() (cvStorage infrastructure.FileStorageService, cvDomainStorage domain.FileStorageService, shared bool) {
 	if infrastructure.Env.FileStorageURL != "" && infrastructure.Env.AccessKey != "" && infrastructure.Env.SecretKey != "" {
 		// Use the same bucket as document uploads by default
 		bucket := "documents"
@@ -200,6 +422,7 @@ func main() {
 		} else {
 			cvStorage = mstore
 			cvDomainStorage = mstore
+			shared = true
 		}
 	} else {
 		local := infrastructure.NewLocalCVFileStorageService("./data/cv")
@@ -207,46 +430,7 @@ func main() {
 		cvDomainStorage = local
 	}
 
-	// --- Initialize Usecases ---
-	cvUsecase := usecases.NewCVUsecase(cvRepo, queueService, cvDomainStorage) // New CV Usecase
-
-	// --- Initialize Controllers ---
-	cvController := controllers.NewCVController(cvUsecase) // New CV Controller
-
-	// --- Start Background Worker ---
-	cvProcessor := worker.NewCVProcessor(queueService, cvRepo, cvParserService, cvStorage, aiServiceClient)
-	go cvProcessor.Start() // Run the worker in a separate goroutine
-
-	// Setup router (match parameter order defined in router.SetupRouter)
-	r := router.SetupRouter(
-		userController,
-		authController,
-		jobController,
-		authMiddleware,
-		fileController,
-		cvController,
-		contactController,
-		chatController,
-	)
-
-	// Health and root endpoints for platform readiness checks
-	r.GET("/", func(c *gin.Context) { c.String(200, "OK") })
-	r.GET("/api/v1/health", func(c *gin.Context) { c.JSON(200, gin.H{"status": "ok"}) })
-
-	// Start server
-	port := infrastructure.Env.Port
-	if port == "" {
-		port = "8080"
-	}
-
-	log.Printf("Starting JobGen API server on port %s", port)
-	log.Printf("Environment: %s", infrastructure.Env.Environment)
-	log.Printf("Swagger documentation available at: http://localhost:%s/swagger/index.html", port)
-	log.Printf("AI Chatbot endpoints available at: /api/v1/chat/*")
-
-	if err := r.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
-	}
+	return cvStorage, cvDomainStorage, shared
 }
 
 // Additional model definitions for Swagger
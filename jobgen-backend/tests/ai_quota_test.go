@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAIUsageRepository keeps usage records in a map keyed like the Mongo collection.
+type memoryAIUsageRepository struct {
+	mu      sync.Mutex
+	records map[string]domain.AIUsageRecord
+}
+
+func (r *memoryAIUsageRepository) Add(_ context.Context, record domain.AIUsageRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := record.UserID + "|" + record.Day + "|" + record.Operation
+	existing := r.records[key]
+	existing.UserID, existing.Day, existing.Operation = record.UserID, record.Day, record.Operation
+	existing.Usage.Add(record.Usage)
+	existing.CostUSD += record.CostUSD
+	r.records[key] = existing
+	return nil
+}
+
+func (r *memoryAIUsageRepository) List(_ context.Context, filter domain.AIUsageFilter) ([]domain.AIUsageRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.AIUsageRecord
+	for _, rec := range r.records {
+		if (filter.UserID == "" || rec.UserID == filter.UserID) &&
+			(filter.FromDay == "" || rec.Day >= filter.FromDay) &&
+			(filter.ToDay == "" || rec.Day <= filter.ToDay) {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+func TestLLMClientEnforcesPerUserQuota(t *testing.T) {
+	repo := &memoryAIUsageRepository{records: map[string]domain.AIUsageRecord{}}
+	meter := usecases.NewAIUsageUsecase(repo, nil,
+		map[domain.Role]domain.AIQuota{domain.RoleUser: {DailyRequests: 2}},
+		domain.AIPricing{PromptPer1K: 1, CompletionPer1K: 2})
+	provider := infrastructure.NewScriptedProvider(infrastructure.ScriptedRule{Match: "", Reply: "two words"})
+	client := infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{
+		Cache:     infrastructure.NewInMemoryLLMCache(10),
+		CacheTTLs: map[string]time.Duration{"job_search": time.Hour},
+		Meter:     meter,
+	})
+	userCtx := domain.WithAIUser(context.Background(), "user-1")
+	ask := func(ctx context.Context, operation string) error {
+		_, err := client.Generate(ctx, operation, infrastructure.LLMRequest{Messages: []infrastructure.LLMMessage{{Role: "user", Content: "remote go jobs"}}})
+		return err
+	}
+
+	require.NoError(t, ask(userCtx, "job_search"))
+	require.NoError(t, ask(userCtx, "chat"))
+	// Cached answers cost nothing and are served over quota
+	require.NoError(t, ask(userCtx, "job_search"))
+
+	err := ask(userCtx, "chat")
+	require.ErrorIs(t, err, domain.ErrAIQuotaExceeded)
+	require.False(t, infrastructure.IsTransientLLMError(err))
+	var quotaErr *domain.AIQuotaError
+	require.ErrorAs(t, err, &quotaErr)
+	require.Equal(t, "daily", quotaErr.Period)
+	require.Equal(t, "requests", quotaErr.Unit)
+	require.Equal(t, int64(2), quotaErr.Used)
+	require.True(t, quotaErr.ResetAt.After(time.Now()))
+	require.Len(t, provider.Calls(), 2)
+
+	// Other users and calls not made for a user are not limited
+	require.NoError(t, ask(domain.WithAIUser(context.Background(), "user-2"), "chat"))
+	require.NoError(t, ask(context.Background(), "chat"))
+
+	report, err := meter.Report(context.Background(), domain.AIUsageFilter{})
+	require.NoError(t, err)
+	require.Len(t, report.Users, 2)
+	require.Equal(t, "user-1", report.Users[0].UserID)
+	require.Equal(t, domain.AIUsage{Requests: 1, CacheHits: 1, PromptTokens: 3, CompletionTokens: 2}, report.Users[0].Operations["job_search"])
+	require.Equal(t, int64(2), report.Users[0].Total.Requests)
+	require.InDelta(t, 2*(0.003+0.004), report.Users[0].CostUSD, 1e-9)
+	require.Equal(t, int64(3), report.Total.Requests)
+
+	_, err = meter.Report(context.Background(), domain.AIUsageFilter{FromDay: "2024-02-01", ToDay: "2024-01-01"})
+	require.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func TestParseAIQuota(t *testing.T) {
+	quota, err := infrastructure.ParseAIQuota("daily_requests=200, monthly_tokens=5000000")
+	require.NoError(t, err)
+	require.Equal(t, domain.AIQuota{DailyRequests: 200, MonthlyTokens: 5000000}, quota)
+
+	quota, err = infrastructure.ParseAIQuota("")
+	require.NoError(t, err)
+	require.Equal(t, domain.AIQuota{}, quota)
+
+	_, err = infrastructure.ParseAIQuota("hourly_requests=5")
+	require.Error(t, err)
+	_, err = infrastructure.ParseAIQuota("daily_tokens=-1")
+	require.Error(t, err)
+}
@@ -232,6 +232,125 @@ func (m *MockCVUsecase) GetJobStatusAndResult(jobID string) (*domain.CV, error)
 	return args.Get(0).(*domain.CV), args.Error(1)
 }
 
+func (m *MockCVUsecase) TailorForJob(ctx context.Context, userID, cvID, jobID string) (*domain.CV, error) {
+	args := m.Called(ctx, userID, cvID, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CV), args.Error(1)
+}
+
+func (m *MockCVUsecase) ListUserCVs(userID string) ([]domain.CV, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CV), args.Error(1)
+}
+
+func (m *MockCVUsecase) SetPrimaryCV(userID, cvID string) error {
+	args := m.Called(userID, cvID)
+	return args.Error(0)
+}
+
+func (m *MockCVUsecase) UpdateSections(userID, cvID string, update domain.CVSectionsUpdate) (*domain.CV, error) {
+	args := m.Called(userID, cvID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CV), args.Error(1)
+}
+
+func (m *MockCVUsecase) GetVersionHistory(userID, cvID string) ([]domain.CVVersionSummary, error) {
+	args := m.Called(userID, cvID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CVVersionSummary), args.Error(1)
+}
+
+func (m *MockCVUsecase) RestoreVersion(userID, cvID string, version int) (*domain.CV, error) {
+	args := m.Called(userID, cvID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CV), args.Error(1)
+}
+
+func (m *MockCVUsecase) AcceptSuggestion(userID, cvID, suggestionID string) (*domain.CV, error) {
+	args := m.Called(userID, cvID, suggestionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CV), args.Error(1)
+}
+
+func (m *MockCVUsecase) RejectSuggestion(userID, cvID, suggestionID string) (*domain.CV, error) {
+	args := m.Called(userID, cvID, suggestionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CV), args.Error(1)
+}
+
+func (m *MockCVUsecase) GetReview(userID, cvID string) (*domain.CVReview, error) {
+	args := m.Called(userID, cvID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CVReview), args.Error(1)
+}
+
+func (m *MockCVUsecase) SubmitReview(ctx context.Context, userID, cvID string, corrections []domain.CVFieldCorrection) (*domain.CV, error) {
+	args := m.Called(ctx, userID, cvID, corrections)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CV), args.Error(1)
+}
+
+func (m *MockCVUsecase) ListDeadLetteredJobs() ([]domain.DeadLetteredJob, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.DeadLetteredJob), args.Error(1)
+}
+
+func (m *MockCVUsecase) RequeueDeadLetteredJob(jobID string) error {
+	args := m.Called(jobID)
+	return args.Error(0)
+}
+
+func (m *MockCVUsecase) SubscribeJobEvents(ctx context.Context, userID, jobID string) (*domain.CV, <-chan domain.CVJobEvent, error) {
+	args := m.Called(ctx, userID, jobID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*domain.CV), args.Get(1).(<-chan domain.CVJobEvent), args.Error(2)
+}
+
+func (m *MockCVUsecase) RegisterWebhook(ctx context.Context, userID, url string) (*domain.CVWebhook, error) {
+	args := m.Called(ctx, userID, url)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CVWebhook), args.Error(1)
+}
+
+func (m *MockCVUsecase) ListWebhooks(ctx context.Context, userID string) ([]domain.CVWebhook, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CVWebhook), args.Error(1)
+}
+
+func (m *MockCVUsecase) DeleteWebhook(ctx context.Context, userID, webhookID string) error {
+	args := m.Called(ctx, userID, webhookID)
+	return args.Error(0)
+}
+
 // Setup and teardown
 func (suite *APITestSuite) SetupTest() {
 	gin.SetMode(gin.TestMode)
@@ -252,6 +371,7 @@ func (suite *APITestSuite) SetupTest() {
 	jobController := controllers.NewJobController(nil)
 	contactController := controllers.NewContactController(nil)
 	chatController := controllers.NewChatController(nil)
+	coverLetterController := controllers.NewCoverLetterController(nil)
 
 	// Setup router
 	suite.router = router.SetupRouter(
@@ -263,6 +383,8 @@ func (suite *APITestSuite) SetupTest() {
 		suite.cvController,
 		contactController,
 		chatController,
+		coverLetterController,
+		controllers.NewAIController(nil, nil),
 	)
 
 }
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryJobRepository serves a fixed list of jobs; only reads are supported.
+type memoryJobRepository struct {
+	jobs []domain.Job
+}
+
+func (r *memoryJobRepository) GetByID(_ context.Context, id string) (*domain.Job, error) {
+	for _, job := range r.jobs {
+		if job.ID == id {
+			return &job, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *memoryJobRepository) List(_ context.Context, filter domain.JobFilter) ([]domain.Job, int64, error) {
+	var out []domain.Job
+	for _, job := range r.jobs {
+		if strings.Contains(strings.ToLower(job.Title), strings.ToLower(filter.Query)) {
+			out = append(out, job)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+func (r *memoryJobRepository) Create(context.Context, *domain.Job) error      { return nil }
+func (r *memoryJobRepository) Update(context.Context, *domain.Job) error      { return nil }
+func (r *memoryJobRepository) Delete(context.Context, string) error           { return nil }
+func (r *memoryJobRepository) BulkUpsert(context.Context, []domain.Job) error { return nil }
+func (r *memoryJobRepository) GetByApplyURL(context.Context, string) (*domain.Job, error) {
+	return nil, domain.ErrNotFound
+}
+func (r *memoryJobRepository) GetJobsForMatching(context.Context, int, int) ([]domain.Job, error) {
+	return nil, nil
+}
+
+func TestChatAgentCitesOnlyJobsFromTools(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "short title", Reply: "Go jobs"},
+		infrastructure.ScriptedRule{Match: "tool search_jobs returned", Reply: "Try Backend Go Engineer at Acme [job:job-1], or Go Lead at Nowhere [job:fake]."},
+		infrastructure.ScriptedRule{Match: "", Reply: `{"tool": "search_jobs", "arguments": {"query": "go", "limit": 3}}`},
+	)
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+	jobs := &memoryJobRepository{jobs: []domain.Job{
+		{ID: "job-1", Title: "Backend Go Engineer", CompanyName: "Acme", PostedAt: time.Now()},
+		{ID: "job-2", Title: "Frontend Engineer", CompanyName: "Globex", PostedAt: time.Now()},
+	}}
+	jobUsecase := usecases.NewJobUsecase(jobs, nil, nil, nil, nil, nil, time.Second)
+	repo := newMemoryChatRepository()
+	uc := usecases.NewChatUsecase(repo, ai, jobUsecase, nil, nil, 0)
+
+	resp, err := uc.SendMessage(context.Background(), &domain.ChatRequest{UserID: "user-1", Message: "Find me Go jobs"})
+	require.NoError(t, err)
+	require.Equal(t, "Try Backend Go Engineer at Acme [job:job-1], or Go Lead at Nowhere.", resp.Message)
+	require.Len(t, resp.Jobs, 1)
+	require.Equal(t, "job-1", resp.Jobs[0].ID)
+
+	saved := repo.messages[1]
+	require.Equal(t, []string{"job-1"}, saved.JobIDs)
+	require.Len(t, saved.ToolCalls, 1)
+	require.Equal(t, "search_jobs", saved.ToolCalls[0].Name)
+	require.Empty(t, saved.ToolCalls[0].Error)
+
+	// The model saw the search result as the tool's output
+	var toolResult string
+	for _, call := range provider.Calls() {
+		for _, msg := range call.Messages {
+			if strings.HasPrefix(msg.Content, "Tool search_jobs returned") {
+				toolResult = msg.Content
+			}
+		}
+	}
+	require.Contains(t, toolResult, `"id":"job-1"`)
+	require.NotContains(t, toolResult, "job-2")
+}
+
+func TestChatAgentStreamsResolvedCitations(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "short title", Reply: "Go jobs"},
+		infrastructure.ScriptedRule{Match: "tool search_jobs returned", Reply: "Try Backend Go Engineer at Acme [job: job-1 ], or Go Lead at Nowhere [job: fake ]. Lists look like [this]."},
+		infrastructure.ScriptedRule{Match: "", Reply: `{"tool": "search_jobs", "arguments": {"query": "go"}}`},
+	)
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+	jobs := &memoryJobRepository{jobs: []domain.Job{
+		{ID: "job-1", Title: "Backend Go Engineer", CompanyName: "Acme", PostedAt: time.Now()},
+	}}
+	uc := usecases.NewChatUsecase(newMemoryChatRepository(), ai, usecases.NewJobUsecase(jobs, nil, nil, nil, nil, nil, time.Second), nil, nil, 0)
+
+	var deltas []string
+	resp, err := uc.SendMessageStream(context.Background(), &domain.ChatRequest{UserID: "user-1", Message: "Find me Go jobs"},
+		func(delta string) error {
+			deltas = append(deltas, delta)
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, "Try Backend Go Engineer at Acme [job:job-1], or Go Lead at Nowhere. Lists look like [this].", resp.Message)
+	require.Equal(t, resp.Message, strings.Join(deltas, ""))
+	for _, delta := range deltas {
+		require.NotContains(t, delta, "fake")
+	}
+}
+
+func TestChatAgentShortensLargeToolResultsToValidJSON(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "tool list_notes returned", Reply: "Done."},
+		infrastructure.ScriptedRule{Match: "", Reply: `{"tool": "list_notes"}`},
+	)
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+	notes := make([]string, 40)
+	for i := range notes {
+		notes[i] = strings.Repeat("é", 400)
+	}
+	tool := domain.ChatTool{Name: "list_notes", Run: func(context.Context, json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{"notes": notes}, nil
+	}}
+
+	_, err := ai.RunChatAgent(context.Background(), "Show my notes", nil, []domain.ChatTool{tool}, nil)
+	require.NoError(t, err)
+
+	var result string
+	for _, call := range provider.Calls() {
+		for _, msg := range call.Messages {
+			if text, ok := strings.CutPrefix(msg.Content, "Tool list_notes returned:\n"); ok {
+				result = text
+			}
+		}
+	}
+	require.NotEmpty(t, result)
+	require.LessOrEqual(t, len(result), 6000)
+	require.True(t, utf8.ValidString(result))
+	var decoded struct {
+		Notes []string `json:"notes"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result), &decoded))
+	require.NotEmpty(t, decoded.Notes)
+	require.Less(t, len(decoded.Notes), len(notes))
+}
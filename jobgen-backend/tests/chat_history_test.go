@@ -0,0 +1,294 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	controllers "jobgen-backend/Delivery/Controllers"
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatSummarizesMessagesOutsideTheHistoryBudget(t *testing.T) {
+	reply := "Polish your CV, list your Go projects with numbers, and apply to remote backend roles every week."
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "short title", Reply: "Go careers"},
+		infrastructure.ScriptedRule{Match: "update the summary of a conversation", Reply: "User is a Go developer looking for remote work."},
+		infrastructure.ScriptedRule{Match: "", Reply: reply},
+	)
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+	repo := newMemoryChatRepository()
+	// Room for roughly one reply, so every older message is summarized
+	uc := usecases.NewChatUsecase(repo, ai, nil, nil, nil, 30)
+
+	send := func(sessionID, message string) *domain.ChatResponse {
+		resp, err := uc.SendMessage(context.Background(), &domain.ChatRequest{UserID: "user-1", SessionID: sessionID, Message: message})
+		require.NoError(t, err)
+		return resp
+	}
+	first := send("", "I am a Go developer. How do I find remote work?")
+	send(first.SessionID, "What should my CV say?")
+	send(first.SessionID, "And my cover letter?")
+
+	session := repo.sessions[first.SessionID]
+	require.Equal(t, "User is a Go developer looking for remote work.", session.Summary)
+	require.Equal(t, 3, session.SummarizedCount) // summarized before the third message was answered
+	require.Equal(t, 6, session.MessageCount)
+
+	var summaries, replies []infrastructure.LLMRequest
+	for _, call := range provider.Calls() {
+		prompt := call.Messages[len(call.Messages)-1].Content
+		switch {
+		case strings.Contains(prompt, "Update the summary of a conversation"):
+			summaries = append(summaries, call)
+		case !strings.Contains(prompt, "short title"):
+			replies = append(replies, call)
+		}
+	}
+	require.Len(t, summaries, 2)
+	require.Contains(t, summaries[0].Messages[0].Content, "I am a Go developer")
+	// The second summary builds on the first rather than starting over
+	require.Contains(t, summaries[1].Messages[0].Content, "Summary so far:\nUser is a Go developer looking for remote work.")
+	require.NotContains(t, summaries[1].Messages[0].Content, "I am a Go developer")
+
+	require.Len(t, replies, 3)
+	last := replies[2]
+	require.Contains(t, last.System, "Summary of the earlier conversation:\nUser is a Go developer looking for remote work.")
+	require.Len(t, last.Messages, 2) // the previous reply and the new message
+	require.Equal(t, reply, last.Messages[0].Content)
+
+	page, err := uc.GetSessionHistory(context.Background(), first.SessionID, "user-1", 4, 0)
+	require.NoError(t, err)
+	require.Len(t, page.Messages, 4)
+	require.Equal(t, "What should my CV say?", page.Messages[0].Content)
+	require.True(t, page.HasMore)
+	page, err = uc.GetSessionHistory(context.Background(), first.SessionID, "user-1", 4, 4)
+	require.NoError(t, err)
+	require.Len(t, page.Messages, 2)
+	require.Equal(t, "I am a Go developer. How do I find remote work?", page.Messages[0].Content)
+	require.False(t, page.HasMore)
+}
+
+func TestChatSummarizesABacklogOldestFirst(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "update the summary of a conversation", Reply: "The user asked many questions."},
+		infrastructure.ScriptedRule{Match: "", Reply: "Here is my answer."},
+	)
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+	repo := newMemoryChatRepository()
+	// Earlier summaries failed, leaving 60 unsummarized messages
+	repo.sessions["session-1"] = domain.ChatSession{ID: "session-1", UserID: "user-1", Title: "Backlog", MessageCount: 60}
+	for i := 1; i <= 60; i++ {
+		repo.messages = append(repo.messages, domain.ChatMessage{SessionID: "session-1", Role: "user", Content: fmt.Sprintf("message %02d", i)})
+	}
+	uc := usecases.NewChatUsecase(repo, ai, nil, nil, nil, 100000)
+
+	_, err := uc.SendMessage(context.Background(), &domain.ChatRequest{UserID: "user-1", SessionID: "session-1", Message: "What next?"})
+	require.NoError(t, err)
+
+	session := repo.sessions["session-1"]
+	require.Equal(t, 62, session.MessageCount)
+	require.Equal(t, 10, session.SummarizedCount) // only the messages the summary saw
+	require.Equal(t, "The user asked many questions.", session.Summary)
+
+	var summaries, replies []infrastructure.LLMRequest
+	for _, call := range provider.Calls() {
+		if strings.Contains(call.Messages[len(call.Messages)-1].Content, "Update the summary of a conversation") {
+			summaries = append(summaries, call)
+		} else {
+			replies = append(replies, call)
+		}
+	}
+	require.Len(t, summaries, 1)
+	require.Contains(t, summaries[0].Messages[0].Content, "message 01")
+	require.Contains(t, summaries[0].Messages[0].Content, "message 10")
+	require.NotContains(t, summaries[0].Messages[0].Content, "message 11")
+	require.Len(t, replies, 1)
+	require.Equal(t, "message 11", replies[0].Messages[0].Content)
+}
+
+func TestManageAndExportChatSessions(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "short title", Reply: "Go careers"},
+		infrastructure.ScriptedRule{Match: "", Reply: "Apply to **remote** Go roles."},
+	)
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+	repo := newMemoryChatRepository()
+	uc := usecases.NewChatUsecase(repo, ai, nil, nil, infrastructure.NewDocumentExporter(), 0)
+	ctx := context.Background()
+
+	resp, err := uc.SendMessage(ctx, &domain.ChatRequest{UserID: "user-1", Message: "How do I find Go jobs?"})
+	require.NoError(t, err)
+
+	title, pinned := "  My   Go job hunt ", true
+	session, err := uc.UpdateSessionSettings(ctx, resp.SessionID, "user-1", domain.ChatSessionUpdate{Title: &title, Pinned: &pinned})
+	require.NoError(t, err)
+	require.Equal(t, "My Go job hunt", session.Title)
+	require.True(t, session.Pinned)
+
+	empty := " "
+	_, err = uc.UpdateSessionSettings(ctx, resp.SessionID, "user-1", domain.ChatSessionUpdate{Title: &empty})
+	require.ErrorIs(t, err, domain.ErrInvalidInput)
+	_, err = uc.UpdateSessionSettings(ctx, resp.SessionID, "user-1", domain.ChatSessionUpdate{})
+	require.ErrorIs(t, err, domain.ErrInvalidInput)
+	_, err = uc.UpdateSessionSettings(ctx, resp.SessionID, "user-2", domain.ChatSessionUpdate{Pinned: &pinned})
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	archived := true
+	_, err = uc.UpdateSessionSettings(ctx, resp.SessionID, "user-1", domain.ChatSessionUpdate{Archived: &archived})
+	require.NoError(t, err)
+	active, err := uc.GetUserSessions(ctx, "user-1", false, 10, 0)
+	require.NoError(t, err)
+	require.Empty(t, active)
+	archivedSessions, err := uc.GetUserSessions(ctx, "user-1", true, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, archivedSessions, 1)
+
+	doc, err := uc.ExportSession(ctx, resp.SessionID, "user-1", domain.ExportMarkdown)
+	require.NoError(t, err)
+	require.Equal(t, "chat-my-go-job-hunt.md", doc.FileName)
+	md := string(doc.Data)
+	require.True(t, strings.HasPrefix(md, "# My Go job hunt\n"))
+	require.Contains(t, md, "## You (")
+	require.Contains(t, md, "How do I find Go jobs?")
+	require.Contains(t, md, "## JobGen (")
+	require.Contains(t, md, "Apply to **remote** Go roles.")
+
+	doc, err = uc.ExportSession(ctx, resp.SessionID, "user-1", domain.ExportJSON)
+	require.NoError(t, err)
+	require.Equal(t, "application/json", doc.ContentType)
+	var exported struct {
+		Session  domain.ChatSession   `json:"session"`
+		Messages []domain.ChatMessage `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal(doc.Data, &exported))
+	require.Equal(t, "My Go job hunt", exported.Session.Title)
+	require.Len(t, exported.Messages, 2)
+
+	_, err = uc.ExportSession(ctx, resp.SessionID, "user-1", "docx")
+	require.ErrorIs(t, err, domain.ErrInvalidInput)
+	_, err = uc.ExportSession(ctx, resp.SessionID, "user-2", domain.ExportMarkdown)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+// renamingChatRepository renames and pins a session while its reply is being prepared, as a
+// request from another tab could.
+type renamingChatRepository struct {
+	*memoryChatRepository
+}
+
+func (r renamingChatRepository) GetSessionMessages(ctx context.Context, sessionID, userID string, limit, offset int) ([]domain.ChatMessage, error) {
+	title, pinned := "Renamed", true
+	if _, err := r.UpdateSessionSettings(ctx, sessionID, userID, domain.ChatSessionUpdate{Title: &title, Pinned: &pinned}); err != nil {
+		return nil, err
+	}
+	return r.memoryChatRepository.GetSessionMessages(ctx, sessionID, userID, limit, offset)
+}
+
+func TestChatReplyKeepsSessionChangesMadeMeanwhile(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "short title", Reply: "Go careers"},
+		infrastructure.ScriptedRule{Match: "", Reply: "Apply to remote Go roles."},
+	)
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+	repo := newMemoryChatRepository()
+	uc := usecases.NewChatUsecase(renamingChatRepository{repo}, ai, nil, nil, nil, 0)
+	ctx := context.Background()
+
+	first, err := uc.SendMessage(ctx, &domain.ChatRequest{UserID: "user-1", Message: "How do I find Go jobs?"})
+	require.NoError(t, err)
+	require.Equal(t, "Go careers", first.Session.Title)
+
+	resp, err := uc.SendMessage(ctx, &domain.ChatRequest{UserID: "user-1", SessionID: first.SessionID, Message: "Any in Kenya?"})
+	require.NoError(t, err)
+	session := repo.sessions[first.SessionID]
+	require.Equal(t, "Renamed", session.Title)
+	require.True(t, session.Pinned)
+	require.Equal(t, 4, session.MessageCount)
+	require.Equal(t, session, *resp.Session)
+}
+
+// failingChatRepository fails session reads and deletes the way a lost database connection would.
+type failingChatRepository struct {
+	*memoryChatRepository
+}
+
+var errChatStoreDown = errors.New("connection reset")
+
+func (r failingChatRepository) GetSession(context.Context, string, string) (*domain.ChatSession, error) {
+	return nil, errChatStoreDown
+}
+
+func (r failingChatRepository) DeleteSession(context.Context, string, string) error {
+	return errChatStoreDown
+}
+
+// failingExporter cannot render any document.
+type failingExporter struct{}
+
+func (failingExporter) Export(string, string, string, domain.ExportFormat) (*domain.ExportedDocument, error) {
+	return nil, errors.New("font not found")
+}
+
+func TestChatSessionErrorsKeepTheirStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := newMemoryChatRepository()
+	repo.sessions["session-1"] = domain.ChatSession{ID: "session-1", UserID: "user-1", Title: "Go jobs"}
+	serve := func(uc domain.IChatUsecase, method, path string) int {
+		ctrl := controllers.NewChatController(uc)
+		r := gin.New()
+		auth := func(c *gin.Context) { c.Set("user_id", "user-1") }
+		r.GET("/chat/session/:session_id", auth, ctrl.GetSessionHistory)
+		r.DELETE("/chat/session/:session_id", auth, ctrl.DeleteSession)
+		r.GET("/chat/session/:session_id/export", auth, ctrl.ExportSession)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	working := usecases.NewChatUsecase(repo, nil, nil, nil, failingExporter{}, 0)
+	require.Equal(t, http.StatusNotFound, serve(working, http.MethodGet, "/chat/session/session-2"))
+	require.Equal(t, http.StatusNotFound, serve(working, http.MethodGet, "/chat/session/session-2/export?format=json"))
+	require.Equal(t, http.StatusBadRequest, serve(working, http.MethodGet, "/chat/session/session-1/export?format=docx"))
+	require.Equal(t, http.StatusInternalServerError, serve(working, http.MethodGet, "/chat/session/session-1/export?format=pdf"))
+	require.Equal(t, http.StatusOK, serve(working, http.MethodGet, "/chat/session/session-1/export?format=json"))
+
+	broken := usecases.NewChatUsecase(failingChatRepository{repo}, nil, nil, nil, failingExporter{}, 0)
+	require.Equal(t, http.StatusInternalServerError, serve(broken, http.MethodGet, "/chat/session/session-1"))
+	require.Equal(t, http.StatusInternalServerError, serve(broken, http.MethodDelete, "/chat/session/session-1"))
+	require.Equal(t, http.StatusInternalServerError, serve(broken, http.MethodGet, "/chat/session/session-1/export?format=json"))
+}
+
+func TestChatMessageToAnUnknownSessionIsNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := newMemoryChatRepository()
+	send := func(uc domain.IChatUsecase, path string) int {
+		ctrl := controllers.NewChatController(uc)
+		r := gin.New()
+		auth := func(c *gin.Context) { c.Set("user_id", "user-1") }
+		r.POST("/chat/message", auth, ctrl.SendMessage)
+		r.POST("/chat/message/stream", auth, ctrl.SendMessageStream)
+		w := httptest.NewRecorder()
+		body := strings.NewReader(`{"message":"Any Go jobs?","session_id":"session-2"}`)
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, body))
+		return w.Code
+	}
+
+	working := usecases.NewChatUsecase(repo, nil, nil, nil, nil, 0)
+	require.Equal(t, http.StatusNotFound, send(working, "/chat/message"))
+	require.Equal(t, http.StatusNotFound, send(working, "/chat/message/stream"))
+
+	broken := usecases.NewChatUsecase(failingChatRepository{repo}, nil, nil, nil, nil, 0)
+	require.Equal(t, http.StatusInternalServerError, send(broken, "/chat/message"))
+	require.Equal(t, http.StatusInternalServerError, send(broken, "/chat/message/stream"))
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryChatRepository keeps sessions and messages in memory.
+type memoryChatRepository struct {
+	sessions map[string]domain.ChatSession
+	messages []domain.ChatMessage
+}
+
+func newMemoryChatRepository() *memoryChatRepository {
+	return &memoryChatRepository{sessions: map[string]domain.ChatSession{}}
+}
+
+func (r *memoryChatRepository) CreateSession(_ context.Context, session *domain.ChatSession) error {
+	session.ID = "session-" + strconv.Itoa(len(r.sessions)+1)
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *memoryChatRepository) GetSession(_ context.Context, sessionID, userID string) (*domain.ChatSession, error) {
+	session, ok := r.sessions[sessionID]
+	if !ok || session.UserID != userID {
+		return nil, domain.ErrNotFound
+	}
+	return &session, nil
+}
+
+func (r *memoryChatRepository) UpdateSession(_ context.Context, session *domain.ChatSession) error {
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *memoryChatRepository) RecordExchange(_ context.Context, sessionID, userID string, exchange domain.ChatExchange) (*domain.ChatSession, error) {
+	session, ok := r.sessions[sessionID]
+	if !ok || session.UserID != userID {
+		return nil, domain.ErrNotFound
+	}
+	session.MessageCount += exchange.Messages
+	session.Summary, session.SummarizedCount = exchange.Summary, exchange.SummarizedCount
+	if exchange.Title != "" {
+		session.Title = exchange.Title
+	}
+	r.sessions[sessionID] = session
+	return &session, nil
+}
+
+func (r *memoryChatRepository) GetUserSessions(_ context.Context, userID string, archived bool, _, _ int) ([]domain.ChatSession, error) {
+	var out []domain.ChatSession
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.Archived == archived {
+			out = append(out, session)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryChatRepository) UpdateSessionSettings(_ context.Context, sessionID, userID string, update domain.ChatSessionUpdate) (*domain.ChatSession, error) {
+	session, ok := r.sessions[sessionID]
+	if !ok || session.UserID != userID {
+		return nil, domain.ErrNotFound
+	}
+	if update.Title != nil {
+		session.Title = *update.Title
+	}
+	if update.Pinned != nil {
+		session.Pinned = *update.Pinned
+	}
+	if update.Archived != nil {
+		session.Archived = *update.Archived
+	}
+	r.sessions[sessionID] = session
+	return &session, nil
+}
+
+func (r *memoryChatRepository) SearchMessages(context.Context, string, string, int) ([]domain.ChatSearchHit, error) {
+	return nil, nil
+}
+
+func (r *memoryChatRepository) SaveMessage(_ context.Context, message *domain.ChatMessage) error {
+	message.ID = "msg-" + strconv.Itoa(len(r.messages)+1)
+	r.messages = append(r.messages, *message)
+	return nil
+}
+
+func (r *memoryChatRepository) GetSessionMessages(_ context.Context, sessionID, _ string, limit, offset int) ([]domain.ChatMessage, error) {
+	var out []domain.ChatMessage
+	for _, msg := range r.messages {
+		if msg.SessionID == sessionID {
+			out = append(out, msg)
+		}
+	}
+	end := len(out) - offset
+	if end < 0 {
+		end = 0
+	}
+	start := 0
+	if limit > 0 && end-limit > 0 {
+		start = end - limit
+	}
+	return out[start:end], nil
+}
+
+func (r *memoryChatRepository) DeleteSession(context.Context, string, string) error { return nil }
+
+func TestSendMessageStreamRelaysAndSavesReply(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "short title", Reply: "Go careers"},
+		infrastructure.ScriptedRule{Match: "", Reply: "Learn Go and apply to remote roles"},
+	)
+	repo := newMemoryChatRepository()
+	uc := usecases.NewChatUsecase(repo, infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{})), nil, nil, nil, 0)
+
+	var deltas []string
+	resp, err := uc.SendMessageStream(context.Background(), &domain.ChatRequest{UserID: "user-1", Message: "How do I get a Go job?"},
+		func(delta string) error {
+			deltas = append(deltas, delta)
+			return nil
+		})
+	require.NoError(t, err)
+	require.Greater(t, len(deltas), 1)
+	require.Equal(t, "Learn Go and apply to remote roles", strings.Join(deltas, ""))
+	require.Equal(t, "Learn Go and apply to remote roles", resp.Message)
+	require.False(t, resp.Partial)
+	require.Equal(t, "Go careers", resp.Session.Title)
+	require.Equal(t, 2, resp.Session.MessageCount)
+	require.Len(t, repo.messages, 2)
+	require.Equal(t, resp.MessageID, repo.messages[1].ID)
+
+	// The client goes away after two pieces; what it was shown is kept as a partial reply
+	gone := errors.New("client disconnected")
+	sent := 0
+	_, err = uc.SendMessageStream(context.Background(), &domain.ChatRequest{UserID: "user-1", SessionID: resp.SessionID, Message: "Tell me more"},
+		func(delta string) error {
+			if sent == 2 {
+				return gone
+			}
+			sent++
+			return nil
+		})
+	require.ErrorIs(t, err, gone)
+	require.Len(t, repo.messages, 4)
+	// The space after "Go" was held back in case a citation followed, so it was never shown
+	require.Equal(t, "Learn Go", repo.messages[3].Content)
+	require.True(t, repo.messages[3].Partial)
+	require.Equal(t, 4, repo.sessions[resp.SessionID].MessageCount)
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	domain "jobgen-backend/Domain"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCoverLetterRepository keeps drafts in memory and, like the unique index, refuses a
+// second draft with the same version.
+type memoryCoverLetterRepository struct {
+	drafts []domain.CoverLetterDraft
+}
+
+func (r *memoryCoverLetterRepository) Create(_ context.Context, draft *domain.CoverLetterDraft) error {
+	for _, existing := range r.drafts {
+		if existing.UserID == draft.UserID && existing.JobID == draft.JobID && existing.Version == draft.Version {
+			return domain.ErrDraftVersionConflict
+		}
+	}
+	draft.ID = "draft-" + strconv.Itoa(len(r.drafts)+1)
+	draft.CreatedAt = time.Now()
+	r.drafts = append(r.drafts, *draft)
+	return nil
+}
+
+func (r *memoryCoverLetterRepository) GetLatest(_ context.Context, userID, jobID string) (*domain.CoverLetterDraft, error) {
+	var latest *domain.CoverLetterDraft
+	for i, draft := range r.drafts {
+		if draft.UserID == userID && draft.JobID == jobID && (latest == nil || draft.Version > latest.Version) {
+			latest = &r.drafts[i]
+		}
+	}
+	if latest == nil {
+		return nil, domain.ErrNotFound
+	}
+	found := *latest
+	return &found, nil
+}
+
+func (r *memoryCoverLetterRepository) GetVersion(context.Context, string, string, int) (*domain.CoverLetterDraft, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (r *memoryCoverLetterRepository) ListVersions(context.Context, string, string) ([]domain.CoverLetterDraft, error) {
+	return r.drafts, nil
+}
+
+// racingCoverLetterRepository saves a competing draft just before the first Create, as a
+// concurrent request would.
+type racingCoverLetterRepository struct {
+	*memoryCoverLetterRepository
+	raced bool
+}
+
+func (r *racingCoverLetterRepository) Create(ctx context.Context, draft *domain.CoverLetterDraft) error {
+	if !r.raced {
+		r.raced = true
+		competitor := *draft
+		competitor.Content = "Written in another tab."
+		if err := r.memoryCoverLetterRepository.Create(ctx, &competitor); err != nil {
+			return err
+		}
+	}
+	return r.memoryCoverLetterRepository.Create(ctx, draft)
+}
+
+func TestCoverLetterEditRetriesWhenTheVersionIsTaken(t *testing.T) {
+	repo := &racingCoverLetterRepository{memoryCoverLetterRepository: &memoryCoverLetterRepository{drafts: []domain.CoverLetterDraft{
+		{ID: "draft-0", UserID: "user-1", JobID: "job-1", CVID: "cv-1", Version: 1, Tone: domain.ToneProfessional, Content: "Dear team,"},
+	}}}
+	uc := usecases.NewCoverLetterUsecase(repo, nil, nil, nil, nil, time.Second)
+
+	draft, err := uc.Edit(context.Background(), "user-1", "job-1", "Dear hiring manager,")
+	require.NoError(t, err)
+	require.Equal(t, 3, draft.Version)
+	require.Equal(t, "cv-1", draft.CVID)
+
+	latest, err := repo.GetLatest(context.Background(), "user-1", "job-1")
+	require.NoError(t, err)
+	require.Equal(t, "Dear hiring manager,", latest.Content)
+	require.Len(t, repo.drafts, 3)
+}
@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"testing"
+
+	infrastructure "jobgen-backend/Infrastructure"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/stretchr/testify/require"
+)
+
+// cvEvalBaseline is the F1 the parser reaches on testdata/cv-corpus. Raise a floor when a
+// parser change improves a field; a drop below it is a regression.
+var cvEvalBaseline = map[string]float64{
+	"skills":                1.0,
+	"experience.title":      0.8,
+	"experience.company":    0.8,
+	"experience.dates":      1.0,
+	"education.degree":      1.0,
+	"education.institution": 0.3,
+	"education.graduation":  1.0,
+}
+
+func TestCVParserCorpus(t *testing.T) {
+	report, err := usecases.EvaluateCVCorpus("../testdata/cv-corpus", infrastructure.NewCVParserService())
+	require.NoError(t, err)
+	require.Zero(t, report.Failed, "corpus documents failed to parse")
+
+	for _, field := range report.FieldNames() {
+		score := report.Fields[field]
+		require.GreaterOrEqual(t, score.F1(), cvEvalBaseline[field], "%s regressed: precision %.3f, recall %.3f",
+			field, score.Precision(), score.Recall())
+	}
+}
@@ -0,0 +1,298 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	controllers "jobgen-backend/Delivery/Controllers"
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryCVEventBusDeliversToJobSubscribers(t *testing.T) {
+	bus := infrastructure.NewInMemoryCVEventBus()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := bus.Subscribe(ctx, "cv-1")
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(domain.CVJobEvent{JobID: "cv-2", Type: domain.CVEventStatus, Status: domain.StatusProcessing}))
+	require.NoError(t, bus.Publish(domain.CVJobEvent{JobID: "cv-1", Type: domain.CVEventStage, Stage: domain.StageTextExtracted}))
+	require.NoError(t, bus.Publish(domain.CVJobEvent{JobID: "cv-1", Type: domain.CVEventStatus, Status: domain.StatusCompleted}))
+
+	first := <-events
+	require.Equal(t, domain.StageTextExtracted, first.Stage)
+	require.False(t, first.Terminal())
+	second := <-events
+	require.True(t, second.Terminal())
+
+	// Ending the subscription closes the channel
+	cancel()
+	select {
+	case _, ok := <-events:
+		require.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription channel was not closed")
+	}
+}
+
+func TestWebhookSenderSignsPayload(t *testing.T) {
+	payload := []byte(`{"event":"cv.job.finished","jobId":"cv-1","status":"Completed"}`)
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := infrastructure.NewWebhookSender(time.Second, loopbackNetwork(t))
+	require.NoError(t, sender.Send(context.Background(), server.URL, "whsec_test", "cv.job.finished", payload))
+
+	require.Equal(t, payload, body)
+	require.Equal(t, "cv.job.finished", got.Header.Get(infrastructure.WebhookEventHeader))
+	timestamp := got.Header.Get(infrastructure.WebhookTimestampHeader)
+	require.NotEmpty(t, timestamp)
+	require.Equal(t, "sha256="+infrastructure.SignWebhookPayload("whsec_test", timestamp, payload), got.Header.Get(infrastructure.WebhookSignatureHeader))
+
+	// Non-2xx responses count as failed deliveries
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	require.Error(t, sender.Send(context.Background(), failing.URL, "whsec_test", "cv.job.finished", payload))
+}
+
+// loopbackNetwork exempts 127.0.0.0/8 so tests can reach httptest servers.
+func loopbackNetwork(t *testing.T) *net.IPNet {
+	t.Helper()
+	_, network, err := net.ParseCIDR("127.0.0.0/8")
+	require.NoError(t, err)
+	return network
+}
+
+func TestWebhookSenderRefusesPrivateAddressesAtDialTime(t *testing.T) {
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	payload := []byte(`{}`)
+	sender := infrastructure.NewWebhookSender(time.Second)
+
+	// Both a literal loopback address and a hostname resolving to one are refused
+	err := sender.Send(context.Background(), server.URL, "whsec_test", "cv.job.finished", payload)
+	require.ErrorIs(t, err, infrastructure.ErrWebhookAddressBlocked)
+	byName := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	err = sender.Send(context.Background(), byName, "whsec_test", "cv.job.finished", payload)
+	require.ErrorIs(t, err, infrastructure.ErrWebhookAddressBlocked)
+	require.Zero(t, hits.Load())
+
+	for _, target := range []string{"http://169.254.169.254/latest/meta-data/", "http://10.0.0.1/", "http://[::1]/", "http://0.0.0.0/", "http://224.0.0.1/"} {
+		err := sender.Send(context.Background(), target, "whsec_test", "cv.job.finished", payload)
+		require.ErrorIs(t, err, infrastructure.ErrWebhookAddressBlocked, target)
+	}
+}
+
+func TestWebhookSenderChecksRedirectTargets(t *testing.T) {
+	var landed atomic.Int64
+	landing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		landed.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer landing.Close()
+	redirect := func(to string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, to, http.StatusTemporaryRedirect)
+		}))
+	}
+	sender := infrastructure.NewWebhookSender(time.Second, loopbackNetwork(t))
+	payload := []byte(`{}`)
+
+	toMetadata := redirect("http://169.254.169.254/latest/meta-data/")
+	defer toMetadata.Close()
+	err := sender.Send(context.Background(), toMetadata.URL, "whsec_test", "cv.job.finished", payload)
+	require.ErrorIs(t, err, infrastructure.ErrWebhookAddressBlocked)
+
+	// Allowed targets are followed, with the body and signature intact
+	toLanding := redirect(landing.URL)
+	defer toLanding.Close()
+	require.NoError(t, sender.Send(context.Background(), toLanding.URL, "whsec_test", "cv.job.finished", payload))
+	require.Equal(t, int64(1), landed.Load())
+
+	loop := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.String(), http.StatusTemporaryRedirect)
+	}))
+	defer loop.Close()
+	require.ErrorContains(t, sender.Send(context.Background(), loop.URL, "whsec_test", "cv.job.finished", payload), "redirected more than")
+}
+
+// memoryWebhookRepository keeps registered webhooks in memory.
+type memoryWebhookRepository struct {
+	domain.ICVWebhookRepository
+	hooks []domain.CVWebhook
+}
+
+func (r *memoryWebhookRepository) Create(_ context.Context, hook *domain.CVWebhook) error {
+	r.hooks = append(r.hooks, *hook)
+	return nil
+}
+
+func (r *memoryWebhookRepository) ListByUser(_ context.Context, userID string) ([]domain.CVWebhook, error) {
+	var hooks []domain.CVWebhook
+	for _, hook := range r.hooks {
+		if hook.UserID == userID {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func TestRegisterWebhookValidatesURL(t *testing.T) {
+	hooks := &memoryWebhookRepository{}
+	uc := usecases.NewCVUsecase(nil, nil, nil, nil, nil, nil, nil, hooks)
+	ctx := context.Background()
+
+	rejected := []string{
+		"ftp://example.com/hook",
+		"/relative/hook",
+		"https://",
+		"http://localhost:8080/hook",
+		"http://api.localhost/hook",
+		"http://127.0.0.1/hook",
+		"http://10.1.2.3/hook",
+		"http://192.168.0.10/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://0.0.0.0/hook",
+		"http://239.1.1.1/hook",
+	}
+	for _, rawURL := range rejected {
+		_, err := uc.RegisterWebhook(ctx, "user-1", rawURL)
+		require.ErrorIs(t, err, domain.ErrInvalidInput, rawURL)
+	}
+	require.Empty(t, hooks.hooks)
+
+	hook, err := uc.RegisterWebhook(ctx, "user-1", "  https://hooks.example.com/jobgen  ")
+	require.NoError(t, err)
+	require.Equal(t, "https://hooks.example.com/jobgen", hook.URL)
+	require.True(t, strings.HasPrefix(hook.Secret, "whsec_"))
+
+	for i := 1; i < 5; i++ {
+		_, err := uc.RegisterWebhook(ctx, "user-1", "https://hooks.example.com/jobgen")
+		require.NoError(t, err)
+	}
+	_, err = uc.RegisterWebhook(ctx, "user-1", "https://hooks.example.com/jobgen")
+	require.ErrorIs(t, err, domain.ErrInvalidInput, "at most five webhooks per user")
+}
+
+// sseEvent is one server-sent event read back from a stream.
+type sseEvent struct{ name, data string }
+
+func readSSE(t *testing.T, body io.Reader, events chan<- sseEvent) {
+	t.Helper()
+	defer close(events)
+	var current sseEvent
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			current.name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			current.data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		case line == "" && current.name != "":
+			events <- current
+			current = sseEvent{}
+		}
+	}
+}
+
+func TestStreamJobEventsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &memoryCVRepository{cvs: map[string]domain.CV{
+		"cv-running": {ID: "cv-running", UserID: "user-1", Status: domain.StatusProcessing},
+		"cv-done":    {ID: "cv-done", UserID: "user-1", Status: domain.StatusCompleted},
+		"cv-other":   {ID: "cv-other", UserID: "user-2", Status: domain.StatusProcessing},
+	}}
+	bus := infrastructure.NewInMemoryCVEventBus()
+	uc := usecases.NewCVUsecase(repo, nil, nil, nil, nil, nil, bus, nil)
+	r := gin.New()
+	r.GET("/cv/parse/:jobId/events", func(c *gin.Context) { c.Set("user_id", "user-1") }, controllers.NewCVController(uc).StreamJobEventsHandler)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	stream := func(jobID string) (*http.Response, <-chan sseEvent) {
+		resp, err := http.Get(server.URL + "/cv/parse/" + jobID + "/events")
+		require.NoError(t, err)
+		events := make(chan sseEvent, 10)
+		if resp.StatusCode == http.StatusOK {
+			go readSSE(t, resp.Body, events)
+		} else {
+			close(events)
+		}
+		return resp, events
+	}
+	next := func(events <-chan sseEvent) sseEvent {
+		select {
+		case event, ok := <-events:
+			require.True(t, ok, "stream ended early")
+			return event
+		case <-time.After(2 * time.Second):
+			t.Fatal("no event received")
+			return sseEvent{}
+		}
+	}
+
+	// Another user's job and unknown jobs are refused before the stream starts
+	resp, _ := stream("cv-other")
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp, _ = stream("cv-missing")
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// A finished job sends its status and ends the stream
+	resp, events := stream("cv-done")
+	require.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"))
+	done := next(events)
+	require.Equal(t, "status", done.name)
+	require.Contains(t, done.data, `"status":"Completed"`)
+	_, open := <-events
+	require.False(t, open)
+	resp.Body.Close()
+
+	// A running job streams its current status, then each transition until a final one
+	resp, events = stream("cv-running")
+	defer resp.Body.Close()
+	current := next(events)
+	require.Equal(t, "status", current.name)
+	require.Contains(t, current.data, `"status":"Processing"`)
+
+	require.NoError(t, bus.Publish(domain.CVJobEvent{JobID: "cv-other", Type: domain.CVEventStatus, Status: domain.StatusCompleted}))
+	require.NoError(t, bus.Publish(domain.CVJobEvent{JobID: "cv-running", Type: domain.CVEventStage, Stage: domain.StageTextExtracted}))
+	require.NoError(t, bus.Publish(domain.CVJobEvent{JobID: "cv-running", Type: domain.CVEventStatus, Status: domain.StatusNeedsReview}))
+	stage := next(events)
+	require.Equal(t, "stage", stage.name)
+	require.Contains(t, stage.data, string(domain.StageTextExtracted))
+	final := next(events)
+	require.Equal(t, "status", final.name)
+	require.Contains(t, final.data, `"status":"NeedsReview"`)
+	_, open = <-events
+	require.False(t, open)
+}
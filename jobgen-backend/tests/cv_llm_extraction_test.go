@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"testing"
+
+	domain "jobgen-backend/Domain"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/stretchr/testify/require"
+)
+
+const llmExtractionCVText = `Jane Doe
+Experience
+Platform Engineer at Globex
+Jan 2020 - Present
+Education
+BSc Informatics, University of Nairobi 2019
+Skills
+Go, Kafka`
+
+func TestReconcileLLMExtraction(t *testing.T) {
+	t.Run("valid sections replace the heuristic parse", func(t *testing.T) {
+		cv, err := usecases.ParseTextToCVSections(llmExtractionCVText)
+		require.NoError(t, err)
+
+		problems := usecases.ReconcileLLMExtraction(cv, llmExtractionCVText, `{
+			"profileSummary": "",
+			"experiences": [{"title": "Platform Engineer", "company": "Globex", "location": "", "startDate": "2020-01", "endDate": "", "description": ""}],
+			"educations": [{"degree": "BSc Informatics", "institution": "University of Nairobi", "location": "", "graduationDate": "2019"}],
+			"skills": ["Go", "Kafka"]
+		}`)
+
+		require.Empty(t, problems)
+		require.Equal(t, "Platform Engineer", cv.Experiences[0].Title)
+		require.Equal(t, "University of Nairobi", cv.Educations[0].Institution)
+		require.Equal(t, domain.FieldSourceLLM, cv.FieldSources["experiences"])
+		require.Equal(t, domain.FieldSourceLLM, cv.FieldSources["educations"])
+		require.Equal(t, domain.FieldSourceHeuristic, cv.FieldSources["profileSummary"])
+	})
+
+	t.Run("malformed or invented sections keep the heuristic parse", func(t *testing.T) {
+		cv, err := usecases.ParseTextToCVSections(llmExtractionCVText)
+		require.NoError(t, err)
+		heuristicEducations := cv.Educations
+
+		problems := usecases.ReconcileLLMExtraction(cv, llmExtractionCVText, `{
+			"experiences": [{"title": "CTO", "company": "Initech", "startDate": "2020-01"}],
+			"educations": [{"institution": "University of Nairobi", "graduationDate": "June 2019"}],
+			"skills": ["Go", "Kafka"]
+		}`)
+
+		require.Len(t, problems, 2)
+		require.Equal(t, heuristicEducations, cv.Educations)
+		require.Equal(t, domain.FieldSourceHeuristic, cv.FieldSources["experiences"])
+		require.Equal(t, domain.FieldSourceHeuristic, cv.FieldSources["educations"])
+		require.Equal(t, domain.FieldSourceLLM, cv.FieldSources["skills"])
+	})
+}
@@ -0,0 +1,337 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+	worker "jobgen-backend/Worker"
+
+	"github.com/stretchr/testify/require"
+)
+
+// statusRecordingCVRepository records the last status and message set for each CV; like the
+// Mongo repository, a status without a message clears the previous one.
+type statusRecordingCVRepository struct {
+	domain.CVRepository
+	mu       sync.Mutex
+	statuses map[string]domain.JobStatus
+	messages map[string]string
+}
+
+func newStatusRecordingCVRepository() *statusRecordingCVRepository {
+	return &statusRecordingCVRepository{statuses: make(map[string]domain.JobStatus), messages: make(map[string]string)}
+}
+
+func (r *statusRecordingCVRepository) UpdateStatus(id string, status domain.JobStatus, procError ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses[id] = status
+	r.messages[id] = ""
+	if len(procError) > 0 {
+		r.messages[id] = procError[0]
+	}
+	return nil
+}
+
+func (r *statusRecordingCVRepository) status(id string) (domain.JobStatus, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.statuses[id], r.messages[id]
+}
+
+func TestCVProcessorFailsJobWhoseFinalLeaseExpired(t *testing.T) {
+	q := infrastructure.NewInMemoryQueueService(10, infrastructure.QueueOptions{
+		VisibilityTimeout: 20 * time.Millisecond,
+		MaxAttempts:       1,
+	})
+	repo := newStatusRecordingCVRepository()
+	require.NoError(t, q.Enqueue("cv-1"))
+
+	// A worker takes the only attempt and dies without settling it
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+
+	processor := worker.NewCVProcessor(q, repo, nil, nil, nil, nil, worker.CVProcessorOptions{})
+	runCtx, stop := context.WithCancel(context.Background())
+	go processor.Run(runCtx)
+	defer func() {
+		stop()
+		require.NoError(t, processor.Shutdown(context.Background()))
+	}()
+
+	// Dead-lettering is the last step, after the CV was marked failed
+	var dead []domain.DeadLetteredJob
+	require.Eventually(t, func() bool {
+		dead, err = q.ListDeadLetters()
+		return err == nil && len(dead) == 1
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, "cv-1", dead[0].JobID)
+	require.Equal(t, 2, dead[0].Attempts)
+
+	status, message := repo.status("cv-1")
+	require.Equal(t, domain.StatusFailed, status)
+	require.Equal(t, "lease expired on the final attempt", message)
+	require.Equal(t, int64(1), processor.Stats().DeadLettered)
+}
+
+// processorCVRepository serves a CV for every job ID and records what the processor stores.
+type processorCVRepository struct {
+	*statusRecordingCVRepository
+}
+
+func (r processorCVRepository) GetByID(id string) (*domain.CV, error) {
+	return &domain.CV{ID: id, UserID: "user-1", FileStorageID: id + ".txt"}, nil
+}
+
+func (r processorCVRepository) UpdateWithResults(id string, results *domain.CV) error {
+	return r.UpdateStatus(id, domain.StatusCompleted, results.ProcessingError)
+}
+
+// textFileStore serves the same plain-text CV for every file.
+type textFileStore struct{}
+
+func (textFileStore) GetFile(string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("Jane Doe\nSoftware Engineer\n\nSkills\nGo, Docker\n")), nil
+}
+
+// gatedAI holds every analysis until release is closed or the job's context ends, and tracks
+// how many wait at once.
+type gatedAI struct {
+	domain.IAIService
+	release            chan struct{}
+	waiting, maxWaited atomic.Int64
+}
+
+func (g *gatedAI) SuggestCVImprovements(ctx context.Context, _ string) ([]domain.Suggestion, error) {
+	n := g.waiting.Add(1)
+	defer g.waiting.Add(-1)
+	for {
+		max := g.maxWaited.Load()
+		if n <= max || g.maxWaited.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	select {
+	case <-g.release:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func startGatedProcessor(t *testing.T, q infrastructure.QueueService, concurrency int) (*worker.CVProcessor, *gatedAI, *statusRecordingCVRepository, context.CancelFunc) {
+	t.Helper()
+	repo := newStatusRecordingCVRepository()
+	ai := &gatedAI{release: make(chan struct{})}
+	processor := worker.NewCVProcessor(q, processorCVRepository{repo}, infrastructure.NewCVParserService(), textFileStore{}, ai, nil,
+		worker.CVProcessorOptions{Concurrency: concurrency, JobTimeout: 5 * time.Second})
+	ctx, stop := context.WithCancel(context.Background())
+	go processor.Run(ctx)
+	return processor, ai, repo, stop
+}
+
+func TestCVProcessorRunsJobsConcurrently(t *testing.T) {
+	q := infrastructure.NewInMemoryQueueService(10, infrastructure.QueueOptions{})
+	processor, ai, repo, stop := startGatedProcessor(t, q, 3)
+	for _, id := range []string{"cv-1", "cv-2", "cv-3", "cv-4"} {
+		require.NoError(t, q.Enqueue(id))
+	}
+
+	// Three workers pick up three jobs; the fourth waits for a free worker
+	require.Eventually(t, func() bool { return ai.waiting.Load() == 3 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, int64(3), ai.maxWaited.Load())
+	require.Equal(t, int64(3), processor.Stats().InFlight)
+
+	close(ai.release)
+	require.Eventually(t, func() bool { return processor.Stats().Succeeded == 4 }, time.Second, 5*time.Millisecond)
+	for _, id := range []string{"cv-1", "cv-2", "cv-3", "cv-4"} {
+		status, _ := repo.status(id)
+		require.Equal(t, domain.StatusCompleted, status, id)
+	}
+
+	stop()
+	require.NoError(t, processor.Shutdown(context.Background()))
+	require.False(t, processor.Stats().Running)
+}
+
+func TestCVProcessorShutdownDrainsInFlightJobs(t *testing.T) {
+	q := infrastructure.NewInMemoryQueueService(10, infrastructure.QueueOptions{})
+	processor, ai, repo, stop := startGatedProcessor(t, q, 2)
+	require.NoError(t, q.Enqueue("cv-1"))
+	require.Eventually(t, func() bool { return ai.waiting.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	// Stopping takes no new jobs but lets the running one finish
+	stop()
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- processor.Shutdown(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case err := <-shutdown:
+		t.Fatalf("shutdown returned with a job in flight: %v", err)
+	default:
+	}
+
+	close(ai.release)
+	require.NoError(t, <-shutdown)
+	status, _ := repo.status("cv-1")
+	require.Equal(t, domain.StatusCompleted, status)
+	require.Equal(t, int64(1), processor.Stats().Succeeded)
+}
+
+func TestCVProcessorShutdownDeadlineRequeuesInFlightJobs(t *testing.T) {
+	q := infrastructure.NewInMemoryQueueService(10, infrastructure.QueueOptions{BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	processor, ai, repo, stop := startGatedProcessor(t, q, 1)
+	require.NoError(t, q.Enqueue("cv-1"))
+	require.Eventually(t, func() bool { return ai.waiting.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	err := processor.Shutdown(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The interrupted job goes back on the queue for the next process
+	status, message := repo.status("cv-1")
+	require.Equal(t, domain.StatusPending, status)
+	require.Equal(t, "interrupted by shutdown", message)
+	require.Equal(t, int64(1), processor.Stats().Retried)
+	next, err := q.Dequeue(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "cv-1", next.JobID)
+	require.Equal(t, 2, next.Attempt)
+}
+
+func TestDrainInParallelGivesEachStageItsOwnTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	var deadlines sync.Map
+	stage := func(name string, work time.Duration) infrastructure.ShutdownStage {
+		return infrastructure.ShutdownStage{Name: name, Stop: func(ctx context.Context) error {
+			deadline, _ := ctx.Deadline()
+			deadlines.Store(name, time.Until(deadline))
+			select {
+			case <-time.After(work):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}}
+	}
+
+	start := time.Now()
+	err := infrastructure.DrainInParallel(timeout,
+		stage("HTTP server", time.Hour), // never finishes on its own
+		stage("CV worker", 10*time.Millisecond),
+		stage("webhooks", 30*time.Millisecond),
+	)
+	elapsed := time.Since(start)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Contains(t, err.Error(), "HTTP server")
+	require.NotContains(t, err.Error(), "CV worker")
+	require.NotContains(t, err.Error(), "webhooks")
+	// The stages ran side by side, not one after another
+	require.Less(t, elapsed, 2*timeout)
+	deadlines.Range(func(name, left any) bool {
+		require.Greater(t, left.(time.Duration), timeout-20*time.Millisecond, name)
+		return true
+	})
+
+	require.NoError(t, infrastructure.DrainInParallel(timeout))
+	require.True(t, errors.Is(infrastructure.DrainInParallel(timeout, stage("slow", time.Hour)), context.DeadlineExceeded))
+}
+
+// failingAI fails every call with err.
+type failingAI struct {
+	domain.IAIService
+	err error
+}
+
+func (a failingAI) SuggestCVImprovements(context.Context, string) ([]domain.Suggestion, error) {
+	return nil, a.err
+}
+
+func (a failingAI) ExtractCVFields(context.Context, string) (string, error) {
+	return "", a.err
+}
+
+func TestCVProcessorRecordsWhichPathDegraded(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		llmExtraction bool
+		want          string
+	}{
+		{name: "ai unavailable", err: errors.New("model overloaded"), want: "ai_unavailable"},
+		{name: "quota exceeded", err: domain.ErrAIQuotaExceeded, want: "ai_quota_exceeded"},
+		{name: "llm extraction unavailable", err: errors.New("model overloaded"), llmExtraction: true, want: "llm_extraction_unavailable; ai_unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := infrastructure.NewInMemoryQueueService(10, infrastructure.QueueOptions{})
+			repo := newStatusRecordingCVRepository()
+			processor := worker.NewCVProcessor(q, processorCVRepository{repo}, infrastructure.NewCVParserService(), textFileStore{}, failingAI{err: tt.err}, nil,
+				worker.CVProcessorOptions{JobTimeout: 5 * time.Second, LLMExtraction: tt.llmExtraction})
+			ctx, stop := context.WithCancel(context.Background())
+			go processor.Run(ctx)
+			defer func() {
+				stop()
+				require.NoError(t, processor.Shutdown(context.Background()))
+			}()
+
+			require.NoError(t, q.Enqueue("cv-1"))
+			require.Eventually(t, func() bool { return processor.Stats().Succeeded == 1 }, time.Second, 5*time.Millisecond)
+			status, message := repo.status("cv-1")
+			require.Contains(t, []domain.JobStatus{domain.StatusCompleted, domain.StatusNeedsReview}, status)
+			require.Equal(t, tt.want, message)
+		})
+	}
+}
+
+// flakyFileStore fails the first read as an unreachable store would and serves the text CV
+// afterwards.
+type flakyFileStore struct {
+	textFileStore
+	reads atomic.Int64
+}
+
+func (s *flakyFileStore) GetFile(id string) (io.ReadCloser, error) {
+	if s.reads.Add(1) == 1 {
+		return nil, errors.New("storage unreachable")
+	}
+	return s.textFileStore.GetFile(id)
+}
+
+func TestCVProcessorClearsTheRetryMessageOnSuccess(t *testing.T) {
+	q := infrastructure.NewInMemoryQueueService(10, infrastructure.QueueOptions{BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	repo := newStatusRecordingCVRepository()
+	files := &flakyFileStore{}
+	ai := &gatedAI{release: make(chan struct{})}
+	close(ai.release)
+	processor := worker.NewCVProcessor(q, processorCVRepository{repo}, infrastructure.NewCVParserService(), files, ai, nil,
+		worker.CVProcessorOptions{JobTimeout: 5 * time.Second})
+	ctx, stop := context.WithCancel(context.Background())
+	go processor.Run(ctx)
+	defer func() {
+		stop()
+		require.NoError(t, processor.Shutdown(context.Background()))
+	}()
+
+	require.NoError(t, q.Enqueue("cv-1"))
+	require.Eventually(t, func() bool { return processor.Stats().Succeeded == 1 }, time.Second, 5*time.Millisecond)
+	require.Equal(t, int64(1), processor.Stats().Retried)
+	require.Equal(t, int64(2), files.reads.Load())
+	status, message := repo.status("cv-1")
+	require.Contains(t, []domain.JobStatus{domain.StatusCompleted, domain.StatusNeedsReview}, status)
+	require.Empty(t, message)
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	domain "jobgen-backend/Domain"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseCVText(t *testing.T, text string) *domain.CV {
+	t.Helper()
+	cv, err := usecases.ParseTextToCVSections(text)
+	require.NoError(t, err)
+	return cv
+}
+
+func TestParseContactBarFromTheHeader(t *testing.T) {
+	cv := parseCVText(t, `Amina Okafor
+Lagos, Nigeria | +234 803 123 4567 | amina@example.com
+linkedin.com/in/aminaokafor | github.com/aminaok | aminaokafor.dev
+`)
+
+	require.Equal(t, domain.CVContact{
+		Name:      "Amina Okafor",
+		Email:     "amina@example.com",
+		Phone:     "+234 803 123 4567",
+		Location:  "Lagos, Nigeria",
+		LinkedIn:  "https://linkedin.com/in/aminaokafor",
+		GitHub:    "https://github.com/aminaok",
+		Portfolio: "https://aminaokafor.dev",
+	}, cv.Contact)
+	require.Equal(t, map[string]float64{
+		"contact.name":      0.8,  // first header line
+		"contact.email":     0.95, // in the header
+		"contact.phone":     0.9,  // international prefix
+		"contact.location":  0.65, // "City, Country" without a label
+		"contact.linkedin":  0.95,
+		"contact.github":    0.95,
+		"contact.portfolio": 0.6, // bare domain
+	}, cv.FieldConfidence)
+}
+
+func TestParseLabelledContactDetails(t *testing.T) {
+	cv := parseCVText(t, `Senior Backend Engineer
+Location: Nairobi, Kenya
+0803 123 4567
+Portfolio https://kamau.io
+`)
+
+	require.Empty(t, cv.Contact.Name) // a job title is not a name
+	require.Equal(t, "Nairobi, Kenya", cv.Contact.Location)
+	require.Equal(t, "0803 123 4567", cv.Contact.Phone)
+	require.Equal(t, "https://kamau.io", cv.Contact.Portfolio)
+	require.Equal(t, 0.85, cv.FieldConfidence["contact.location"])
+	require.Equal(t, 0.75, cv.FieldConfidence["contact.phone"]) // unlabelled, no prefix
+	require.Equal(t, 0.7, cv.FieldConfidence["contact.portfolio"])
+}
+
+func TestParseContactDetailsOutsideTheHeader(t *testing.T) {
+	cv := parseCVText(t, `Kamau Otieno
+
+Experience
+Backend Engineer at Acme
+Reach me at Kamau.Otieno@Example.com, https://www.linkedin.com/in/kotieno or https://github.com/kotieno.
+Phone: 0712 345 6789
+`)
+
+	require.Equal(t, "Kamau Otieno", cv.Contact.Name)
+	require.Equal(t, "kamau.otieno@example.com", cv.Contact.Email)
+	require.Equal(t, "https://www.linkedin.com/in/kotieno", cv.Contact.LinkedIn)
+	require.Equal(t, "https://github.com/kotieno", cv.Contact.GitHub)
+	require.Equal(t, "0712 345 6789", cv.Contact.Phone)
+	require.Empty(t, cv.Contact.Portfolio) // links in the body are never taken as a portfolio
+	require.Equal(t, 0.8, cv.FieldConfidence["contact.email"])
+	require.Equal(t, 0.85, cv.FieldConfidence["contact.linkedin"])
+	require.Equal(t, 0.85, cv.FieldConfidence["contact.github"])
+	require.Equal(t, 0.6, cv.FieldConfidence["contact.phone"]) // labelled, outside the header
+}
+
+func TestParseLanguages(t *testing.T) {
+	cv := parseCVText(t, `Amina Okafor
+
+Languages
+English (Native), French - B2, Yoruba: fluent
+`)
+	require.Equal(t, []domain.Language{
+		{Name: "English", Proficiency: "native"},
+		{Name: "French", Proficiency: "B2"},
+		{Name: "Yoruba", Proficiency: "fluent"},
+	}, cv.Languages)
+	require.Equal(t, 0.9, cv.FieldConfidence["languages"])
+
+	// Entries that are not languages lower the confidence
+	cv = parseCVText(t, `Amina Okafor
+
+Languages
+Swahili, English fluent, 3 years abroad, Kikuyu basic
+`)
+	require.Equal(t, []domain.Language{
+		{Name: "Swahili"},
+		{Name: "English", Proficiency: "fluent"},
+		{Name: "Kikuyu", Proficiency: "basic"},
+	}, cv.Languages)
+	require.Equal(t, 0.83, cv.FieldConfidence["languages"])
+}
+
+func TestParseCertifications(t *testing.T) {
+	cv := parseCVText(t, `Amina Okafor
+
+Certifications
+AWS Certified Developer - Amazon Web Services, 2022
+Certified Kubernetes Administrator | CNCF
+`)
+	require.Len(t, cv.Certifications, 2)
+	require.Equal(t, "AWS Certified Developer", cv.Certifications[0].Name)
+	require.Equal(t, "Amazon Web Services", cv.Certifications[0].Issuer)
+	require.NotNil(t, cv.Certifications[0].Date)
+	require.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), cv.Certifications[0].Date.UTC())
+	require.Equal(t, "Certified Kubernetes Administrator", cv.Certifications[1].Name)
+	require.Equal(t, "CNCF", cv.Certifications[1].Issuer)
+	require.Nil(t, cv.Certifications[1].Date)
+	require.Equal(t, 0.9, cv.FieldConfidence["certifications"])
+}
+
+func TestParseProjects(t *testing.T) {
+	cv := parseCVText(t, `Amina Okafor
+
+Projects
+Payroll API - Go, PostgreSQL, Docker
+Payroll service for small businesses.
+https://github.com/aminaok/payroll
+
+Budget Bot
+Telegram bot that tracks spending.
+Tech: Python, Redis
+`)
+	require.Len(t, cv.Projects, 2)
+	payroll, bot := cv.Projects[0], cv.Projects[1]
+	require.Equal(t, "Payroll API", payroll.Name)
+	require.ElementsMatch(t, []string{"Go", "PostgreSQL", "Docker"}, payroll.Technologies)
+	require.Equal(t, "https://github.com/aminaok/payroll", payroll.URL)
+	require.Contains(t, payroll.Description, "Payroll service for small businesses.")
+	require.Equal(t, "Budget Bot", bot.Name)
+	require.ElementsMatch(t, []string{"Python", "Redis"}, bot.Technologies)
+	require.Equal(t, "Telegram bot that tracks spending.", bot.Description)
+	require.Empty(t, bot.URL)
+	require.Equal(t, 0.9, cv.FieldConfidence["projects"])
+}
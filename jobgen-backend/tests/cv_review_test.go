@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	domain "jobgen-backend/Domain"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLabelRepository collects the parse labels a review produces.
+type memoryLabelRepository struct {
+	labels []domain.CVParseLabel
+}
+
+func (r *memoryLabelRepository) CreateMany(_ context.Context, labels []domain.CVParseLabel) error {
+	r.labels = append(r.labels, labels...)
+	return nil
+}
+
+func (r *memoryLabelRepository) List(context.Context, string, int) ([]domain.CVParseLabel, error) {
+	return r.labels, nil
+}
+
+func TestLowConfidenceFieldsSkipFieldsNotFound(t *testing.T) {
+	cv := &domain.CV{FieldConfidence: map[string]float64{
+		"contact.email": 0.4,
+		"skills":        0.55,
+		"experiences":   0.9,
+	}}
+	// contact.name and educations were not found, so there is nothing to confirm
+	require.Equal(t, []string{"contact.email", "skills"}, cv.LowConfidenceFields())
+	require.Empty(t, (&domain.CV{}).LowConfidenceFields())
+}
+
+func TestSubmitReviewSavesNewVersions(t *testing.T) {
+	suggestions := []domain.Suggestion{
+		{ID: "sug-skill", Type: domain.SuggestionMissingKeywords, Section: domain.SectionSkills,
+			Original: "golang", Replacement: "Go, gRPC", Content: "Name the tools"},
+		{ID: "sug-summary", Type: domain.SuggestionQuantification, Section: domain.SectionProfileSummary,
+			Original: "led a team", Replacement: "led a team of 6", Content: "Add numbers"},
+	}
+	repo := &memoryCVRepository{cvs: map[string]domain.CV{"cv-1": {
+		ID: "cv-1", UserID: "user-1", Status: domain.StatusNeedsReview, LineageID: "cv-1", Version: 1,
+		Origin:          domain.OriginUpload,
+		ProfileSummary:  "Engineer who led a team.",
+		Contact:         domain.CVContact{Email: "jane@example.con"},
+		Skills:          []string{"golang", "SQL"},
+		FieldConfidence: map[string]float64{"contact.email": 0.4, "skills": 0.5, "experiences": 0.9},
+		ReviewFields:    []string{"contact.email", "skills"},
+		Suggestions:     suggestions,
+		Score:           usecases.CalculateScore(suggestions),
+	}}}
+	labels := &memoryLabelRepository{}
+	uc := usecases.NewCVUsecase(repo, nil, nil, nil, nil, labels, nil, nil)
+	ctx := context.Background()
+
+	skills, _ := json.Marshal([]string{"Go", "SQL"})
+	first, err := uc.SubmitReview(ctx, "user-1", "cv-1", []domain.CVFieldCorrection{
+		{Field: "skills", Action: domain.ReviewCorrect, Value: skills},
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, "cv-1", first.ID)
+	require.Equal(t, 2, first.Version)
+	require.Equal(t, domain.OriginReview, first.Origin)
+	require.Equal(t, domain.StatusNeedsReview, first.Status)
+	require.Equal(t, []string{"contact.email"}, first.ReviewFields)
+	require.Equal(t, []string{"Go", "SQL"}, first.Skills)
+	require.Equal(t, 1.0, first.FieldConfidence["skills"])
+	require.Equal(t, []domain.CVFieldChange{{Field: "skills", Before: "golang, SQL", After: "Go, SQL"}}, first.Changes)
+	// The skills suggestion quoted "golang", which is gone, so it no longer costs points
+	require.Len(t, first.Suggestions, 1)
+	require.Equal(t, "sug-summary", first.Suggestions[0].ID)
+	require.Equal(t, usecases.CalculateScore(suggestions[1:]), first.Score)
+	require.Greater(t, first.Score, repo.cvs["cv-1"].Score)
+
+	// The parsed version is left as it was
+	original := repo.cvs["cv-1"]
+	require.Equal(t, []string{"golang", "SQL"}, original.Skills)
+	require.Equal(t, domain.StatusNeedsReview, original.Status)
+	require.Equal(t, 0.5, original.FieldConfidence["skills"])
+
+	second, err := uc.SubmitReview(ctx, "user-1", first.ID, []domain.CVFieldCorrection{
+		{Field: "contact.email", Action: domain.ReviewConfirm},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, second.Version)
+	require.Equal(t, domain.StatusCompleted, second.Status)
+	require.Empty(t, second.ReviewFields)
+	require.Empty(t, second.Changes)
+
+	require.Len(t, labels.labels, 2)
+	require.Equal(t, `["golang","SQL"]`, labels.labels[0].ParsedValue)
+	require.Equal(t, `["Go","SQL"]`, labels.labels[0].LabelValue)
+	require.True(t, labels.labels[1].Confirmed)
+
+	_, err = uc.SubmitReview(ctx, "user-1", second.ID, []domain.CVFieldCorrection{{Field: "hobbies", Action: domain.ReviewConfirm}})
+	require.ErrorIs(t, err, domain.ErrInvalidInput)
+}
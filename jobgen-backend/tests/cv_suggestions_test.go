@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"sort"
+	"testing"
+
+	domain "jobgen-backend/Domain"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCVRepository keeps CVs in a map; only the methods the suggestion and versioning
+// workflows use do anything.
+type memoryCVRepository struct {
+	cvs map[string]domain.CV
+}
+
+func (r *memoryCVRepository) Create(cv *domain.CV) error {
+	for _, existing := range r.cvs {
+		if cv.LineageID != "" && existing.Lineage() == cv.LineageID && existing.VersionNumber() == cv.VersionNumber() {
+			return domain.ErrCVVersionConflict
+		}
+	}
+	r.cvs[cv.ID] = *cv
+	return nil
+}
+
+func (r *memoryCVRepository) GetByID(id string) (*domain.CV, error) {
+	cv, ok := r.cvs[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &cv, nil
+}
+
+func (r *memoryCVRepository) ListVersions(lineageID string) ([]domain.CV, error) {
+	var out []domain.CV
+	for _, cv := range r.cvs {
+		if cv.Lineage() == lineageID {
+			out = append(out, cv)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].VersionNumber() < out[j].VersionNumber() })
+	return out, nil
+}
+
+func (r *memoryCVRepository) ListByUser(userID string) ([]domain.CV, error) {
+	var out []domain.CV
+	for _, cv := range r.cvs {
+		if cv.UserID == userID {
+			out = append(out, cv)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *memoryCVRepository) UpdateSuggestions(id string, suggestions []domain.Suggestion) error {
+	cv, ok := r.cvs[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	cv.Suggestions = suggestions
+	r.cvs[id] = cv
+	return nil
+}
+
+func (r *memoryCVRepository) UpdateStatus(string, domain.JobStatus, ...string) error { return nil }
+func (r *memoryCVRepository) UpdateWithResults(string, *domain.CV) error             { return nil }
+func (r *memoryCVRepository) ListByStatus(...domain.JobStatus) ([]domain.CV, error) {
+	return nil, nil
+}
+func (r *memoryCVRepository) SetPrimary(string, string) error { return nil }
+
+func TestAcceptAndRejectCVSuggestions(t *testing.T) {
+	suggestions := []domain.Suggestion{
+		{ID: "sug-verb", Type: domain.SuggestionWeakActionVerbs, Section: domain.SectionExperience, TargetID: "exp-1",
+			Original: "worked on  backend services", Replacement: "Built backend services", Content: "Stronger verb"},
+		{ID: "sug-skill", Type: domain.SuggestionMissingKeywords, Section: domain.SectionSkills,
+			Original: "golang", Replacement: "Go, gRPC", Content: "Name the tools"},
+		{ID: "sug-summary", Type: domain.SuggestionQuantification, Section: domain.SectionProfileSummary,
+			Original: "led a team", Replacement: "led a team of 6", Content: "Add numbers"},
+	}
+	repo := &memoryCVRepository{cvs: map[string]domain.CV{"cv-1": {
+		ID:             "cv-1",
+		UserID:         "user-1",
+		Status:         domain.StatusCompleted,
+		ProfileSummary: "Engineer who led a team.",
+		Experiences:    []domain.Experience{{ID: "exp-1", Title: "Engineer", Description: "Worked on backend services for payments"}},
+		Skills:         []string{"Python", "Golang", "SQL"},
+		Suggestions:    suggestions,
+		Score:          usecases.CalculateScore(suggestions),
+	}}}
+	uc := usecases.NewCVUsecase(repo, nil, nil, nil, nil, nil, nil, nil)
+
+	v2, err := uc.AcceptSuggestion("user-1", "cv-1", "sug-verb")
+	require.NoError(t, err)
+	require.Equal(t, domain.OriginSuggestion, v2.Origin)
+	require.Equal(t, 2, v2.Version)
+	require.Equal(t, "Built backend services for payments", v2.Experiences[0].Description)
+	require.True(t, v2.Suggestions[0].Applied)
+	require.Greater(t, v2.Score, repo.cvs["cv-1"].Score)
+	require.Len(t, v2.Changes, 1)
+	require.Equal(t, "experiences[exp-1].description", v2.Changes[0].Field)
+	// The original version is untouched
+	require.Equal(t, "Worked on backend services for payments", repo.cvs["cv-1"].Experiences[0].Description)
+	require.False(t, repo.cvs["cv-1"].Suggestions[0].Applied)
+
+	v3, err := uc.AcceptSuggestion("user-1", v2.ID, "sug-skill")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Python", "Go", "gRPC", "SQL"}, v3.Skills)
+
+	_, err = uc.AcceptSuggestion("user-1", v3.ID, "sug-verb")
+	require.ErrorIs(t, err, domain.ErrInvalidInput)
+	_, err = uc.AcceptSuggestion("user-1", v3.ID, "sug-missing")
+	require.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = uc.AcceptSuggestion("user-2", v3.ID, "sug-summary")
+	require.ErrorIs(t, err, domain.ErrForbidden)
+
+	rejected, err := uc.RejectSuggestion("user-1", v3.ID, "sug-summary")
+	require.NoError(t, err)
+	require.True(t, rejected.Suggestions[2].Rejected)
+	require.Equal(t, v3.Score, rejected.Score)
+	require.Equal(t, "Engineer who led a team.", rejected.ProfileSummary)
+	require.True(t, repo.cvs[v3.ID].Suggestions[2].Rejected)
+	_, err = uc.AcceptSuggestion("user-1", v3.ID, "sug-summary")
+	require.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func TestAcceptSuggestionFailsWhenTextChanged(t *testing.T) {
+	repo := &memoryCVRepository{cvs: map[string]domain.CV{"cv-1": {
+		ID:             "cv-1",
+		UserID:         "user-1",
+		Status:         domain.StatusCompleted,
+		ProfileSummary: "Backend engineer",
+		Suggestions: []domain.Suggestion{{ID: "sug-1", Type: domain.SuggestionQuantification, Section: domain.SectionProfileSummary,
+			Original: "led a team", Replacement: "led a team of 6", Content: "Add numbers"}},
+	}}}
+	uc := usecases.NewCVUsecase(repo, nil, nil, nil, nil, nil, nil, nil)
+
+	_, err := uc.AcceptSuggestion("user-1", "cv-1", "sug-1")
+	require.ErrorIs(t, err, domain.ErrInvalidInput)
+	require.Len(t, repo.cvs, 1)
+}
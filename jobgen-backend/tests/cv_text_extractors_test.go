@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+
+	"github.com/stretchr/testify/require"
+)
+
+// zipFile builds a zip package holding the given entries, in order.
+func zipFile(t *testing.T, entries ...[2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, err := zw.Create(entry[0])
+		require.NoError(t, err)
+		_, err = w.Write([]byte(entry[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+const docxDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Jane Doe</w:t></w:r></w:p>
+<w:p><w:r><w:t>Email:</w:t></w:r><w:r><w:tab/><w:t>jane_doe@example.com</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr/></w:pPr><w:r><w:t>Built payment APIs in Go</w:t></w:r></w:p>
+</w:body></w:document>`
+
+const odtContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:text>
+<text:h>Jane Doe</text:h>
+<text:p>Go<text:s text:c="2"/>developer<office:annotation><text:p>reviewer note</text:p></office:annotation></text:p>
+<text:list><text:list-item><text:p>Built payment APIs</text:p></text:list-item></text:list>
+</office:text></office:body></office:document-content>`
+
+func TestDetectCVFormat(t *testing.T) {
+	pdf, err := infrastructure.NewDocumentExporter().Export("cv", "Jane Doe", "Go developer", domain.ExportPDF)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name string
+		data []byte
+		want infrastructure.CVFormat
+	}{
+		{"pdf", pdf.Data, infrastructure.CVFormatPDF},
+		{"pdf after junk", append([]byte("junk\n"), pdf.Data...), infrastructure.CVFormatPDF},
+		{"docx", zipFile(t, [2]string{"word/document.xml", docxDocument}), infrastructure.CVFormatDOCX},
+		{"odt", zipFile(t, [2]string{"mimetype", "application/vnd.oasis.opendocument.text"}, [2]string{"content.xml", odtContent}), infrastructure.CVFormatODT},
+		{"other zip", zipFile(t, [2]string{"readme.txt", "hello"}), infrastructure.CVFormatUnknown},
+		{"rtf", []byte("\xEF\xBB\xBF {\\rtf1 Jane}"), infrastructure.CVFormatRTF},
+		{"text", []byte("Jane Doe\nGo developer\n"), infrastructure.CVFormatText},
+		{"utf-16 text", []byte{0xFF, 0xFE, 'J', 0, 'a', 0}, infrastructure.CVFormatText},
+		{"legacy doc", []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1rest"), infrastructure.CVFormatUnknown},
+		{"binary", []byte{0x01, 0x02, 0x00, 0x03}, infrastructure.CVFormatUnknown},
+		{"empty", nil, infrastructure.CVFormatUnknown},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.want, infrastructure.DetectCVFormat(tc.data), tc.name)
+	}
+
+	require.Equal(t, "application/pdf", infrastructure.CVFormatPDF.ContentType())
+	require.Equal(t, ".docx", infrastructure.CVFormatDOCX.Extension())
+	require.Equal(t, ".txt", infrastructure.CVFormatText.Extension())
+	require.Equal(t, "application/octet-stream", infrastructure.CVFormatUnknown.ContentType())
+}
+
+func TestCVTextExtractors(t *testing.T) {
+	parser := infrastructure.NewCVParserService()
+	extract := func(data []byte) string {
+		text, err := parser.ExtractText(bytes.NewReader(data))
+		require.NoError(t, err)
+		return text
+	}
+
+	pdf, err := infrastructure.NewDocumentExporter().Export("cv", "Jane Doe", "Go developer\n\nBuilt payment APIs", domain.ExportPDF)
+	require.NoError(t, err)
+	text := extract(pdf.Data)
+	require.Contains(t, text, "Jane Doe")
+	require.Contains(t, text, "Built payment APIs")
+
+	text = extract(zipFile(t, [2]string{"word/document.xml", docxDocument}))
+	require.Equal(t, "Jane Doe\nEmail:\tjane_doe@example.com\n• Built payment APIs in Go", text)
+
+	text = extract(zipFile(t, [2]string{"mimetype", "application/vnd.oasis.opendocument.text"}, [2]string{"content.xml", odtContent}))
+	require.Equal(t, "Jane Doe\nGo  developer\n• Built payment APIs", text)
+
+	text = extract([]byte(`{\rtf1\ansi{\fonttbl{\f0 Arial;}}\f0 Jane Doe\par Caf\'e9 owner\emdash 5 years\par\bullet  Go}`))
+	require.Equal(t, "Jane Doe\nCafé owner—5 years\n• Go", text)
+
+	text = extract([]byte("Jane Doe\r\n\r\n\r\n\r\nGo   developer   \n"))
+	require.Equal(t, "Jane Doe\n\nGo   developer", text)
+
+	// Markdown loses its markup but keeps underscores and asterisks inside words, emails and links
+	text = extract([]byte("# Jane Doe\n\n**Go** developer, *remote*. Email jane_doe@example.com\n\n" +
+		"- Wrote snake_case tools, see [my site](https://example.com/my_site)\n* Ran 2 * 3 services"))
+	require.Equal(t, "Jane Doe\n\nGo developer, remote. Email jane_doe@example.com\n"+
+		"• Wrote snake_case tools, see my site (https://example.com/my_site)\n• Ran 2 * 3 services", text)
+
+	_, err = parser.ExtractText(bytes.NewReader([]byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1rest")))
+	require.True(t, errors.Is(err, domain.ErrUnsupportedCVFormat))
+}
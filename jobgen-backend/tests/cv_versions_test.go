@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "jobgen-backend/Domain"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/stretchr/testify/require"
+)
+
+// racingCVRepository saves a competing version just before the first Create, as a
+// concurrent request would.
+type racingCVRepository struct {
+	*memoryCVRepository
+	raced bool
+}
+
+func (r *racingCVRepository) Create(cv *domain.CV) error {
+	if !r.raced {
+		r.raced = true
+		competitor := *cv
+		competitor.ID = "cv-competitor"
+		if err := r.memoryCVRepository.Create(&competitor); err != nil {
+			return err
+		}
+	}
+	return r.memoryCVRepository.Create(cv)
+}
+
+func versionedCVs() map[string]domain.CV {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return map[string]domain.CV{
+		"cv-1": {ID: "cv-1", UserID: "user-1", Status: domain.StatusCompleted, LineageID: "cv-1", Version: 1,
+			Origin: domain.OriginUpload, IsPrimary: true, ProfileSummary: "Backend engineer.", CreatedAt: created},
+		"cv-2": {ID: "cv-2", UserID: "user-1", Status: domain.StatusCompleted, LineageID: "cv-1", Version: 2,
+			Origin: domain.OriginTailored, SourceCVID: "cv-1", TargetJobID: "job-1",
+			ProfileSummary: "Go backend engineer for Acme.", CreatedAt: created.Add(time.Hour)},
+	}
+}
+
+func TestTailoredCopiesStayOffTheLatestVersion(t *testing.T) {
+	repo := &memoryCVRepository{cvs: versionedCVs()}
+	uc := usecases.NewCVUsecase(repo, nil, nil, nil, nil, nil, nil, nil)
+
+	list, err := uc.ListUserCVs("user-1")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, "cv-1", list[0].ID)
+
+	summary := "Backend engineer who ships."
+	edited, err := uc.UpdateSections("user-1", "cv-1", domain.CVSectionsUpdate{ProfileSummary: &summary})
+	require.NoError(t, err)
+	require.Equal(t, 3, edited.Version)
+	require.True(t, edited.IsPrimary)
+	require.Equal(t, []domain.CVFieldChange{{Field: "profileSummary", Before: "Backend engineer.", After: summary}}, edited.Changes)
+
+	list, err = uc.ListUserCVs("user-1")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, edited.ID, list[0].ID)
+
+	history, err := uc.GetVersionHistory("user-1", "cv-1")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	require.Equal(t, domain.OriginTailored, history[1].Origin)
+}
+
+func TestSaveVersionRetriesWhenTheNumberIsTaken(t *testing.T) {
+	repo := &racingCVRepository{memoryCVRepository: &memoryCVRepository{cvs: versionedCVs()}}
+	uc := usecases.NewCVUsecase(repo, nil, nil, nil, nil, nil, nil, nil)
+
+	summary := "Backend engineer who ships."
+	edited, err := uc.UpdateSections("user-1", "cv-1", domain.CVSectionsUpdate{ProfileSummary: &summary})
+	require.NoError(t, err)
+	require.Equal(t, 4, edited.Version)
+	require.Equal(t, 3, repo.cvs["cv-competitor"].Version)
+
+	history, err := uc.GetVersionHistory("user-1", "cv-1")
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+}
+
+// tailoringAI rewrites the profile summary and leaves the experience descriptions alone.
+type tailoringAI struct {
+	domain.IAIService
+}
+
+func (tailoringAI) TailorCV(context.Context, *domain.CV, *domain.Job) (*domain.TailoredCVContent, error) {
+	return &domain.TailoredCVContent{ProfileSummary: "Go engineer who builds payment APIs."}, nil
+}
+
+func TestTailoredCopyDropsSuggestionsForRewrittenText(t *testing.T) {
+	cvs := versionedCVs()
+	source := cvs["cv-1"]
+	source.Experiences = []domain.Experience{{ID: "exp-1", Title: "Engineer", Description: "worked on backend services"}}
+	source.Suggestions = []domain.Suggestion{
+		{ID: "sug-summary", Type: domain.SuggestionWeakActionVerbs, Section: domain.SectionProfileSummary,
+			Original: "Backend engineer.", Replacement: "Backend engineer who ships."},
+		{ID: "sug-verb", Type: domain.SuggestionWeakActionVerbs, Section: domain.SectionExperience, TargetID: "exp-1",
+			Original: "worked on", Replacement: "Built"},
+		{ID: "sug-done", Type: domain.SuggestionWeakActionVerbs, Section: domain.SectionProfileSummary,
+			Original: "Engineer.", Replacement: "Engineer who ships.", Applied: true},
+	}
+	source.Score = usecases.CalculateScore(source.Suggestions)
+	cvs["cv-1"] = source
+	repo := &memoryCVRepository{cvs: cvs}
+	jobs := &memoryJobRepository{jobs: []domain.Job{{ID: "job-2", Title: "Go Engineer"}}}
+	uc := usecases.NewCVUsecase(repo, nil, nil, jobs, tailoringAI{}, nil, nil, nil)
+
+	tailored, err := uc.TailorForJob(context.Background(), "user-1", "cv-1", "job-2")
+	require.NoError(t, err)
+	require.Equal(t, "Go engineer who builds payment APIs.", tailored.ProfileSummary)
+	var ids []string
+	for _, sg := range tailored.Suggestions {
+		ids = append(ids, sg.ID)
+	}
+	require.Equal(t, []string{"sug-verb", "sug-done"}, ids)
+	require.Equal(t, usecases.CalculateScore(tailored.Suggestions), tailored.Score)
+	require.NotEqual(t, source.Score, tailored.Score)
+
+	accepted, err := uc.AcceptSuggestion("user-1", tailored.ID, "sug-verb")
+	require.NoError(t, err)
+	require.Equal(t, "Built backend services", accepted.Experiences[0].Description)
+}
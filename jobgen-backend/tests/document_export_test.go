@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	controllers "jobgen-backend/Delivery/Controllers"
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlainTextExportStripsOnlyEmphasis(t *testing.T) {
+	exporter := infrastructure.NewDocumentExporter()
+	cases := map[string]string{
+		"I built **three** services":               "I built three services",
+		"An *exciting* and __bold__ move":          "An exciting and bold move",
+		"Ran `go test` daily":                      "Ran go test daily",
+		"Write to jane_doe@example.com":            "Write to jane_doe@example.com",
+		"See https://example.com/a_b_c/*path*/x_y": "See https://example.com/a_b_c/*path*/x_y",
+		"Set MAX_RETRY_COUNT and user_id":          "Set MAX_RETRY_COUNT and user_id",
+		"Mismatched *delimiters_ stay":             "Mismatched *delimiters_ stay",
+		"2 * 3 * 4 = 24":                           "2 * 3 * 4 = 24",
+		"_one_ and _two_":                          "one and two",
+		"## Skills\n- **Go** and *Rust*\n* Docker": "Skills\n- Go and Rust\n- Docker",
+	}
+	for in, want := range cases {
+		doc, err := exporter.Export("letter", "", in, domain.ExportPlainText)
+		require.NoError(t, err)
+		require.Equal(t, want, string(doc.Data), in)
+	}
+
+	doc, err := exporter.Export("letter", "Dear Acme", "Hello", domain.ExportPlainText)
+	require.NoError(t, err)
+	require.Equal(t, "letter.txt", doc.FileName)
+	require.Equal(t, "Dear Acme\n\nHello", string(doc.Data))
+
+	doc, err = exporter.Export("letter", "Dear Acme", "**Hello**", domain.ExportMarkdown)
+	require.NoError(t, err)
+	require.Equal(t, "letter.md", doc.FileName)
+	require.Equal(t, "# Dear Acme\n\n**Hello**", string(doc.Data))
+
+	_, err = exporter.Export("letter", "", "Hello", "docx")
+	require.Error(t, err)
+}
+
+func TestPDFExportIsWellFormed(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i < 80; i++ {
+		fmt.Fprintf(&body, "Paragraph %d mentions (parentheses), a back\\slash and “quotes”.\n", i)
+	}
+	doc, err := infrastructure.NewDocumentExporter().Export("letter", "Cover letter", body.String(), domain.ExportPDF)
+	require.NoError(t, err)
+	require.Equal(t, "letter.pdf", doc.FileName)
+	require.Equal(t, "application/pdf", doc.ContentType)
+
+	data := doc.Data
+	require.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	require.Contains(t, string(data), `(Paragraph 0 mentions \(parentheses\), a back\\slash and "quotes".) Tj`)
+	require.Contains(t, string(data), "/Count 2 >>") // 80 lines do not fit on one page
+
+	// startxref points at the xref table, whose entries point at each object
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	require.NotNil(t, m)
+	xref, err := strconv.Atoi(string(m[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data[xref:], []byte("xref\n")))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(string(entry[1]))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(data[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
+
+// stubCoverLetterUsecase exports a fixed document and records the version asked for.
+type stubCoverLetterUsecase struct {
+	domain.ICoverLetterUsecase
+	version int
+}
+
+func (s *stubCoverLetterUsecase) Export(_ context.Context, _, _ string, version int, _ domain.ExportFormat) (*domain.ExportedDocument, error) {
+	s.version = version
+	return &domain.ExportedDocument{FileName: "letter.txt", ContentType: "text/plain; charset=utf-8", Data: []byte("Hello")}, nil
+}
+
+func TestCoverLetterExportValidatesVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	uc := &stubCoverLetterUsecase{}
+	r := gin.New()
+	r.GET("/jobs/:id/cover-letter/export", func(c *gin.Context) { c.Set("user_id", "user-1") }, controllers.NewCoverLetterController(uc).Export)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/job-1/cover-letter/export"+query, nil))
+		return w
+	}
+	require.Equal(t, http.StatusBadRequest, get("?version=abc").Code)
+	require.Equal(t, http.StatusBadRequest, get("?version=-1").Code)
+
+	w := get("?version=2")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, 2, uc.version)
+	require.Equal(t, `attachment; filename="letter.txt"`, w.Header().Get("Content-Disposition"))
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain "jobgen-backend/Domain"
+	services "jobgen-backend/Infrastructure/services"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memoryUserRepository serves fixed users; only GetByID is used by the matching service.
+type memoryUserRepository struct {
+	domain.IUserRepository
+	users map[string]*domain.User
+}
+
+func (r *memoryUserRepository) GetByID(_ context.Context, id string) (*domain.User, error) {
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+// memoryFeedbackRepository keeps verdicts and weights in memory.
+type memoryFeedbackRepository struct {
+	feedback map[string]domain.JobFeedback
+	weights  map[string]domain.MatchWeights
+}
+
+func newMemoryFeedbackRepository() *memoryFeedbackRepository {
+	return &memoryFeedbackRepository{feedback: make(map[string]domain.JobFeedback), weights: make(map[string]domain.MatchWeights)}
+}
+
+func (r *memoryFeedbackRepository) Upsert(_ context.Context, feedback *domain.JobFeedback) error {
+	r.feedback[feedback.UserID+"/"+feedback.JobID] = *feedback
+	return nil
+}
+
+func (r *memoryFeedbackRepository) Get(_ context.Context, userID, jobID string) (*domain.JobFeedback, error) {
+	feedback, ok := r.feedback[userID+"/"+jobID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &feedback, nil
+}
+
+func (r *memoryFeedbackRepository) GetDismissedJobIDs(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *memoryFeedbackRepository) GetWeights(_ context.Context, userID string) (*domain.MatchWeights, error) {
+	weights, ok := r.weights[userID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &weights, nil
+}
+
+func (r *memoryFeedbackRepository) SaveWeights(_ context.Context, weights *domain.MatchWeights) error {
+	r.weights[weights.UserID] = *weights
+	return nil
+}
+
+func (r *memoryFeedbackRepository) GetScoreBands(context.Context, []float64) ([]domain.MatchScoreBand, error) {
+	return nil, nil
+}
+
+func TestRecordFeedbackAppliesEachVerdictOnce(t *testing.T) {
+	users := &memoryUserRepository{users: map[string]*domain.User{
+		"user-1": {ID: "user-1", Skills: []string{"Go", "Docker"}, Location: "Remote", ExperienceYears: 4},
+	}}
+	jobs := &memoryJobRepository{jobs: []domain.Job{
+		{ID: "job-1", Title: "Backend Go Engineer", ExtractedSkills: []string{"Go", "Docker"}, Location: "Lagos", PostedAt: time.Now()},
+	}}
+	feedbackRepo := newMemoryFeedbackRepository()
+	svc := services.NewJobMatchingService(jobs, users, feedbackRepo)
+	ctx := context.Background()
+
+	record := func(verdict domain.FeedbackType) domain.MatchWeights {
+		require.NoError(t, svc.RecordFeedback(ctx, "user-1", &domain.JobFeedback{JobID: "job-1", Type: verdict}))
+		return feedbackRepo.weights["user-1"]
+	}
+
+	liked := record(domain.FeedbackThumbsUp)
+	defaults := domain.DefaultMatchWeights()
+	require.Greater(t, liked.Skills, defaults.Skills)
+	require.Equal(t, 1, liked.Samples)
+
+	// Repeating the verdict leaves the weights where they are
+	for i := 0; i < 5; i++ {
+		again := record(domain.FeedbackThumbsUp)
+		require.Equal(t, liked.Skills, again.Skills)
+		require.Equal(t, liked.Experience, again.Experience)
+		require.Equal(t, liked.Location, again.Location)
+		require.Equal(t, 1, again.Samples)
+	}
+
+	// Flipping it undoes the thumbs-up before applying the thumbs-down
+	disliked := record(domain.FeedbackThumbsDown)
+	require.Less(t, disliked.Skills, defaults.Skills)
+	require.InDelta(t, defaults.Skills-disliked.Skills, liked.Skills-defaults.Skills, 0.01)
+	require.Equal(t, 1, disliked.Samples)
+
+	// Both negative verdicts count the same
+	dismissed := record(domain.FeedbackNotInterested)
+	require.Equal(t, disliked.Skills, dismissed.Skills)
+	require.Equal(t, domain.FeedbackNotInterested, feedbackRepo.feedback["user-1/job-1"].Type)
+}
+
+// unreachableWeightsRepository fails to read weights the way a lost database connection would.
+type unreachableWeightsRepository struct {
+	*memoryFeedbackRepository
+}
+
+func (r unreachableWeightsRepository) GetWeights(context.Context, string) (*domain.MatchWeights, error) {
+	return nil, errors.New("connection reset")
+}
+
+func TestRecordFeedbackKeepsWeightsItCannotRead(t *testing.T) {
+	users := &memoryUserRepository{users: map[string]*domain.User{
+		"user-1": {ID: "user-1", Skills: []string{"Go"}, Location: "Remote", ExperienceYears: 4},
+	}}
+	jobs := &memoryJobRepository{jobs: []domain.Job{
+		{ID: "job-1", Title: "Backend Go Engineer", ExtractedSkills: []string{"Go", "Docker"}, Location: "Lagos", PostedAt: time.Now()},
+	}}
+	feedbackRepo := newMemoryFeedbackRepository()
+	learned := domain.MatchWeights{UserID: "user-1", Skills: 0.7, Experience: 0.2, Location: 0.1, Samples: 12}
+	feedbackRepo.weights["user-1"] = learned
+	svc := services.NewJobMatchingService(jobs, users, unreachableWeightsRepository{feedbackRepo})
+	ctx := context.Background()
+
+	err := svc.RecordFeedback(ctx, "user-1", &domain.JobFeedback{JobID: "job-1", Type: domain.FeedbackThumbsUp})
+	require.Error(t, err)
+	require.Equal(t, learned, feedbackRepo.weights["user-1"])
+
+	// Matching still works, with the default weights
+	_, err = svc.GetMatchedJobs(ctx, "user-1", 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, learned, feedbackRepo.weights["user-1"])
+}
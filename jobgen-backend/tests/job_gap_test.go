@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "jobgen-backend/Domain"
+	services "jobgen-backend/Infrastructure/services"
+	usecases "jobgen-backend/Usecases"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int) time.Time {
+	return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func dateRef(year int) *time.Time {
+	d := date(year)
+	return &d
+}
+
+func TestAnalyzeGap(t *testing.T) {
+	tests := []struct {
+		name            string
+		job             domain.Job
+		cv              domain.CV
+		matching        []string
+		missing         []string
+		skillScore      float64
+		cvYears         float64
+		gapYears        float64
+		experienceScore float64
+		fitScore        float64
+	}{
+		{
+			name: "skills from the skill list and from experience",
+			job:  domain.Job{ExtractedSkills: []string{"Go", "Kubernetes", "Docker", "go"}},
+			cv: domain.CV{Skills: []string{" GO "}, Experiences: []domain.Experience{
+				{Title: "Backend Engineer", Description: "Shipped services in Docker containers", StartDate: date(2020), EndDate: dateRef(2023)},
+			}},
+			matching:        []string{"Go", "Docker"},
+			missing:         []string{"Kubernetes"},
+			skillScore:      200.0 / 3,
+			cvYears:         3,
+			experienceScore: 75, // no requirement in the description
+			fitScore:        69, // 66.7*0.75 + 75*0.25
+		},
+		{
+			name:            "job lists no skills",
+			job:             domain.Job{},
+			cv:              domain.CV{Skills: []string{"Go"}},
+			matching:        []string{},
+			missing:         []string{},
+			skillScore:      75,
+			experienceScore: 50, // no dated experience counts as entry level
+			fitScore:        69, // 75*0.75 + 50*0.25
+		},
+		{
+			name: "experience below the requirement",
+			job:  domain.Job{ExtractedSkills: []string{"Go"}, Description: "You have 5+ years of experience with Go."},
+			cv: domain.CV{Skills: []string{"Go"}, Experiences: []domain.Experience{
+				{Title: "Developer", StartDate: date(2021), EndDate: dateRef(2023)},
+			}},
+			matching:        []string{"Go"},
+			missing:         []string{},
+			skillScore:      100,
+			cvYears:         2,
+			gapYears:        3,
+			experienceScore: 60,
+			fitScore:        90, // 100*0.75 + 60*0.25
+		},
+		{
+			name: "overlapping roles count once",
+			job:  domain.Job{ExtractedSkills: []string{"Go", "Rust"}, Description: "At least 3 years of experience."},
+			cv: domain.CV{Skills: []string{"Go"}, Experiences: []domain.Experience{
+				{Title: "Engineer", StartDate: date(2019), EndDate: dateRef(2022)},
+				{Title: "Freelance developer", StartDate: date(2020), EndDate: dateRef(2021)},
+				{Title: "Contractor", StartDate: date(2021), EndDate: dateRef(2022)},
+			}},
+			matching:        []string{"Go"},
+			missing:         []string{"Rust"},
+			skillScore:      50,
+			cvYears:         3,
+			experienceScore: 100,
+			fitScore:        63, // 50*0.75 + 100*0.25
+		},
+	}
+
+	svc := services.NewJobMatchingService(nil, nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := svc.AnalyzeGap(tt.job, tt.cv)
+			require.Equal(t, tt.matching, analysis.MatchingSkills)
+			require.Equal(t, tt.missing, analysis.MissingSkills)
+			require.InDelta(t, tt.skillScore, analysis.SkillScore, 0.01)
+			require.Equal(t, tt.cvYears, analysis.CVExperienceYears)
+			require.Equal(t, tt.gapYears, analysis.ExperienceGapYears)
+			require.Equal(t, tt.experienceScore, analysis.ExperienceScore)
+			require.Equal(t, tt.fitScore, analysis.FitScore)
+		})
+	}
+}
+
+func TestAnalyzeJobGapChecksTheCV(t *testing.T) {
+	cvs := &memoryCVRepository{cvs: map[string]domain.CV{
+		"cv-ready":   {ID: "cv-ready", UserID: "user-1", Status: domain.StatusCompleted, Skills: []string{"Go"}},
+		"cv-pending": {ID: "cv-pending", UserID: "user-1", Status: domain.StatusPending},
+		"cv-other":   {ID: "cv-other", UserID: "user-2", Status: domain.StatusCompleted},
+	}}
+	jobs := &memoryJobRepository{jobs: []domain.Job{{ID: "job-1", Title: "Go Engineer", ExtractedSkills: []string{"Go"}}}}
+	uc := usecases.NewJobUsecase(jobs, nil, cvs, nil, services.NewJobMatchingService(jobs, nil, nil), nil, time.Second)
+	ctx := context.Background()
+
+	analysis, err := uc.AnalyzeJobGap(ctx, "user-1", "job-1", "cv-ready", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Go"}, analysis.MatchingSkills)
+
+	_, err = uc.AnalyzeJobGap(ctx, "user-1", "job-1", "cv-pending", false)
+	require.ErrorIs(t, err, domain.ErrCVNotReady)
+	_, err = uc.AnalyzeJobGap(ctx, "user-1", "job-1", "cv-other", false)
+	require.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = uc.AnalyzeJobGap(ctx, "user-1", "job-1", "cv-missing", false)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	// Asking for suggestions without an AI service still returns the analysis
+	analysis, err = uc.AnalyzeJobGap(ctx, "user-1", "job-1", "cv-ready", true)
+	require.NoError(t, err)
+	require.Equal(t, "ai_unavailable", analysis.SuggestionsError)
+}
@@ -0,0 +1,463 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptedProviderBacksAIServices(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "extracts cv improvement suggestions", Reply: `{"suggestions":[{"type":"quantification","section":"experience","original":"Go developer","replacement":"Go developer who cut API latency by 40%","content":"Add numbers","applied":true}]}`},
+		infrastructure.ScriptedRule{Match: "", Reply: "Hello from the script"},
+	)
+
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+
+	suggestions, err := ai.SuggestCVImprovements(context.Background(), "Jane Doe\nGo developer")
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	require.False(t, suggestions[0].Applied)
+	require.Equal(t, domain.SectionExperience, suggestions[0].Section)
+
+	history := []domain.ChatMessage{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}}
+	answer, err := ai.RunChatAgent(context.Background(), "how are you?", history, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "Hello from the script", answer.Reply)
+
+	calls := provider.Calls()
+	require.Len(t, calls, 2)
+	require.Len(t, calls[1].Messages, 3)
+	require.Equal(t, "assistant", calls[1].Messages[1].Role)
+}
+
+func TestImproveCVRepairsInvalidSuggestions(t *testing.T) {
+	cv := &domain.CV{
+		ProfileSummary: "Backend engineer",
+		Experiences:    []domain.Experience{{ID: "exp-1", Title: "Engineer", Company: "Acme", Description: "Worked on backend services"}},
+	}
+	invalid := `{"reply":"Try these","suggestions":[
+		{"type":"grammar","section":"experience","targetId":"exp-1","original":"Worked on backend services","replacement":"Built backend services","content":"Fix grammar"},
+		{"type":"weak_action_verbs","section":"experience","targetId":"exp-9","original":"Worked on backend services","replacement":"Built backend services","content":"Stronger verb"},]}`
+	repaired := "```json\n" + `{"reply":"Try these","suggestions":[
+		{"type":"Weak Action Verbs","section":"experience","targetId":"exp-1","original":"worked on  backend services","replacement":"Built backend services serving 2M users","rationale":"Strong verbs read as ownership"}]}` + "\n```"
+
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "previous response was rejected", Reply: repaired},
+		infrastructure.ScriptedRule{Match: "improve their cvs", Reply: invalid},
+	)
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+
+	reply, suggestions, err := ai.ImproveCV(context.Background(), cv, "make it stronger", nil)
+	require.NoError(t, err)
+	require.Equal(t, "Try these", reply)
+	require.Len(t, suggestions, 1)
+	require.Equal(t, domain.SuggestionWeakActionVerbs, suggestions[0].Type)
+	require.Equal(t, "exp-1", suggestions[0].TargetID)
+	require.Equal(t, "Strong verbs read as ownership", suggestions[0].Content)
+	require.NotEmpty(t, suggestions[0].ID)
+
+	calls := provider.Calls()
+	require.Len(t, calls, 2)
+	require.True(t, calls[1].JSON)
+	require.Len(t, calls[1].Messages, 3)
+	require.Contains(t, calls[1].Messages[2].Content, `type "grammar"`)
+	require.Contains(t, calls[1].Messages[2].Content, `targetId "exp-9"`)
+
+	// A second invalid answer fails as bad output, which is not worth retrying
+	provider = infrastructure.NewScriptedProvider(infrastructure.ScriptedRule{Match: "", Reply: "Here are my thoughts: - use numbers"})
+	ai = infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+	_, _, err = ai.AnalyzeCV(context.Background(), "Jane Doe\nGo developer")
+	var llmErr *infrastructure.LLMError
+	require.ErrorAs(t, err, &llmErr)
+	require.Equal(t, infrastructure.LLMBadOutput, llmErr.Kind)
+	require.False(t, infrastructure.IsTransientLLMError(err))
+	require.Len(t, provider.Calls(), 2)
+}
+
+func TestSuggestionsAcceptAnEmptyArray(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "previous response was rejected", Reply: `{"reply":"Still nothing","suggestions":[]}`},
+		infrastructure.ScriptedRule{Match: "extracts cv improvement suggestions", Reply: `{"reply":"Your CV already reads well","suggestions":[]}`},
+	)
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+
+	suggestions, err := ai.SuggestCVImprovements(context.Background(), "Jane Doe\nGo developer")
+	require.NoError(t, err)
+	require.Empty(t, suggestions)
+	require.Len(t, provider.Calls(), 1) // nothing to repair
+
+	// Leaving the array out entirely is still sent back for a repair
+	provider = infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "previous response was rejected", Reply: `{"suggestions":[]}`},
+		infrastructure.ScriptedRule{Match: "extracts cv improvement suggestions", Reply: `{"reply":"Looks good"}`},
+	)
+	ai = infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+	suggestions, err = ai.SuggestCVImprovements(context.Background(), "Jane Doe\nGo developer")
+	require.NoError(t, err)
+	require.Empty(t, suggestions)
+	calls := provider.Calls()
+	require.Len(t, calls, 2)
+	require.Contains(t, calls[1].Messages[2].Content, `no "suggestions" array`)
+}
+
+func TestSuggestForJobReturnsValidatedSuggestions(t *testing.T) {
+	cv := &domain.CV{
+		Experiences: []domain.Experience{{ID: "exp-1", Title: "Engineer", Company: "Acme", Description: "Worked on backend services"}},
+		Skills:      []string{"Go"},
+	}
+	job := &domain.Job{Title: "Platform Engineer", CompanyName: "Globex", Description: "Kubernetes and Go"}
+	reply := `{"reply":"Tailored","suggestions":[
+		{"type":"missing_keywords","section":"experience","targetId":"exp-1","original":"Worked on backend services","replacement":"Built Go backend services on Kubernetes","rationale":"Names the stack the job asks for"},
+		{"type":"job_tailoring","section":"skills","original":"","replacement":"Kubernetes","content":"Add Kubernetes"}]}`
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "previous response was rejected", Reply: reply},
+		infrastructure.ScriptedRule{Match: "stronger fit for this job", Reply: reply},
+	)
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{}))
+
+	suggestions, err := ai.SuggestForJob(context.Background(), cv, job, []string{"Kubernetes"})
+	require.NoError(t, err)
+	// Only the suggestion with a known type survives, with the fields accept/reject rely on
+	require.Len(t, suggestions, 1)
+	require.Equal(t, domain.SuggestionMissingKeywords, suggestions[0].Type)
+	require.Equal(t, domain.SectionExperience, suggestions[0].Section)
+	require.Equal(t, "exp-1", suggestions[0].TargetID)
+	require.Equal(t, "Built Go backend services on Kubernetes", suggestions[0].Replacement)
+	require.NotEmpty(t, suggestions[0].ID)
+
+	calls := provider.Calls()
+	require.Len(t, calls, 2)
+	require.True(t, calls[0].JSON)
+	require.Contains(t, calls[0].Messages[0].Content, "- exp-1: Engineer at Acme")
+	require.Contains(t, calls[1].Messages[2].Content, `type "job_tailoring"`)
+}
+
+func TestLLMClientRetriesTransientFailuresAndCountsUsage(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "overloaded", Error: "gemini http 503: model overloaded"},
+		infrastructure.ScriptedRule{Match: "bad key", Error: "openai http 401: invalid api key"},
+		infrastructure.ScriptedRule{Match: "", Reply: "two words"},
+	)
+	client := infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{MaxRetries: 2, RetryBackoff: time.Millisecond})
+
+	_, err := client.Generate(context.Background(), "chat", infrastructure.LLMRequest{Messages: []infrastructure.LLMMessage{{Role: "user", Content: "overloaded"}}})
+	var llmErr *infrastructure.LLMError
+	require.ErrorAs(t, err, &llmErr)
+	require.Equal(t, infrastructure.LLMUnavailable, llmErr.Kind)
+	require.ErrorIs(t, err, domain.ErrServiceUnavailable)
+	require.Len(t, provider.Calls(), 3)
+
+	_, err = client.Generate(context.Background(), "chat", infrastructure.LLMRequest{Messages: []infrastructure.LLMMessage{{Role: "user", Content: "bad key"}}})
+	require.ErrorAs(t, err, &llmErr)
+	require.Equal(t, infrastructure.LLMRejected, llmErr.Kind)
+	require.False(t, infrastructure.IsTransientLLMError(err))
+	require.Len(t, provider.Calls(), 4)
+
+	resp, err := client.Generate(context.Background(), "cover_letter", infrastructure.LLMRequest{Messages: []infrastructure.LLMMessage{{Role: "user", Content: "hello"}}})
+	require.NoError(t, err)
+	require.Equal(t, 2, resp.Usage.CompletionTokens)
+
+	usage := client.Usage()
+	require.Equal(t, "scripted", usage.Provider)
+	require.Equal(t, domain.AIUsage{Requests: 2, Failures: 2, Retries: 2}, usage.Operations["chat"])
+	require.Equal(t, int64(3), usage.Total.Requests)
+	require.Equal(t, int64(2), usage.Total.CompletionTokens)
+}
+
+func TestLLMClientCachesResponsesPerOperation(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(infrastructure.ScriptedRule{Match: "", Reply: "cached answer"})
+	client := infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{
+		Cache:     infrastructure.NewInMemoryLLMCache(10),
+		CacheTTLs: map[string]time.Duration{"job_search": time.Hour},
+	})
+	ask := func(operation, prompt string) string {
+		text, err := client.GenerateText(context.Background(), operation, infrastructure.LLMRequest{Messages: []infrastructure.LLMMessage{{Role: "user", Content: prompt}}})
+		require.NoError(t, err)
+		return text
+	}
+
+	require.Equal(t, "cached answer", ask("job_search", "remote go jobs"))
+	require.Equal(t, "cached answer", ask("job_search", "remote go jobs"))
+	require.Len(t, provider.Calls(), 1)
+	ask("job_search", "remote rust jobs")
+	require.Len(t, provider.Calls(), 2)
+	// Operations without a TTL always reach the model
+	ask("chat", "remote go jobs")
+	ask("chat", "remote go jobs")
+	require.Len(t, provider.Calls(), 4)
+
+	usage := client.Usage()
+	require.Equal(t, domain.AIUsage{Requests: 2, CacheHits: 1, PromptTokens: 6, CompletionTokens: 4}, usage.Operations["job_search"])
+	require.Equal(t, int64(0), usage.Operations["chat"].CacheHits)
+}
+
+// gatedProvider blocks every call until release is closed.
+type gatedProvider struct {
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (p *gatedProvider) Name() string { return "gated" }
+
+func (p *gatedProvider) Generate(ctx context.Context, req infrastructure.LLMRequest) (*infrastructure.LLMResponse, error) {
+	p.calls.Add(1)
+	<-p.release
+	return &infrastructure.LLMResponse{Text: "shared"}, nil
+}
+
+func TestLLMClientDeduplicatesConcurrentIdenticalRequests(t *testing.T) {
+	provider := &gatedProvider{release: make(chan struct{})}
+	client := infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{
+		Cache:     infrastructure.NewInMemoryLLMCache(10),
+		CacheTTLs: map[string]time.Duration{"cv_analysis": time.Hour},
+	})
+	req := infrastructure.LLMRequest{Messages: []infrastructure.LLMMessage{{Role: "user", Content: "same cv"}}}
+
+	var wg sync.WaitGroup
+	answers := make([]string, 3)
+	for i := range answers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			answers[i], _ = client.GenerateText(context.Background(), "cv_analysis", req)
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond) // let every request reach the client
+	close(provider.release)
+	wg.Wait()
+
+	require.Equal(t, []string{"shared", "shared", "shared"}, answers)
+	require.Equal(t, int32(1), provider.calls.Load())
+	require.Equal(t, int64(2), client.Usage().Operations["cv_analysis"].Deduplicated)
+}
+
+func TestLLMClientCachesOnlyAcceptedResponses(t *testing.T) {
+	provider := infrastructure.NewScriptedProvider(infrastructure.ScriptedRule{Match: "", Reply: "not json"})
+	cache := infrastructure.NewInMemoryLLMCache(10)
+	client := infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{
+		Cache:     cache,
+		CacheTTLs: map[string]time.Duration{"cv_extraction": time.Hour},
+	})
+	req := infrastructure.LLMRequest{Messages: []infrastructure.LLMMessage{{Role: "user", Content: "extract"}}}
+	rejectAll := func(string) error { return errors.New("unusable") }
+
+	// A rejected response still reaches the caller but is asked for again next time
+	for i := 1; i <= 2; i++ {
+		text, err := client.GenerateCheckedText(context.Background(), "cv_extraction", req, rejectAll)
+		require.NoError(t, err)
+		require.Equal(t, "not json", text)
+		require.Len(t, provider.Calls(), i)
+	}
+
+	_, err := client.GenerateCheckedText(context.Background(), "cv_extraction", req, nil)
+	require.NoError(t, err)
+	_, err = client.GenerateCheckedText(context.Background(), "cv_extraction", req, nil)
+	require.NoError(t, err)
+	require.Len(t, provider.Calls(), 3)
+
+	// A cached response the caller now rejects counts as a miss
+	_, err = client.GenerateCheckedText(context.Background(), "cv_extraction", req, rejectAll)
+	require.NoError(t, err)
+	require.Len(t, provider.Calls(), 4)
+}
+
+func TestSuggestionsNeedingRepairAreNotCached(t *testing.T) {
+	invalid := `{"reply":"Try these","suggestions":[{"type":"grammar","section":"skills","original":"","replacement":"Go","content":"Add Go"}]}`
+	valid := `{"reply":"Try these","suggestions":[{"type":"missing_keywords","section":"skills","original":"","replacement":"Go","content":"Add Go"}]}`
+	provider := infrastructure.NewScriptedProvider(
+		infrastructure.ScriptedRule{Match: "previous response was rejected", Reply: valid},
+		infrastructure.ScriptedRule{Match: "", Reply: invalid},
+	)
+	ai := infrastructure.NewAIService(infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{
+		Cache:     infrastructure.NewInMemoryLLMCache(10),
+		CacheTTLs: infrastructure.DefaultLLMCacheTTLs,
+	}))
+
+	for round := 0; round < 2; round++ {
+		suggestions, err := ai.SuggestCVImprovements(context.Background(), "Jane Doe\nSkills: Docker")
+		require.NoError(t, err)
+		require.Len(t, suggestions, 1)
+		require.Equal(t, domain.SuggestionMissingKeywords, suggestions[0].Type)
+	}
+	// The first answer needed a repair, so it is asked for again; the repaired one is cached
+	require.Len(t, provider.Calls(), 3)
+}
+
+// cancellableProvider blocks every call until release is closed or the call's context ends.
+type cancellableProvider struct {
+	release   chan struct{}
+	calls     atomic.Int32
+	cancelled atomic.Bool
+}
+
+func (p *cancellableProvider) Name() string { return "cancellable" }
+
+func (p *cancellableProvider) Generate(ctx context.Context, req infrastructure.LLMRequest) (*infrastructure.LLMResponse, error) {
+	p.calls.Add(1)
+	select {
+	case <-p.release:
+		return &infrastructure.LLMResponse{Text: "shared"}, nil
+	case <-ctx.Done():
+		p.cancelled.Store(true)
+		return nil, ctx.Err()
+	}
+}
+
+func TestLLMClientSharedCallSurvivesTheFirstCallerGivingUp(t *testing.T) {
+	provider := &cancellableProvider{release: make(chan struct{})}
+	client := infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{
+		Cache:     infrastructure.NewInMemoryLLMCache(10),
+		CacheTTLs: map[string]time.Duration{"cv_analysis": time.Hour},
+	})
+	req := infrastructure.LLMRequest{Messages: []infrastructure.LLMMessage{{Role: "user", Content: "same cv"}}}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := client.GenerateText(leaderCtx, "cv_analysis", req)
+		leaderErr <- err
+	}()
+	require.Eventually(t, func() bool { return provider.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	waiter := make(chan string, 1)
+	go func() {
+		text, _ := client.GenerateText(context.Background(), "cv_analysis", req)
+		waiter <- text
+	}()
+	time.Sleep(20 * time.Millisecond) // let the second request join the call
+
+	// The first caller leaves; the model call it started keeps going for the other one
+	cancelLeader()
+	var llmErr *infrastructure.LLMError
+	require.ErrorAs(t, <-leaderErr, &llmErr)
+	require.Equal(t, infrastructure.LLMTimeout, llmErr.Kind)
+
+	close(provider.release)
+	require.Equal(t, "shared", <-waiter)
+	require.False(t, provider.cancelled.Load())
+	require.Equal(t, int32(1), provider.calls.Load())
+
+	// The waiter cached the answer
+	text, err := client.GenerateText(context.Background(), "cv_analysis", req)
+	require.NoError(t, err)
+	require.Equal(t, "shared", text)
+	require.Equal(t, int32(1), provider.calls.Load())
+}
+
+func TestInMemoryLLMCacheEvictsAndExpires(t *testing.T) {
+	ctx := context.Background()
+	cache := infrastructure.NewInMemoryLLMCache(2)
+	require.NoError(t, cache.Set(ctx, "a", &infrastructure.LLMResponse{Text: "a"}, time.Hour))
+	require.NoError(t, cache.Set(ctx, "b", &infrastructure.LLMResponse{Text: "b"}, time.Hour))
+	_, ok, _ := cache.Get(ctx, "a") // a is now the most recently used
+	require.True(t, ok)
+	require.NoError(t, cache.Set(ctx, "c", &infrastructure.LLMResponse{Text: "c"}, time.Hour))
+
+	_, ok, _ = cache.Get(ctx, "b")
+	require.False(t, ok)
+	resp, ok, _ := cache.Get(ctx, "a")
+	require.True(t, ok)
+	require.Equal(t, "a", resp.Text)
+
+	require.NoError(t, cache.Set(ctx, "short", &infrastructure.LLMResponse{Text: "x"}, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	_, ok, _ = cache.Get(ctx, "short")
+	require.False(t, ok)
+
+	ttls, err := infrastructure.ParseLLMCacheTTLs("job_suggestions=30m, cv_analysis=0")
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Minute, ttls["job_suggestions"])
+	require.Zero(t, ttls["cv_analysis"])
+	require.Equal(t, 24*time.Hour, ttls["chat_title"])
+	_, err = infrastructure.ParseLLMCacheTTLs("job_search")
+	require.Error(t, err)
+}
+
+func TestOpenAICompatibleProvider(t *testing.T) {
+	var body map[string]interface{}
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/chat/completions", r.URL.Path)
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"ok\":true}"}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`))
+	}))
+	defer server.Close()
+
+	provider := infrastructure.NewOpenAICompatibleProvider(server.URL+"/v1/", "sk-test", "llama3", time.Second)
+	resp, err := provider.Generate(context.Background(), infrastructure.LLMRequest{
+		System:   "Answer in JSON",
+		Messages: []infrastructure.LLMMessage{{Role: "user", Content: "ping"}},
+		JSON:     true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, `{"ok":true}`, resp.Text)
+	require.Equal(t, infrastructure.LLMUsage{PromptTokens: 12, CompletionTokens: 3}, resp.Usage)
+
+	require.Equal(t, "Bearer sk-test", auth)
+	require.Equal(t, "llama3", body["model"])
+	messages := body["messages"].([]interface{})
+	require.Len(t, messages, 2)
+	require.Equal(t, "system", messages[0].(map[string]interface{})["role"])
+	require.Equal(t, "json_object", body["response_format"].(map[string]interface{})["type"])
+}
+
+func TestOpenAICompatibleProviderStreams(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\"Hello\"}}]}\n\n" +
+			": keep-alive\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\" there\"}}]}\n\n" +
+			"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":2}}\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	provider := infrastructure.NewOpenAICompatibleProvider(server.URL, "", "llama3", time.Second).(infrastructure.LLMStreamingProvider)
+	var deltas []string
+	resp, err := provider.GenerateStream(context.Background(), infrastructure.LLMRequest{Messages: []infrastructure.LLMMessage{{Role: "user", Content: "hi"}}},
+		func(delta string) error {
+			deltas = append(deltas, delta)
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Hello", " there"}, deltas)
+	require.Equal(t, "Hello there", resp.Text)
+	require.Equal(t, infrastructure.LLMUsage{PromptTokens: 5, CompletionTokens: 2}, resp.Usage)
+	require.Equal(t, true, body["stream"])
+}
+
+func TestLLMProviderDegradesWithoutConfiguration(t *testing.T) {
+	saved := infrastructure.Env
+	defer func() { infrastructure.Env = saved }()
+	infrastructure.Env = infrastructure.EnvConfig{}
+
+	provider, err := infrastructure.NewLLMProvider()
+	require.NoError(t, err)
+	require.False(t, infrastructure.LLMAvailable(provider))
+
+	client := infrastructure.NewLLMClient(provider, infrastructure.LLMClientOptions{})
+	_, err = infrastructure.NewAIService(client).GenerateCoverLetter(context.Background(), &domain.CV{}, &domain.Job{}, domain.CoverLetterOptions{})
+	require.ErrorIs(t, err, domain.ErrServiceUnavailable)
+	require.False(t, infrastructure.IsTransientLLMError(err))
+
+	infrastructure.Env.LLMProvider = "bogus"
+	_, err = infrastructure.NewLLMProvider()
+	require.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"os"
+	"testing"
+
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The fixtures in testdata/pdf are hand-built single-page PDFs that place every line with
+// an absolute text matrix in Courier, which has fixed glyph widths. two-column.pdf has a
+// full-width header above a skills sidebar and a main column whose lines are written in
+// interleaved order; single-column.pdf has a small date table and wrapped paragraphs.
+
+func extractPDFFixture(t *testing.T, name string) *domain.CVDocument {
+	t.Helper()
+	file, err := os.Open("../testdata/pdf/" + name)
+	require.NoError(t, err)
+	defer file.Close()
+	doc, err := infrastructure.NewCVParserService().ExtractDocument(file)
+	require.NoError(t, err)
+	require.True(t, doc.HasLayout)
+	require.Equal(t, "pdf", doc.Format)
+	return doc
+}
+
+func TestPDFLayoutReadsColumnsInOrder(t *testing.T) {
+	doc := extractPDFFixture(t, "two-column.pdf")
+
+	type block struct {
+		kind   domain.CVBlockKind
+		text   string
+		column int
+	}
+	var got []block
+	for _, b := range doc.Blocks {
+		got = append(got, block{b.Kind, b.Text, b.Column})
+	}
+	require.Equal(t, []block{
+		// the header crosses the gutter, so it is read before either column
+		{domain.BlockHeading, "Jane Doe", 0},
+		{domain.BlockParagraph, "Senior Backend Engineer, Lagos, jane@example.com", 0},
+		// the whole sidebar comes before the main column rather than line by line
+		{domain.BlockHeading, "SKILLS", 0},
+		{domain.BlockParagraph, "Go\nPostgreSQL\nDocker\nKubernetes", 0},
+		{domain.BlockHeading, "LANGUAGES", 0},
+		{domain.BlockParagraph, "English\nAmharic", 0},
+		{domain.BlockHeading, "EXPERIENCE", 1},
+		{domain.BlockParagraph, "Backend Engineer at Acme", 1},
+		{domain.BlockBullet, "Built payment APIs in Go", 1},
+		{domain.BlockBullet, "Cut latency by 40 percent for card checkouts", 1}, // indented continuation
+		{domain.BlockHeading, "EDUCATION", 1},
+		{domain.BlockParagraph, "BSc Computer Science, AAU", 1},
+	}, got)
+}
+
+func TestPDFLayoutKeepsSingleColumnLines(t *testing.T) {
+	doc := extractPDFFixture(t, "single-column.pdf")
+
+	for _, b := range doc.Blocks {
+		require.Equal(t, 0, b.Column, b.Text)
+	}
+	// The dates are too sparse to be a column, so each stays on the line it labels
+	require.Equal(t, "John Smith\n\n"+
+		"Data engineer who builds reliable pipelines.\n\n"+
+		"EXPERIENCE\n\n"+
+		"2020 - 2023 Data Engineer at Globex\n2017 - 2020 Analyst at Initech\n\n"+
+		"SKILLS\n\n"+
+		"Python, SQL, Airflow and Spark for batch and streaming jobs\nacross cloud warehouses.", doc.PlainText())
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	controllers "jobgen-backend/Delivery/Controllers"
+	router "jobgen-backend/Delivery/Router"
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProcessRole(t *testing.T) {
+	cases := map[string]infrastructure.ProcessRole{
+		"api":    {Name: "api", API: true},
+		"worker": {Name: "worker", Worker: true},
+		"all":    {Name: "all", API: true, Worker: true},
+		"":       {Name: "all", API: true, Worker: true},
+	}
+	for in, want := range cases {
+		role, err := infrastructure.ParseProcessRole(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, role, in)
+		require.Equal(t, in == "api" || in == "worker", role.Split(), in)
+	}
+
+	_, err := infrastructure.ParseProcessRole("scheduler")
+	require.ErrorContains(t, err, `unknown process role "scheduler"`)
+}
+
+func TestSplitRolesNeedSharedQueueAndStorage(t *testing.T) {
+	all, _ := infrastructure.ParseProcessRole("all")
+	require.NoError(t, all.CheckSharedServices(false, false), "one process shares memory and disk")
+
+	for _, name := range []string{"api", "worker"} {
+		role, _ := infrastructure.ParseProcessRole(name)
+		require.ErrorContains(t, role.CheckSharedServices(false, true), "Redis", name)
+		require.ErrorContains(t, role.CheckSharedServices(true, false), "MinIO", name)
+		require.NoError(t, role.CheckSharedServices(true, true), name)
+	}
+}
+
+// roleJWTService accepts the tokens "admin" and "user" as access tokens of that role.
+type roleJWTService struct{ domain.IJWTService }
+
+func (roleJWTService) ValidateAccessToken(token string) (*domain.AccessTokenPayload, error) {
+	switch token {
+	case "admin":
+		return &domain.AccessTokenPayload{UserID: "admin-1", Role: domain.RoleAdmin}, nil
+	case "user":
+		return &domain.AccessTokenPayload{UserID: "user-1", Role: domain.RoleUser}, nil
+	}
+	return nil, domain.ErrInvalidToken
+}
+
+// fixedWorkerStats reports a running pool.
+type fixedWorkerStats struct{}
+
+func (fixedWorkerStats) Stats() domain.CVWorkerStats {
+	return domain.CVWorkerStats{Running: true, Concurrency: 4}
+}
+
+func TestWorkerMetricsAreAdminOnlyNextToTheAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	workerController := controllers.NewWorkerController(fixedWorkerStats{})
+	get := func(r *gin.Engine, path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Role "all": the worker routes share the public API router
+	api := gin.New()
+	auth := infrastructure.NewAuthMiddleware(roleJWTService{})
+	router.RegisterWorkerRoutes(api, workerController, auth.RequireAdmin())
+	require.Equal(t, http.StatusOK, get(api, "/api/v1/worker/health", ""))
+	require.Equal(t, http.StatusUnauthorized, get(api, "/api/v1/worker/metrics", ""))
+	require.Equal(t, http.StatusUnauthorized, get(api, "/api/v1/worker/metrics", "forged"))
+	require.Equal(t, http.StatusForbidden, get(api, "/api/v1/worker/metrics", "user"))
+	require.Equal(t, http.StatusOK, get(api, "/api/v1/worker/metrics", "admin"))
+
+	// Role "worker": the internal worker port serves no API and needs no token
+	internal := router.SetupWorkerRouter(workerController)
+	require.Equal(t, http.StatusOK, get(internal, "/health", ""))
+	require.Equal(t, http.StatusOK, get(internal, "/api/v1/worker/metrics", ""))
+}
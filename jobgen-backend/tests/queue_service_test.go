@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "jobgen-backend/Domain"
+	infrastructure "jobgen-backend/Infrastructure"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryQueueRedeliversAndDeadLetters(t *testing.T) {
+	q := infrastructure.NewInMemoryQueueService(10, infrastructure.QueueOptions{
+		VisibilityTimeout: 20 * time.Millisecond,
+		MaxAttempts:       2,
+		BaseBackoff:       time.Millisecond,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	require.NoError(t, q.Enqueue("cv-1"))
+
+	// The first lease expires without an ack, so the job comes back
+	first, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, first.Attempt)
+
+	second, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "cv-1", second.JobID)
+	require.True(t, second.LastAttempt())
+
+	require.NoError(t, q.DeadLetter(second, "storage unavailable"))
+	dead, err := q.ListDeadLetters()
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, "storage unavailable", dead[0].Reason)
+
+	// A requeued job starts over with a fresh attempt count
+	require.NoError(t, q.RequeueDeadLetter("cv-1"))
+	again, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, again.Attempt)
+	require.NoError(t, q.Ack(again))
+
+	dead, err = q.ListDeadLetters()
+	require.NoError(t, err)
+	require.Empty(t, dead)
+}
+
+func TestQueueLeaseOutlastsTheJobTimeout(t *testing.T) {
+	def := infrastructure.DefaultQueueOptions()
+	require.Equal(t, def, infrastructure.QueueOptionsForJobTimeout(3*time.Minute))
+
+	opts := infrastructure.QueueOptionsForJobTimeout(10 * time.Minute)
+	require.Greater(t, opts.VisibilityTimeout, 10*time.Minute)
+	require.Equal(t, def.MaxAttempts, opts.MaxAttempts)
+}
+
+func newMiniredisQueue(t *testing.T, opts infrastructure.QueueOptions) (infrastructure.QueueService, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return infrastructure.NewQueueService(client, "cv_test", opts), mr
+}
+
+func TestRedisQueueRetriesAndDeadLetters(t *testing.T) {
+	q, mr := newMiniredisQueue(t, infrastructure.QueueOptions{
+		MaxAttempts:  2,
+		BaseBackoff:  time.Millisecond,
+		MaxBackoff:   time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	require.NoError(t, q.Enqueue("cv-1"))
+	first, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "cv-1", first.JobID)
+	require.Equal(t, 1, first.Attempt)
+	require.NoError(t, q.Retry(first))
+
+	second, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, second.Attempt)
+	require.True(t, second.LastAttempt())
+	require.NoError(t, q.DeadLetter(second, "storage unavailable"))
+
+	dead, err := q.ListDeadLetters()
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, "cv-1", dead[0].JobID)
+	require.Equal(t, 2, dead[0].Attempts)
+	require.Equal(t, "storage unavailable", dead[0].Reason)
+	require.False(t, mr.Exists("cv_test:leased"))
+	require.False(t, mr.Exists("cv_test:attempts"))
+
+	// A requeued job starts over with a fresh attempt count
+	require.NoError(t, q.RequeueDeadLetter("cv-1"))
+	require.ErrorIs(t, q.RequeueDeadLetter("cv-1"), domain.ErrNotFound)
+	again, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, again.Attempt)
+	require.NoError(t, q.Ack(again))
+	require.False(t, mr.Exists("cv_test:leased"))
+	require.False(t, mr.Exists("cv_test:leases"))
+	require.False(t, mr.Exists("cv_test:attempts"))
+}
+
+func TestRedisQueueIgnoresSettlesFromAnExpiredLease(t *testing.T) {
+	q, mr := newMiniredisQueue(t, infrastructure.QueueOptions{
+		VisibilityTimeout: 30 * time.Millisecond,
+		MaxAttempts:       3,
+		PollInterval:      5 * time.Millisecond,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	require.NoError(t, q.Enqueue("cv-1"))
+	stale, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+
+	// The first worker stalls past its lease, so the job goes to a second one
+	time.Sleep(50 * time.Millisecond)
+	current, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "cv-1", current.JobID)
+	require.Equal(t, 2, current.Attempt)
+
+	require.ErrorIs(t, q.Ack(stale), infrastructure.ErrLeaseLost)
+	require.ErrorIs(t, q.Retry(stale), infrastructure.ErrLeaseLost)
+	require.ErrorIs(t, q.DeadLetter(stale, "late failure"), infrastructure.ErrLeaseLost)
+
+	// The second worker's lease is untouched and the job was not duplicated
+	leased, err := mr.ZMembers("cv_test:leased")
+	require.NoError(t, err)
+	require.Equal(t, []string{"cv-1"}, leased)
+	require.False(t, mr.Exists("cv_test:delayed"))
+	require.False(t, mr.Exists("cv_test"))
+	dead, err := q.ListDeadLetters()
+	require.NoError(t, err)
+	require.Empty(t, dead)
+
+	require.NoError(t, q.Ack(current))
+	require.False(t, mr.Exists("cv_test:leased"))
+	require.False(t, mr.Exists("cv_test:attempts"))
+}
+
+func TestInMemoryQueueIgnoresSettlesFromAnExpiredLease(t *testing.T) {
+	q := infrastructure.NewInMemoryQueueService(10, infrastructure.QueueOptions{
+		VisibilityTimeout: 20 * time.Millisecond,
+		MaxAttempts:       3,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	require.NoError(t, q.Enqueue("cv-1"))
+	stale, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	current, err := q.Dequeue(ctx) // redelivered once the first lease expires
+	require.NoError(t, err)
+	require.Equal(t, 2, current.Attempt)
+
+	require.ErrorIs(t, q.Ack(stale), infrastructure.ErrLeaseLost)
+	require.ErrorIs(t, q.Retry(stale), infrastructure.ErrLeaseLost)
+	require.ErrorIs(t, q.DeadLetter(stale, "late failure"), infrastructure.ErrLeaseLost)
+	require.NoError(t, q.Ack(current))
+	require.ErrorIs(t, q.Ack(current), infrastructure.ErrLeaseLost)
+}
+
+func TestRedisQueueReturnsExhaustedDeliveries(t *testing.T) {
+	q, _ := newMiniredisQueue(t, infrastructure.QueueOptions{
+		VisibilityTimeout: 30 * time.Millisecond,
+		MaxAttempts:       1,
+		PollInterval:      5 * time.Millisecond,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	require.NoError(t, q.Enqueue("cv-1"))
+	first, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	require.False(t, first.Exhausted())
+
+	// The only attempt's lease expires, so the job comes back exhausted for the caller to fail
+	time.Sleep(50 * time.Millisecond)
+	exhausted, err := q.Dequeue(ctx)
+	require.NoError(t, err)
+	require.True(t, exhausted.Exhausted())
+	require.NoError(t, q.DeadLetter(exhausted, "lease expired on the final attempt"))
+
+	dead, err := q.ListDeadLetters()
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, 2, dead[0].Attempts)
+}